@@ -51,16 +51,21 @@ namespace app_otl.Controllers
         }
 
         [HttpGet]
-        public async Task<IActionResult> Get()
+        public async Task<IActionResult> Get([FromQuery] string? email)
         {
             try
             {
                 var connectionString = "Data Source=/app/data/customer.db";
                 using var connection = new SqliteConnection(connectionString);
                 
-                var customers = await connection.QueryAsync<dynamic>(
-                    "SELECT Id, Name, Email, CreatedAt, UpdatedAt FROM Customers ORDER BY CreatedAt DESC"
-                );
+                var customers = string.IsNullOrWhiteSpace(email)
+                    ? await connection.QueryAsync<dynamic>(
+                        "SELECT Id, Name, Email, CreatedAt, UpdatedAt FROM Customers ORDER BY CreatedAt DESC"
+                    )
+                    : await connection.QueryAsync<dynamic>(
+                        "SELECT Id, Name, Email, CreatedAt, UpdatedAt FROM Customers WHERE Email = @Email COLLATE NOCASE ORDER BY CreatedAt DESC",
+                        new { Email = email }
+                    );
                 
                 return SuccessResponse(customers);
             }
@@ -99,5 +104,35 @@ namespace app_otl.Controllers
                 return InternalServerErrorResponse("An error occurred while retrieving the customer", exception: ex);
             }
         }
+
+        [HttpDelete("{id}")]
+        public async Task<IActionResult> Delete(string id)
+        {
+            try
+            {
+                var connectionString = "Data Source=/app/data/customer.db";
+                using var connection = new SqliteConnection(connectionString);
+
+                var deleted = await connection.ExecuteAsync(
+                    "DELETE FROM Customers WHERE Id = @Id",
+                    new { Id = id }
+                );
+
+                if (deleted == 0)
+                {
+                    return NotFoundResponse($"Customer with ID '{id}' was not found");
+                }
+
+                _logger.LogInformation("Customer {CustomerId} deleted", id);
+
+                return NoContent();
+            }
+            catch (Exception ex)
+            {
+                _logger.LogError(ex, "Failed to delete customer {CustomerId}", id);
+                
+                return InternalServerErrorResponse("An error occurred while deleting the customer", exception: ex);
+            }
+        }
     }
 }
\ No newline at end of file
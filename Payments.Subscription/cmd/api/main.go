@@ -2,19 +2,41 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 
 	"payments-subscription/config"
+	"payments-subscription/internal/common/idempotency"
+	idempotencymysql "payments-subscription/internal/common/idempotency/mysql"
 	"payments-subscription/internal/common/logging"
 	"payments-subscription/internal/common/middleware"
 	opentel "payments-subscription/internal/common/telemetry"
 	"payments-subscription/internal/customer"
+	"payments-subscription/internal/plan"
+	planmysql "payments-subscription/internal/plan/mysql"
 	"payments-subscription/internal/subscription"
+	"payments-subscription/internal/subscription/kafka"
 	mysql "payments-subscription/internal/subscription/mysql"
+	natsbroker "payments-subscription/internal/subscription/nats"
 
 	"github.com/gorilla/mux"
+	"github.com/nats-io/nats.go/jetstream"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Publishers disponíveis para os eventos do outbox (EVENT_PUBLISHER)
+const (
+	eventPublisherMemory = "memory"
+	eventPublisherKafka  = "kafka"
+	eventPublisherNATS   = "nats"
+)
+
+// Consumers disponíveis para os eventos de outros serviços (EVENT_CONSUMER)
+const (
+	eventConsumerNone = "none"
+	eventConsumerNATS = "nats"
 )
 
 func main() {
@@ -32,6 +54,10 @@ func main() {
 	// Obtém o tracer configurado
 	tracer := ot.GetTracer()
 
+	// Exporta as métricas via OTLP; precisa vir antes dos componentes que criam instrumentos
+	ot.SetupMetrics()
+	defer ot.Shutdown(context.Background())
+
 	// Conecta com o banco de dados
 	db, err := cfg.NewDatabaseConnection()
 	if err != nil {
@@ -44,23 +70,181 @@ func main() {
 	// Inicializa as dependências seguindo DDD
 	repository := mysql.NewMySQLSubscriptionRepository(db)
 
-	// Aplica o decorator de tracing ao repositório
-	repositoryDecored := subscription.NewSubscriptionRepositoryTracingDecorator(repository, tracer)
+	// Aplica os decorators de timeout e tracing ao repositório
+	repositoryWithTimeout := subscription.NewSubscriptionRepositoryTimeoutDecorator(repository, cfg.Timeouts.DBRead, cfg.Timeouts.DBWrite)
+	repositoryDecored := subscription.NewSubscriptionRepositoryTracingDecorator(repositoryWithTimeout, tracer)
+
+	// Contexto dos processos em background (relay do outbox, worker de renovação e recuperação de sagas)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Opcionalmente roda o worker de renovação no mesmo processo da API
+	if cfg.Renewal.Enabled {
+		renewalScheduler := subscription.NewRenewalScheduler(
+			repositoryDecored,
+			cfg.Renewal.Interval,
+			cfg.Renewal.BatchSize,
+			cfg.Renewal.LeaseDuration,
+			cfg.Renewal.TrialNotice,
+		)
+		go renewalScheduler.Run(backgroundCtx)
+	}
+
+	// Conexão JetStream compartilhada pelo publisher e pelo consumer quando algum deles usa NATS
+	var js jetstream.JetStream
+	if cfg.EventPublisher.Kind == eventPublisherNATS || cfg.EventConsumer.Kind == eventConsumerNATS {
+		natsConn, natsJS, err := natsbroker.Connect(newNATSConfig(cfg))
+		if err != nil {
+			logger.Error(context.Background(), "NATSConnection", "Failed to connect to NATS", err, map[string]interface{}{
+				"nats_url": cfg.NATS.URL,
+			})
+			os.Exit(1)
+		}
+		defer natsConn.Drain()
+
+		if err := natsbroker.EnsureStream(context.Background(), natsJS, newNATSConfig(cfg)); err != nil {
+			logger.Error(context.Background(), "NATSConnection", "Failed to configure JetStream stream", err, map[string]interface{}{
+				"stream": cfg.NATS.Stream,
+			})
+			os.Exit(1)
+		}
+		js = natsJS
+	}
+
+	subscriptionEventPublisher, closePublisher, err := newEventPublisher(cfg, tracer, js)
+	if err != nil {
+		logger.Error(context.Background(), "EventPublisher", "Failed to create event publisher", err, map[string]interface{}{
+			"event_publisher": cfg.EventPublisher.Kind,
+		})
+		os.Exit(1)
+	}
+	defer closePublisher()
+
+	// Dead-letter queue das publicações e dos handlers que esgotaram as tentativas
+	deadLetterRepository := subscription.NewDeadLetterRepositoryTimeoutDecorator(
+		mysql.NewMySQLDeadLetterRepository(db),
+		cfg.Timeouts.DBRead,
+		cfg.Timeouts.DBWrite,
+	)
 
-	subscriptionEventPublisher := subscription.NewInMemoryEventPublisher()
-	subscriptionEventService := subscription.NewSubscriptionEventService(subscriptionEventPublisher)
+	// Inicia o relay do outbox em background para publicar os eventos persistidos
+	outboxRepository := subscription.NewOutboxRepositoryTimeoutDecorator(mysql.NewMySQLOutboxRepository(db), cfg.Timeouts.DBWrite)
+	outboxRelay := subscription.NewOutboxRelay(
+		outboxRepository,
+		subscriptionEventPublisher,
+		deadLetterRepository,
+		cfg.Outbox.PollInterval,
+		cfg.Outbox.BatchSize,
+		cfg.Outbox.MaxAttempts,
+	)
+	go outboxRelay.Run(backgroundCtx)
 
 	// Cria o cliente do serviço de Customer
-	customerClient := customer.NewCustomerClient(cfg.CustomerServiceURL)
+	customerClient := customer.NewCustomerClient(cfg.CustomerServiceURL, cfg.Timeouts.CustomerCall, customer.RetryPolicy{
+		MaxAttempts:          cfg.CustomerRetry.MaxAttempts,
+		BaseDelay:            cfg.CustomerRetry.BaseDelay,
+		MaxDelay:             cfg.CustomerRetry.MaxDelay,
+		RetryableStatusCodes: cfg.CustomerRetry.RetryableStatusCodes,
+	})
+	customerClientBreaker := customer.NewCustomerClientCircuitBreaker(customerClient, customer.CircuitBreakerSettings{
+		FailureRatio:        cfg.CustomerBreaker.FailureRatio,
+		MinRequests:         cfg.CustomerBreaker.MinRequests,
+		Window:              cfg.CustomerBreaker.Window,
+		Cooldown:            cfg.CustomerBreaker.Cooldown,
+		HalfOpenMaxRequests: cfg.CustomerBreaker.HalfOpenMaxRequests,
+	})
+
+	// Catálogo de planos
+	planRepository := plan.NewPlanRepositoryTracingDecorator(
+		plan.NewPlanRepositoryTimeoutDecorator(planmysql.NewMySQLPlanRepository(db), cfg.Timeouts.DBRead, cfg.Timeouts.DBWrite),
+		tracer,
+	)
+	planService := plan.NewPlanServiceTracingDecorator(plan.NewPlanService(planRepository), tracer)
+	planHandler := plan.NewPlanHandler(planService)
+
+	// Saga de criação: compensa customers órfãos e retoma sagas interrompidas em background
+	sagaRepository := subscription.NewSagaRepositoryTimeoutDecorator(mysql.NewMySQLSagaRepository(db), cfg.Timeouts.DBWrite)
+	sagaCoordinator := subscription.NewSagaCoordinator(sagaRepository, repositoryDecored, customerClientBreaker)
+	sagaRecovery := subscription.NewSagaRecovery(
+		sagaCoordinator,
+		sagaRepository,
+		cfg.Saga.RecoveryInterval,
+		cfg.Saga.StaleAfter,
+		cfg.Saga.BatchSize,
+	)
+	go sagaRecovery.Run(backgroundCtx)
+
+	onboardingMode, err := subscription.ParseOnboardingMode(cfg.Onboarding.Mode)
+	if err != nil {
+		logger.Error(context.Background(), "ServiceStartup", "Invalid onboarding mode", err, map[string]interface{}{
+			"onboarding_mode": cfg.Onboarding.Mode,
+		})
+		os.Exit(1)
+	}
+	consumerEnabled := cfg.EventConsumer.Kind == eventConsumerNATS
+	if err := onboardingMode.RequireCustomerEventSource(consumerEnabled, cfg.Auth.InternalEventsToken != ""); err != nil {
+		logger.Error(context.Background(), "ServiceStartup", "Async onboarding requires EVENT_CONSUMER=nats or INTERNAL_EVENTS_TOKEN", err, map[string]interface{}{
+			"onboarding_mode": cfg.Onboarding.Mode,
+			"event_consumer":  cfg.EventConsumer.Kind,
+		})
+		os.Exit(1)
+	}
 
 	// Cria o serviço base
-	subscriptionService := subscription.NewSubscriptionService(repositoryDecored, subscriptionEventService, customerClient)
+	subscriptionService := subscription.NewSubscriptionService(
+		repositoryDecored,
+		customerClientBreaker,
+		planRepository,
+		sagaCoordinator,
+		onboardingMode,
+	)
 
 	// Aplica o decorador de tracing
 	subscriptionServiceDecored := subscription.NewSubscriptionServiceTracingDecorator(subscriptionService, tracer)
 
 	subscriptionHandler := subscription.NewSubscriptionHandler(subscriptionServiceDecored)
 
+	// Eventos do serviço de Customer que concluem o onboarding assíncrono, recebidos via HTTP ou JetStream
+	poisonHandler := subscription.NewDeadLetterPoisonHandler(deadLetterRepository)
+	eventDispatcher := subscription.NewEventDispatcher(
+		tracer,
+		cfg.Dispatcher.Concurrency,
+		subscription.ExponentialRetryPolicy{
+			MaxAttempts: cfg.Dispatcher.MaxAttempts,
+			BaseDelay:   cfg.Dispatcher.BaseDelay,
+			MaxDelay:    cfg.Dispatcher.MaxDelay,
+		},
+		poisonHandler,
+	)
+	eventDispatcher.Register(subscription.NewCustomerOnboardingHandler(repositoryDecored))
+	inboundEventHandler := subscription.NewInboundEventHandler(eventDispatcher)
+
+	deadLetterService := subscription.NewDeadLetterService(deadLetterRepository, subscriptionEventPublisher, eventDispatcher)
+	deadLetterHandler := subscription.NewDeadLetterHandler(deadLetterService)
+
+	switch cfg.EventConsumer.Kind {
+	case eventConsumerNone:
+	case eventConsumerNATS:
+		eventConsumer := natsbroker.NewEventConsumer(js, newNATSConfig(cfg), tracer, eventDispatcher, poisonHandler)
+		go func() {
+			if err := eventConsumer.Run(backgroundCtx); err != nil {
+				logger.Error(backgroundCtx, "EventConsumer", "JetStream consumer stopped", err, map[string]interface{}{
+					"durable": cfg.NATS.Durable,
+				})
+			}
+		}()
+	default:
+		logger.Error(context.Background(), "ServiceStartup", "Invalid event consumer", nil, map[string]interface{}{
+			"event_consumer": cfg.EventConsumer.Kind,
+		})
+		os.Exit(1)
+	}
+
+	// Chaves de idempotência do POST /subscriptions
+	idempotencyStore := idempotency.NewStoreTimeoutDecorator(idempotencymysql.NewMySQLIdempotencyStore(db), cfg.Timeouts.DBWrite)
+	idempotencyMiddleware := idempotency.Middleware(idempotencyStore, cfg.Idempotency.TTL, cfg.Idempotency.ProcessingLease)
+	go idempotency.RunCleanup(backgroundCtx, idempotencyStore, cfg.Idempotency.CleanupInterval)
+
 	// Configura o router HTTP com middleware de tracing
 	router := mux.NewRouter()
 
@@ -77,11 +261,40 @@ func main() {
 		otelmux.WithPropagators(ot.GetPropagators()),
 	))
 
+	// 4. Deadline total da requisição
+	router.Use(middleware.TimeoutMiddleware(cfg.Timeouts.Request))
+
 	// Configura as rotas
-	router.HandleFunc("/subscriptions", subscriptionHandler.CreateSubscription).Methods("POST")
+	router.Handle("/subscriptions", idempotencyMiddleware(http.HandlerFunc(subscriptionHandler.CreateSubscription))).Methods("POST")
 	router.HandleFunc("/subscriptions/{id}", subscriptionHandler.GetSubscriptionByID).Methods("GET")
-	router.HandleFunc("/subscriptions", subscriptionHandler.GetAllSubscriptions).Methods("GET")
+	router.HandleFunc("/subscriptions", subscriptionHandler.ListSubscriptions).Methods("GET")
+	router.HandleFunc("/customers/{customerId}/subscriptions", subscriptionHandler.ListCustomerSubscriptions).Methods("GET")
 	router.HandleFunc("/subscriptions/{id}/activate", subscriptionHandler.ActivateSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/cancel", subscriptionHandler.CancelSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/suspend", subscriptionHandler.SuspendSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/resume", subscriptionHandler.ResumeSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/payment-method", subscriptionHandler.AttachPaymentMethod).Methods("PUT")
+	router.HandleFunc("/subscriptions/{id}/change-plan", subscriptionHandler.ChangePlan).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/cancel-at-period-end", subscriptionHandler.ScheduleCancellation).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/cancel-at-period-end", subscriptionHandler.UndoScheduledCancellation).Methods("DELETE")
+
+	// Eventos de outros serviços vinculam customers a subscriptions, então exigem o segredo compartilhado
+	internalEventsAuth := middleware.BearerTokenMiddleware(cfg.Auth.InternalEventsToken)
+	router.Handle("/internal/events", internalEventsAuth(http.HandlerFunc(inboundEventHandler.ReceiveEvent))).Methods("POST")
+	if cfg.Auth.InternalEventsToken == "" {
+		logger.Info(context.Background(), "ServiceStartup", "INTERNAL_EVENTS_TOKEN is not set, POST /internal/events will reject every request", nil)
+	}
+
+	// Rotas do catálogo de planos
+	planHandler.RegisterRoutes(router)
+
+	// Rotas administrativas, incluindo a dead-letter queue, exigem o token de administração
+	adminRouter := router.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(middleware.BearerTokenMiddleware(cfg.Auth.AdminToken))
+	deadLetterHandler.RegisterRoutes(adminRouter)
+	if cfg.Auth.AdminToken == "" {
+		logger.Info(context.Background(), "ServiceStartup", "ADMIN_API_TOKEN is not set, /admin routes will reject every request", nil)
+	}
 
 	// Health check endpoint
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -96,6 +309,9 @@ func main() {
 	logger.Info(ctx, "ServiceStartup", "Subscription Service started", map[string]interface{}{
 		"port":                 cfg.Server.Port,
 		"customer_service_url": cfg.CustomerServiceURL,
+		"onboarding_mode":      string(onboardingMode),
+		"event_publisher":      cfg.EventPublisher.Kind,
+		"event_consumer":       cfg.EventConsumer.Kind,
 	})
 
 	// Inicia o servidor
@@ -106,3 +322,52 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newEventPublisher cria o publisher configurado e a função que o encerra no shutdown
+func newEventPublisher(cfg *config.Config, tracer trace.Tracer, js jetstream.JetStream) (subscription.EventPublisher, func(), error) {
+	cloudEventMode, err := subscription.ParseCloudEventMode(cfg.CloudEvents.Mode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch cfg.EventPublisher.Kind {
+	case eventPublisherMemory:
+		return subscription.NewInMemoryEventPublisher(cfg.CloudEvents.Source), func() {}, nil
+	case eventPublisherKafka:
+		publisher, err := kafka.NewEventPublisher(kafka.Config{
+			Brokers:    cfg.Kafka.Brokers,
+			Topic:      cfg.Kafka.Topic,
+			ClientID:   cfg.Kafka.ClientID,
+			AckTimeout: cfg.Kafka.AckTimeout,
+			Source:     cfg.CloudEvents.Source,
+			Mode:       cloudEventMode,
+		}, tracer)
+		if err != nil {
+			return nil, nil, err
+		}
+		return publisher, publisher.Close, nil
+	case eventPublisherNATS:
+		natsConfig := newNATSConfig(cfg)
+		natsConfig.CloudEventMode = cloudEventMode
+		return natsbroker.NewEventPublisher(js, natsConfig, tracer), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("publisher de eventos desconhecido: %q", cfg.EventPublisher.Kind)
+	}
+}
+
+// newNATSConfig converte as configurações do NATS para o adaptador JetStream
+func newNATSConfig(cfg *config.Config) natsbroker.Config {
+	return natsbroker.Config{
+		URL:              cfg.NATS.URL,
+		Stream:           cfg.NATS.Stream,
+		StreamSubjects:   cfg.NATS.StreamSubjects,
+		SubjectPrefix:    cfg.NATS.SubjectPrefix,
+		ConsumerSubjects: cfg.NATS.ConsumerSubjects,
+		Durable:          cfg.NATS.Durable,
+		MaxDeliver:       cfg.NATS.MaxDeliver,
+		AckWait:          cfg.NATS.AckWait,
+		RetryDelay:       cfg.NATS.RetryDelay,
+		PublishTimeout:   cfg.NATS.PublishTimeout,
+		CloudEventSource: cfg.CloudEvents.Source,
+	}
+}
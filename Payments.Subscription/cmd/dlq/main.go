@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// deadLetter espelha os campos da resposta das rotas /admin/dead-letters usados pelo CLI
+type deadLetter struct {
+	ID             string            `json:"id"`
+	EventID        string            `json:"event_id"`
+	Source         string            `json:"source"`
+	Status         string            `json:"status"`
+	EventType      string            `json:"event_type"`
+	AggregateID    string            `json:"aggregate_id"`
+	CorrelationID  string            `json:"correlation_id"`
+	Handler        string            `json:"handler,omitempty"`
+	Payload        json.RawMessage   `json:"payload"`
+	Headers        map[string]string `json:"headers"`
+	Error          string            `json:"error"`
+	Attempts       int               `json:"attempts"`
+	ReplayAttempts int               `json:"replay_attempts"`
+	OccurredAt     time.Time         `json:"occurred_at"`
+	CreatedAt      time.Time         `json:"created_at"`
+	ResolvedAt     *time.Time        `json:"resolved_at,omitempty"`
+}
+
+const usage = `Uso: dlq [-addr URL] [-token TOKEN] <comando> [argumentos]
+
+O token de administração vem de -token ou da variável ADMIN_API_TOKEN.
+
+Comandos:
+  list [-event-type T] [-aggregate-id ID] [-correlation-id ID] [-source S] [-status S] [-limit N]
+  show <id>
+  replay <id>
+  discard <id>
+`
+
+// CLI de administração da dead-letter queue sobre as rotas /admin/dead-letters da API
+func main() {
+	addr := flag.String("addr", getEnvOrDefault("SUBSCRIPTION_API_URL", "http://localhost:8081"), "endereço da API de subscriptions")
+	token := flag.String("token", os.Getenv("ADMIN_API_TOKEN"), "token de administração enviado em Authorization: Bearer")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *token == "" {
+		fmt.Fprintln(os.Stderr, "erro: informe o token de administração com -token ou ADMIN_API_TOKEN")
+		os.Exit(2)
+	}
+
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: bearerTransport{token: *token, next: http.DefaultTransport},
+	}
+	baseURL := strings.TrimRight(*addr, "/") + "/admin/dead-letters"
+
+	var err error
+	switch command, args := flag.Arg(0), flag.Args()[1:]; command {
+	case "list":
+		err = list(client, baseURL, args)
+	case "show":
+		err = withID(args, func(id string) error { return show(client, baseURL, id) })
+	case "replay":
+		err = withID(args, func(id string) error { return resolve(client, baseURL, id, "replay") })
+	case "discard":
+		err = withID(args, func(id string) error { return resolve(client, baseURL, id, "discard") })
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "erro:", err)
+		os.Exit(1)
+	}
+}
+
+// list imprime os eventos da dead-letter queue que atendem aos filtros
+func list(client *http.Client, baseURL string, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	filters := map[string]*string{
+		"event_type":     fs.String("event-type", "", "filtra pelo tipo do evento"),
+		"aggregate_id":   fs.String("aggregate-id", "", "filtra pelo ID do agregado"),
+		"correlation_id": fs.String("correlation-id", "", "filtra pelo correlation ID"),
+		"source":         fs.String("source", "", "filtra pela origem (publish ou handler)"),
+		"status":         fs.String("status", "pending", "filtra pelo status (pending, replaying, replayed ou discarded)"),
+	}
+	limit := fs.Int("limit", 0, "quantidade máxima de eventos")
+	fs.Parse(args)
+
+	query := url.Values{}
+	for name, value := range filters {
+		if *value != "" {
+			query.Set(name, *value)
+		}
+	}
+	if *limit > 0 {
+		query.Set("limit", strconv.Itoa(*limit))
+	}
+
+	endpoint := baseURL
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var events []deadLetter
+	if err := call(client, http.MethodGet, endpoint, &events); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tSOURCE\tEVENT TYPE\tAGGREGATE ID\tATTEMPTS\tCREATED AT\tERROR")
+	for _, event := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			event.ID, event.Status, event.Source, event.EventType, event.AggregateID,
+			event.Attempts, event.CreatedAt.Format(time.RFC3339), truncate(event.Error, 60))
+	}
+	return w.Flush()
+}
+
+// show imprime o evento completo, incluindo payload e cabeçalhos
+func show(client *http.Client, baseURL, id string) error {
+	var event deadLetter
+	if err := call(client, http.MethodGet, baseURL+"/"+url.PathEscape(id), &event); err != nil {
+		return err
+	}
+	return printJSON(event)
+}
+
+// resolve reprocessa ou descarta o evento e imprime o resultado
+func resolve(client *http.Client, baseURL, id, action string) error {
+	var event deadLetter
+	if err := call(client, http.MethodPost, baseURL+"/"+url.PathEscape(id)+"/"+action, &event); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", event.ID, event.Status)
+	return nil
+}
+
+// bearerTransport envia o token de administração em todas as requisições do CLI
+type bearerTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+// RoundTrip implementa http.RoundTripper sem alterar a requisição original
+func (t bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.next.RoundTrip(req)
+}
+
+// call executa a requisição e decodifica o campo data da resposta; respostas de erro
+// (problem+json) são devolvidas como erro com o detail informado pela API
+func call(client *http.Client, method, endpoint string, target interface{}) error {
+	req, err := http.NewRequest(method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao chamar %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("erro ao ler resposta: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var problem struct {
+			Code   string `json:"code"`
+			Detail string `json:"detail"`
+		}
+		if json.Unmarshal(body, &problem) == nil && problem.Detail != "" {
+			return fmt.Errorf("%d %s: %s", resp.StatusCode, problem.Code, problem.Detail)
+		}
+		return fmt.Errorf("%d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{Data: target}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+	return nil
+}
+
+// withID valida que o comando recebeu exatamente um ID
+func withID(args []string, run func(id string) error) error {
+	if len(args) != 1 {
+		return fmt.Errorf("informe o ID do evento")
+	}
+	return run(args[0])
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
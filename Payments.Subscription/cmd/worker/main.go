@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"payments-subscription/config"
+	"payments-subscription/internal/common/logging"
+	opentel "payments-subscription/internal/common/telemetry"
+	"payments-subscription/internal/subscription"
+	mysql "payments-subscription/internal/subscription/mysql"
+)
+
+// Worker de renovação executado como binário separado da API
+func main() {
+	// Carrega as configurações
+	cfg := config.LoadConfig()
+
+	// Inicializa o logger estruturado
+	logger := logging.NewStructuredLogger("subscription-service")
+
+	// Inicializa o OpenTelemetry
+	ot := opentel.NewOpenTel()
+	ot.ServiceName = cfg.Telemetry.ServiceName + "-worker"
+	ot.ServiceVersion = cfg.Telemetry.ServiceVersion
+
+	// Obtém o tracer configurado
+	tracer := ot.GetTracer()
+
+	// Encerra o worker ao receber SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Conecta com o banco de dados
+	db, err := cfg.NewDatabaseConnection()
+	if err != nil {
+		logger.Error(ctx, "DatabaseConnection", "Failed to connect to database", err, nil)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	repository := mysql.NewMySQLSubscriptionRepository(db)
+	repositoryWithTimeout := subscription.NewSubscriptionRepositoryTimeoutDecorator(repository, cfg.Timeouts.DBRead, cfg.Timeouts.DBWrite)
+	repositoryDecored := subscription.NewSubscriptionRepositoryTracingDecorator(repositoryWithTimeout, tracer)
+
+	renewalScheduler := subscription.NewRenewalScheduler(
+		repositoryDecored,
+		cfg.Renewal.Interval,
+		cfg.Renewal.BatchSize,
+		cfg.Renewal.LeaseDuration,
+		cfg.Renewal.TrialNotice,
+	)
+
+	logger.Info(ctx, "WorkerStartup", "Renewal worker started", map[string]interface{}{
+		"interval":   cfg.Renewal.Interval.String(),
+		"batch_size": cfg.Renewal.BatchSize,
+	})
+
+	renewalScheduler.Run(ctx)
+
+	logger.Info(context.Background(), "WorkerShutdown", "Renewal worker stopped", nil)
+}
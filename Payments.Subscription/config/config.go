@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
@@ -24,7 +27,89 @@ type Config struct {
 		ServiceName    string
 		ServiceVersion string
 	}
+	Outbox struct {
+		PollInterval time.Duration
+		BatchSize    int
+		MaxAttempts  int
+	}
+	Renewal struct {
+		Enabled       bool
+		Interval      time.Duration
+		BatchSize     int
+		LeaseDuration time.Duration
+		TrialNotice   time.Duration
+	}
+	Idempotency struct {
+		TTL             time.Duration
+		CleanupInterval time.Duration
+		ProcessingLease time.Duration
+	}
+	Timeouts struct {
+		Request      time.Duration
+		CustomerCall time.Duration
+		DBRead       time.Duration
+		DBWrite      time.Duration
+	}
+	Onboarding struct {
+		Mode string
+	}
+	EventPublisher struct {
+		Kind string
+	}
+	EventConsumer struct {
+		Kind string
+	}
+	CloudEvents struct {
+		Source string
+		Mode   string
+	}
+	Dispatcher struct {
+		Concurrency int
+		MaxAttempts int
+		BaseDelay   time.Duration
+		MaxDelay    time.Duration
+	}
+	NATS struct {
+		URL              string
+		Stream           string
+		StreamSubjects   []string
+		SubjectPrefix    string
+		ConsumerSubjects []string
+		Durable          string
+		MaxDeliver       int
+		AckWait          time.Duration
+		RetryDelay       time.Duration
+		PublishTimeout   time.Duration
+	}
+	Kafka struct {
+		Brokers    []string
+		Topic      string
+		ClientID   string
+		AckTimeout time.Duration
+	}
 	CustomerServiceURL string
+	CustomerRetry      struct {
+		MaxAttempts          int
+		BaseDelay            time.Duration
+		MaxDelay             time.Duration
+		RetryableStatusCodes []int
+	}
+	CustomerBreaker struct {
+		FailureRatio        float64
+		MinRequests         int
+		Window              time.Duration
+		Cooldown            time.Duration
+		HalfOpenMaxRequests int
+	}
+	Saga struct {
+		RecoveryInterval time.Duration
+		StaleAfter       time.Duration
+		BatchSize        int
+	}
+	Auth struct {
+		InternalEventsToken string
+		AdminToken          string
+	}
 }
 
 // LoadConfig carrega as configurações da aplicação
@@ -45,9 +130,99 @@ func LoadConfig() *Config {
 	cfg.Telemetry.ServiceName = getEnvOrDefault("TELEMETRY_SERVICE_NAME", "subscription-service")
 	cfg.Telemetry.ServiceVersion = getEnvOrDefault("TELEMETRY_SERVICE_VERSION", "1.0.0")
 
+	// Configurações do relay do outbox
+	cfg.Outbox.PollInterval = getDurationOrDefault("OUTBOX_POLL_INTERVAL", 2*time.Second)
+	cfg.Outbox.BatchSize = getIntOrDefault("OUTBOX_BATCH_SIZE", 100)
+	cfg.Outbox.MaxAttempts = getIntOrDefault("OUTBOX_MAX_ATTEMPTS", 10)
+
+	// Configurações do worker de renovação (Enabled roda o worker dentro da API)
+	cfg.Renewal.Enabled = getBoolOrDefault("RENEWAL_WORKER_ENABLED", false)
+	cfg.Renewal.Interval = getDurationOrDefault("RENEWAL_INTERVAL", time.Minute)
+	cfg.Renewal.BatchSize = getIntOrDefault("RENEWAL_BATCH_SIZE", 100)
+	cfg.Renewal.LeaseDuration = getDurationOrDefault("RENEWAL_LEASE_DURATION", 5*time.Minute)
+	cfg.Renewal.TrialNotice = getDurationOrDefault("RENEWAL_TRIAL_NOTICE", 72*time.Hour)
+
+	// Configurações das chaves de idempotência
+	cfg.Idempotency.TTL = getDurationOrDefault("IDEMPOTENCY_TTL", 24*time.Hour)
+	cfg.Idempotency.CleanupInterval = getDurationOrDefault("IDEMPOTENCY_CLEANUP_INTERVAL", time.Hour)
+
+	// Orçamentos de tempo por dependência (aplicados como deadline no contexto)
+	cfg.Timeouts.Request = getDurationOrDefault("REQUEST_TIMEOUT", 15*time.Second)
+	cfg.Timeouts.CustomerCall = getDurationOrDefault("CUSTOMER_CALL_TIMEOUT", 3*time.Second)
+	cfg.Timeouts.DBRead = getDurationOrDefault("DB_READ_TIMEOUT", 2*time.Second)
+	cfg.Timeouts.DBWrite = getDurationOrDefault("DB_WRITE_TIMEOUT", 5*time.Second)
+
+	// Uma chave em processamento além deste prazo foi abandonada (ex: queda da instância) e pode ser
+	// reservada de novo; o padrão cobre o orçamento da requisição com folga
+	cfg.Idempotency.ProcessingLease = getDurationOrDefault("IDEMPOTENCY_PROCESSING_LEASE", 2*cfg.Timeouts.Request)
+
+	// Modo de onboarding do customer: sync (HTTP) ou async (eventos). O async depende do serviço de
+	// Customer responder ao SubscriptionRequested com CustomerCreated/CustomerVerified, recebidos pelo
+	// consumer (EVENT_CONSUMER=nats) ou em POST /internal/events (INTERNAL_EVENTS_TOKEN); sem nenhum dos
+	// dois o serviço não inicia
+	cfg.Onboarding.Mode = getEnvOrDefault("ONBOARDING_MODE", "sync")
+
+	// Publisher dos eventos do outbox: memory (apenas log), kafka ou nats
+	cfg.EventPublisher.Kind = getEnvOrDefault("EVENT_PUBLISHER", "memory")
+
+	// Envelope CloudEvents dos eventos publicados: source e modo (structured ou binary) no Kafka e no NATS
+	cfg.CloudEvents.Source = getEnvOrDefault("CLOUDEVENTS_SOURCE", "/payments/subscription-service")
+	cfg.CloudEvents.Mode = getEnvOrDefault("CLOUDEVENTS_MODE", "structured")
+
+	// Consumer dos eventos de outros serviços: none (apenas POST /internal/events) ou nats
+	cfg.EventConsumer.Kind = getEnvOrDefault("EVENT_CONSUMER", "none")
+
+	// Segredo compartilhado exigido em POST /internal/events (Authorization: Bearer); vazio recusa tudo
+	cfg.Auth.InternalEventsToken = getEnvOrDefault("INTERNAL_EVENTS_TOKEN", "")
+
+	// Segredo exigido nas rotas administrativas em /admin (Authorization: Bearer); vazio recusa tudo
+	cfg.Auth.AdminToken = getEnvOrDefault("ADMIN_API_TOKEN", "")
+
+	// Despacho dos eventos recebidos para os handlers
+	cfg.Dispatcher.Concurrency = getIntOrDefault("EVENT_DISPATCHER_CONCURRENCY", 8)
+	cfg.Dispatcher.MaxAttempts = getIntOrDefault("EVENT_DISPATCHER_MAX_ATTEMPTS", 3)
+	cfg.Dispatcher.BaseDelay = getDurationOrDefault("EVENT_DISPATCHER_BASE_DELAY", 200*time.Millisecond)
+	cfg.Dispatcher.MaxDelay = getDurationOrDefault("EVENT_DISPATCHER_MAX_DELAY", 5*time.Second)
+
+	// Configurações do NATS JetStream
+	cfg.NATS.URL = getEnvOrDefault("NATS_URL", "nats://localhost:4222")
+	cfg.NATS.Stream = getEnvOrDefault("NATS_STREAM", "PAYMENTS_EVENTS")
+	cfg.NATS.StreamSubjects = getListOrDefault("NATS_STREAM_SUBJECTS", []string{"subscription.events.>", "customer.events.>"})
+	cfg.NATS.SubjectPrefix = getEnvOrDefault("NATS_SUBJECT_PREFIX", "subscription.events")
+	cfg.NATS.ConsumerSubjects = getListOrDefault("NATS_CONSUMER_SUBJECTS", []string{"customer.events.>"})
+	cfg.NATS.Durable = getEnvOrDefault("NATS_DURABLE", "subscription-service")
+	cfg.NATS.MaxDeliver = getIntOrDefault("NATS_MAX_DELIVER", 5)
+	cfg.NATS.AckWait = getDurationOrDefault("NATS_ACK_WAIT", 30*time.Second)
+	cfg.NATS.RetryDelay = getDurationOrDefault("NATS_RETRY_DELAY", 5*time.Second)
+	cfg.NATS.PublishTimeout = getDurationOrDefault("NATS_PUBLISH_TIMEOUT", 5*time.Second)
+
+	// Configurações do publisher Kafka
+	cfg.Kafka.Brokers = getListOrDefault("KAFKA_BROKERS", []string{"localhost:9092"})
+	cfg.Kafka.Topic = getEnvOrDefault("KAFKA_TOPIC", "subscription-events")
+	cfg.Kafka.ClientID = getEnvOrDefault("KAFKA_CLIENT_ID", "subscription-service")
+	cfg.Kafka.AckTimeout = getDurationOrDefault("KAFKA_ACK_TIMEOUT", 10*time.Second)
+
 	// URL do serviço de Customer
 	cfg.CustomerServiceURL = getEnvOrDefault("CUSTOMER_SERVICE_URL", "http://payments.customer/api/customer")
 
+	// Política de retry das chamadas ao serviço de Customer
+	cfg.CustomerRetry.MaxAttempts = getIntOrDefault("CUSTOMER_RETRY_MAX_ATTEMPTS", 3)
+	cfg.CustomerRetry.BaseDelay = getDurationOrDefault("CUSTOMER_RETRY_BASE_DELAY", 100*time.Millisecond)
+	cfg.CustomerRetry.MaxDelay = getDurationOrDefault("CUSTOMER_RETRY_MAX_DELAY", 2*time.Second)
+	cfg.CustomerRetry.RetryableStatusCodes = getIntListOrDefault("CUSTOMER_RETRY_STATUS_CODES", []int{429, 502, 503, 504})
+
+	// Circuit breaker das chamadas ao serviço de Customer
+	cfg.CustomerBreaker.FailureRatio = getFloatOrDefault("CUSTOMER_BREAKER_FAILURE_RATIO", 0.5)
+	cfg.CustomerBreaker.MinRequests = getIntOrDefault("CUSTOMER_BREAKER_MIN_REQUESTS", 10)
+	cfg.CustomerBreaker.Window = getDurationOrDefault("CUSTOMER_BREAKER_WINDOW", time.Minute)
+	cfg.CustomerBreaker.Cooldown = getDurationOrDefault("CUSTOMER_BREAKER_COOLDOWN", 30*time.Second)
+	cfg.CustomerBreaker.HalfOpenMaxRequests = getIntOrDefault("CUSTOMER_BREAKER_HALF_OPEN_REQUESTS", 1)
+
+	// Recuperação das sagas de criação interrompidas
+	cfg.Saga.RecoveryInterval = getDurationOrDefault("SAGA_RECOVERY_INTERVAL", 30*time.Second)
+	cfg.Saga.StaleAfter = getDurationOrDefault("SAGA_STALE_AFTER", 2*time.Minute)
+	cfg.Saga.BatchSize = getIntOrDefault("SAGA_BATCH_SIZE", 50)
+
 	return cfg
 }
 
@@ -80,3 +255,80 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getIntOrDefault obtém uma variável de ambiente inteira ou retorna um valor padrão
+func getIntOrDefault(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getFloatOrDefault obtém uma variável de ambiente decimal ou retorna um valor padrão
+func getFloatOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getBoolOrDefault obtém uma variável de ambiente booleana ou retorna um valor padrão
+func getBoolOrDefault(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getDurationOrDefault obtém uma variável de ambiente de duração (ex: "5s") ou retorna um valor padrão
+func getDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getListOrDefault obtém uma lista de valores separados por vírgula (ex: "kafka-1:9092,kafka-2:9092") ou retorna um valor padrão
+func getListOrDefault(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var parsed []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			parsed = append(parsed, item)
+		}
+	}
+	if len(parsed) == 0 {
+		return defaultValue
+	}
+	return parsed
+}
+
+// getIntListOrDefault obtém uma lista de inteiros separados por vírgula (ex: "502,503") ou retorna um valor padrão
+func getIntListOrDefault(key string, defaultValue []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var parsed []int
+	for _, item := range strings.Split(value, ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return defaultValue
+		}
+		parsed = append(parsed, number)
+	}
+	return parsed
+}
@@ -0,0 +1,50 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// HeaderName é o header HTTP com a chave de idempotência enviada pelo cliente
+const HeaderName = "Idempotency-Key"
+
+// MaxKeyLength é o tamanho máximo da chave, limitado pela coluna idempotency_key
+const MaxKeyLength = 255
+
+// RecordStatus representa o estado de processamento de uma chave
+type RecordStatus string
+
+const (
+	RecordStatusProcessing RecordStatus = "processing"
+	RecordStatusCompleted  RecordStatus = "completed"
+)
+
+// Record representa uma chave de idempotência com a resposta original serializada
+type Record struct {
+	Key          string
+	RequestHash  string
+	Status       RecordStatus
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// Store define o contrato de persistência das chaves de idempotência
+type Store interface {
+	// Reserve grava a chave como em processamento por até processingLease. Se a chave já existir (e não
+	// estiver expirada nem com o processamento abandonado) retorna o registro existente e reserved = false
+	Reserve(ctx context.Context, key, requestHash string, ttl, processingLease time.Duration) (existing *Record, reserved bool, err error)
+
+	// Complete grava a resposta da requisição original. Só altera a chave se ela ainda estiver em
+	// processamento pela mesma requisição (requestHash), já que a reserva pode ter expirado e sido retomada
+	Complete(ctx context.Context, key, requestHash string, statusCode int, contentType string, body []byte) error
+
+	// Release remove uma reserva cuja requisição falhou, permitindo nova tentativa com a mesma chave.
+	// Assim como Complete, não afeta a chave reservada ou concluída por outra requisição
+	Release(ctx context.Context, key, requestHash string) error
+
+	// DeleteExpired remove as chaves expiradas e retorna quantas foram removidas
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
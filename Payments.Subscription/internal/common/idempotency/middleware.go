@@ -0,0 +1,155 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/common/problem"
+)
+
+// Códigos de erro devolvidos nas respostas problem+json do middleware
+const (
+	errorCodeInvalidRequest = "invalid_request"
+	errorCodeKeyMismatch    = "idempotency_key_mismatch"
+	errorCodeKeyInProgress  = "idempotency_key_in_progress"
+	errorCodeInternal       = "internal_error"
+)
+
+// Middleware honra o header Idempotency-Key: a primeira requisição é processada e sua resposta
+// armazenada; repetições com o mesmo corpo recebem a resposta original, e o reuso da chave com
+// outro corpo é rejeitado com 422. Uma chave que ficou em processamento por mais de processingLease
+// (ex: a instância caiu no meio da requisição) pode ser reservada de novo
+func Middleware(store Store, ttl, processingLease time.Duration) func(http.Handler) http.Handler {
+	logger := logging.NewStructuredLogger("subscription-service")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderName)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if utf8.RuneCountInString(key) > MaxKeyLength {
+				problem.Write(w, r, problem.New(http.StatusBadRequest, errorCodeInvalidRequest,
+					fmt.Sprintf("Idempotency-Key must have at most %d characters", MaxKeyLength)))
+				return
+			}
+
+			ctx := r.Context()
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				problem.Write(w, r, problem.New(http.StatusBadRequest, errorCodeInvalidRequest, "Invalid request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			requestHash := hashRequest(r, body)
+
+			existing, reserved, err := store.Reserve(ctx, key, requestHash, ttl, processingLease)
+			if err != nil {
+				logger.Error(ctx, "Idempotency", "Erro ao reservar chave de idempotência", err, nil)
+				problem.Write(w, r, problem.New(http.StatusInternalServerError, errorCodeInternal, "Failed to process idempotency key"))
+				return
+			}
+
+			if !reserved {
+				replay(w, r, existing, requestHash)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// Falhas do servidor não são memorizadas para que o cliente possa tentar novamente
+			storeCtx := context.WithoutCancel(ctx)
+			if recorder.statusCode >= http.StatusInternalServerError {
+				if err := store.Release(storeCtx, key, requestHash); err != nil {
+					logger.Error(ctx, "Idempotency", "Erro ao liberar chave de idempotência", err, nil)
+				}
+				return
+			}
+
+			contentType := recorder.Header().Get("Content-Type")
+			if err := store.Complete(storeCtx, key, requestHash, recorder.statusCode, contentType, recorder.body.Bytes()); err != nil {
+				logger.Error(ctx, "Idempotency", "Erro ao armazenar resposta da chave de idempotência", err, nil)
+			}
+		})
+	}
+}
+
+// replay responde a uma chave já utilizada
+func replay(w http.ResponseWriter, r *http.Request, record *Record, requestHash string) {
+	if record.RequestHash != requestHash {
+		problem.Write(w, r, problem.New(http.StatusUnprocessableEntity, errorCodeKeyMismatch,
+			"Idempotency-Key was already used with a different request body"))
+		return
+	}
+
+	if record.Status != RecordStatusCompleted {
+		problem.Write(w, r, problem.New(http.StatusConflict, errorCodeKeyInProgress,
+			"A request with this Idempotency-Key is in progress"))
+		return
+	}
+
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.ResponseBody)
+}
+
+// hashRequest identifica a requisição pelo método, rota e corpo
+func hashRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte(" "))
+	hash.Write([]byte(r.URL.Path))
+	hash.Write([]byte("\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder repassa a resposta ao cliente e guarda uma cópia para replay
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	rr.statusCode = code
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+// RunCleanup remove periodicamente as chaves expiradas até o contexto ser cancelado
+func RunCleanup(ctx context.Context, store Store, interval time.Duration) {
+	logger := logging.NewStructuredLogger("subscription-service")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := store.DeleteExpired(ctx, time.Now()); err != nil {
+				logger.Error(ctx, "IdempotencyCleanup", "Erro ao remover chaves de idempotência expiradas", err, nil)
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryStore guarda as chaves em memória, com as mesmas guardas de status e hash do store MySQL
+type memoryStore struct {
+	records map[string]*Record
+}
+
+func (s *memoryStore) Reserve(_ context.Context, key, requestHash string, ttl, _ time.Duration) (*Record, bool, error) {
+	if existing, ok := s.records[key]; ok {
+		copied := *existing
+		return &copied, false, nil
+	}
+	now := time.Now()
+	s.records[key] = &Record{Key: key, RequestHash: requestHash, Status: RecordStatusProcessing, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key, requestHash string, statusCode int, contentType string, body []byte) error {
+	if record, ok := s.records[key]; ok && record.Status == RecordStatusProcessing && record.RequestHash == requestHash {
+		record.Status = RecordStatusCompleted
+		record.StatusCode = statusCode
+		record.ContentType = contentType
+		record.ResponseBody = append([]byte(nil), body...)
+	}
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key, requestHash string) error {
+	if record, ok := s.records[key]; ok && record.Status == RecordStatusProcessing && record.RequestHash == requestHash {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteExpired(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	const (
+		key  = "key-1"
+		body = `{"plan_id":"basic"}`
+	)
+	newRequest := func(key, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(HeaderName, key)
+		}
+		return req
+	}
+	requestHash := hashRequest(newRequest(key, body), []byte(body))
+
+	tests := []struct {
+		name string
+		key  string
+		body string
+		// existing é o registro da chave antes da requisição
+		existing      *Record
+		handlerStatus int
+
+		wantStatus      int
+		wantBody        string
+		wantReplayed    bool
+		wantHandlerCall bool
+		// wantRecord é o status da chave após a requisição, vazio se ela não deve existir
+		wantRecord RecordStatus
+	}{
+		{
+			name:            "primeira requisição é processada e a resposta armazenada",
+			key:             key,
+			body:            body,
+			handlerStatus:   http.StatusCreated,
+			wantStatus:      http.StatusCreated,
+			wantBody:        `{"id":"sub-1"}`,
+			wantHandlerCall: true,
+			wantRecord:      RecordStatusCompleted,
+		},
+		{
+			name:         "repetição devolve a resposta original sem chamar o handler",
+			key:          key,
+			body:         body,
+			existing:     &Record{Key: key, RequestHash: requestHash, Status: RecordStatusCompleted, StatusCode: http.StatusCreated, ContentType: "application/json", ResponseBody: []byte(`{"id":"sub-original"}`)},
+			wantStatus:   http.StatusCreated,
+			wantBody:     `{"id":"sub-original"}`,
+			wantReplayed: true,
+			wantRecord:   RecordStatusCompleted,
+		},
+		{
+			name:       "chave reutilizada com outro corpo é rejeitada",
+			key:        key,
+			body:       `{"plan_id":"pro"}`,
+			existing:   &Record{Key: key, RequestHash: requestHash, Status: RecordStatusCompleted, StatusCode: http.StatusCreated},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantRecord: RecordStatusCompleted,
+		},
+		{
+			name:       "requisição com a chave em processamento é rejeitada",
+			key:        key,
+			body:       body,
+			existing:   &Record{Key: key, RequestHash: requestHash, Status: RecordStatusProcessing},
+			wantStatus: http.StatusConflict,
+			wantRecord: RecordStatusProcessing,
+		},
+		{
+			name:       "chave maior que a coluna é rejeitada",
+			key:        strings.Repeat("k", MaxKeyLength+1),
+			body:       body,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "erro do servidor libera a chave para nova tentativa",
+			key:             key,
+			body:            body,
+			handlerStatus:   http.StatusInternalServerError,
+			wantStatus:      http.StatusInternalServerError,
+			wantHandlerCall: true,
+		},
+		{
+			name:            "requisição sem chave não usa o store",
+			body:            body,
+			handlerStatus:   http.StatusCreated,
+			wantStatus:      http.StatusCreated,
+			wantHandlerCall: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{records: map[string]*Record{}}
+			if tt.existing != nil {
+				store.records[tt.existing.Key] = tt.existing
+			}
+
+			handlerCalled := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				handlerCalled = true
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.handlerStatus)
+				w.Write([]byte(`{"id":"sub-1"}`))
+			})
+
+			rec := httptest.NewRecorder()
+			Middleware(store, time.Hour, time.Minute)(next).ServeHTTP(rec, newRequest(tt.key, tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", rec.Body.String(), tt.wantBody)
+			}
+			if got := rec.Header().Get("Idempotent-Replayed") == "true"; got != tt.wantReplayed {
+				t.Errorf("Idempotent-Replayed = %v, want %v", got, tt.wantReplayed)
+			}
+			if handlerCalled != tt.wantHandlerCall {
+				t.Errorf("handler called = %v, want %v", handlerCalled, tt.wantHandlerCall)
+			}
+
+			record, ok := store.records[key]
+			switch {
+			case tt.wantRecord == "" && ok:
+				t.Errorf("record = %+v, want none", record)
+			case tt.wantRecord != "" && (!ok || record.Status != tt.wantRecord):
+				t.Errorf("record = %+v, want status %s", record, tt.wantRecord)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/idempotency"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry é o código de erro do MySQL para violação de chave única
+const mysqlDuplicateEntry = 1062
+
+// maxReserveAttempts limita as tentativas de reserva quando a chave some entre o INSERT e a leitura
+const maxReserveAttempts = 3
+
+// MySQLIdempotencyStore implementa o idempotency.Store usando MySQL
+type MySQLIdempotencyStore struct {
+	db *sql.DB
+}
+
+// NewMySQLIdempotencyStore cria uma nova instância do store de idempotência
+func NewMySQLIdempotencyStore(db *sql.DB) *MySQLIdempotencyStore {
+	return &MySQLIdempotencyStore{
+		db: db,
+	}
+}
+
+// Reserve grava a chave como em processamento ou retorna o registro existente
+func (s *MySQLIdempotencyStore) Reserve(ctx context.Context, key, requestHash string, ttl, processingLease time.Duration) (*idempotency.Record, bool, error) {
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		record, reserved, err := s.tryReserve(ctx, key, requestHash, ttl, processingLease)
+		if err != nil || reserved || record != nil {
+			return record, reserved, err
+		}
+		// A chave foi liberada por outra requisição entre o INSERT e a leitura: tenta reservar de novo
+	}
+
+	return nil, false, fmt.Errorf("erro ao reservar chave de idempotência: chave %s liberada concorrentemente", key)
+}
+
+// tryReserve faz uma tentativa de reserva; retorna record nil e reserved false se a chave existente sumiu
+func (s *MySQLIdempotencyStore) tryReserve(ctx context.Context, key, requestHash string, ttl, processingLease time.Duration) (*idempotency.Record, bool, error) {
+	now := time.Now()
+
+	// Uma chave expirada, ou abandonada em processamento além do lease, pode ser reutilizada
+	staleQuery := `
+		DELETE FROM idempotency_keys
+		WHERE idempotency_key = ?
+		  AND (expires_at < ? OR (status = ? AND locked_until < ?))
+	`
+	if _, err := s.db.ExecContext(ctx, staleQuery, key, now, string(idempotency.RecordStatusProcessing), now); err != nil {
+		return nil, false, fmt.Errorf("erro ao remover chave de idempotência expirada: %w", err)
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (idempotency_key, request_hash, status, locked_until, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := s.db.ExecContext(ctx, query, key, requestHash, string(idempotency.RecordStatusProcessing), now.Add(processingLease), now, now.Add(ttl))
+	if err == nil {
+		return nil, true, nil
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return nil, false, fmt.Errorf("erro ao reservar chave de idempotência: %w", err)
+	}
+
+	record, err := s.get(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return record, false, nil
+}
+
+// Complete grava a resposta da requisição original, se a chave ainda estiver reservada por ela
+func (s *MySQLIdempotencyStore) Complete(ctx context.Context, key, requestHash string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status = ?, status_code = ?, content_type = ?, response_body = ?, locked_until = NULL
+		WHERE idempotency_key = ? AND status = ? AND request_hash = ?
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		string(idempotency.RecordStatusCompleted), statusCode, contentType, body,
+		key, string(idempotency.RecordStatusProcessing), requestHash,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao armazenar resposta da chave de idempotência: %w", err)
+	}
+
+	return nil
+}
+
+// Release remove a reserva de uma chave, se ela ainda estiver reservada pela requisição
+func (s *MySQLIdempotencyStore) Release(ctx context.Context, key, requestHash string) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE idempotency_key = ? AND status = ? AND request_hash = ?
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, key, string(idempotency.RecordStatusProcessing), requestHash); err != nil {
+		return fmt.Errorf("erro ao liberar chave de idempotência: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired remove as chaves expiradas
+func (s *MySQLIdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < ?`, now)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao remover chaves de idempotência expiradas: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// get busca o registro de uma chave; retorna nil sem erro se ela não existir mais
+func (s *MySQLIdempotencyStore) get(ctx context.Context, key string) (*idempotency.Record, error) {
+	query := `
+		SELECT idempotency_key, request_hash, status, COALESCE(status_code, 0), COALESCE(content_type, ''),
+			response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE idempotency_key = ?
+	`
+
+	record := &idempotency.Record{}
+	var status string
+
+	err := s.db.QueryRowContext(ctx, query, key).Scan(
+		&record.Key,
+		&record.RequestHash,
+		&status,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chave de idempotência: %w", err)
+	}
+
+	record.Status = idempotency.RecordStatus(status)
+	return record, nil
+}
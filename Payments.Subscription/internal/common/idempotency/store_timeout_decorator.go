@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// StoreTimeoutDecorator limita o tempo das operações do Store no banco. Todas as operações gravam
+// ou removem chaves, então usam o timeout de escrita
+type StoreTimeoutDecorator struct {
+	store        Store
+	writeTimeout time.Duration
+}
+
+// NewStoreTimeoutDecorator cria uma nova instância do decorator de timeout
+func NewStoreTimeoutDecorator(store Store, writeTimeout time.Duration) Store {
+	return &StoreTimeoutDecorator{
+		store:        store,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Reserve aplica o timeout de escrita à reserva da chave
+func (d *StoreTimeoutDecorator) Reserve(ctx context.Context, key, requestHash string, ttl, processingLease time.Duration) (*Record, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.store.Reserve(ctx, key, requestHash, ttl, processingLease)
+}
+
+// Complete aplica o timeout de escrita à gravação da resposta
+func (d *StoreTimeoutDecorator) Complete(ctx context.Context, key, requestHash string, statusCode int, contentType string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.store.Complete(ctx, key, requestHash, statusCode, contentType, body)
+}
+
+// Release aplica o timeout de escrita à remoção da reserva
+func (d *StoreTimeoutDecorator) Release(ctx context.Context, key, requestHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.store.Release(ctx, key, requestHash)
+}
+
+// DeleteExpired aplica o timeout de escrita à limpeza das chaves expiradas
+func (d *StoreTimeoutDecorator) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.store.DeleteExpired(ctx, now)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"payments-subscription/internal/common/problem"
+)
+
+// errorCodeUnauthorized é o código da resposta problem+json para credencial ausente ou inválida
+const errorCodeUnauthorized = "unauthorized"
+
+// BearerTokenMiddleware exige o header Authorization: Bearer <token> com o segredo compartilhado.
+// Sem token configurado todas as requisições são recusadas, para que a rota não fique aberta por omissão
+func BearerTokenMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="subscription-service"`)
+				problem.Write(w, r, problem.New(http.StatusUnauthorized, errorCodeUnauthorized, "Missing or invalid credentials"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"payments-subscription/internal/common/logging"
+)
+
+// ContentType é o media type das respostas de erro (RFC 7807)
+const ContentType = "application/problem+json"
+
+// typeBaseURI é o prefixo da referência que identifica cada tipo de problema
+const typeBaseURI = "/problems/"
+
+// Problem representa uma resposta de erro no formato Problem Details (RFC 7807)
+type Problem struct {
+	Type          string       `json:"type"`
+	Title         string       `json:"title"`
+	Status        int          `json:"status"`
+	Detail        string       `json:"detail,omitempty"`
+	Instance      string       `json:"instance,omitempty"`
+	Code          string       `json:"code"`
+	CorrelationID string       `json:"correlation_id,omitempty"`
+	Errors        []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError descreve um campo inválido da requisição
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// New cria um Problem para o status e código informados; o título é derivado do status HTTP
+func New(statusCode int, code, detail string) *Problem {
+	return &Problem{
+		Type:   typeBaseURI + code,
+		Title:  http.StatusText(statusCode),
+		Status: statusCode,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithErrors anexa os campos inválidos ao Problem
+func (p *Problem) WithErrors(errors []FieldError) *Problem {
+	p.Errors = errors
+	return p
+}
+
+// Write escreve o Problem na resposta, preenchendo instance e correlation ID a partir da requisição
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	correlationID := logging.GetCorrelationID(r.Context())
+
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	p.CorrelationID = correlationID
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Correlation-ID", correlationID)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
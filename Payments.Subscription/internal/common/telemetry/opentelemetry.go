@@ -2,12 +2,15 @@ package opentel
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
@@ -18,6 +21,7 @@ type OpenTel struct {
 	ServiceName    string
 	ServiceVersion string
 	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
 	propagator     propagation.TextMapPropagator
 }
 
@@ -51,17 +55,10 @@ func (ot *OpenTel) GetTracer() trace.Tracer {
 		log.Fatal(err)
 	}
 
-	// Configure the resource
-	res := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		semconv.ServiceNameKey.String(ot.ServiceName),
-		semconv.ServiceVersionKey.String(ot.ServiceVersion),
-	)
-
 	// Configure the tracer provider
 	ot.tracerProvider = sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(res),
+		sdktrace.WithResource(ot.resource()),
 	)
 
 	// Set the global tracer provider
@@ -70,3 +67,52 @@ func (ot *OpenTel) GetTracer() trace.Tracer {
 
 	return ot.tracerProvider.Tracer(ot.ServiceName)
 }
+
+func (ot *OpenTel) GetMeterProvider() *sdkmetric.MeterProvider {
+	return ot.meterProvider
+}
+
+// SetupMetrics configura o exporter OTLP de métricas e registra o MeterProvider global,
+// usado pelos instrumentos criados com otel.Meter (ex: circuit breaker do serviço de Customer)
+func (ot *OpenTel) SetupMetrics() *sdkmetric.MeterProvider {
+	ctx := context.Background()
+
+	// Configure the OTLP exporter using environment variables
+	exporter, err := otlpmetrichttp.New(ctx,
+		otlpmetrichttp.WithInsecure(),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ot.meterProvider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(ot.resource()),
+	)
+
+	// Set the global meter provider
+	otel.SetMeterProvider(ot.meterProvider)
+
+	return ot.meterProvider
+}
+
+// Shutdown exporta o que ainda está em buffer e encerra os providers configurados
+func (ot *OpenTel) Shutdown(ctx context.Context) error {
+	var errs []error
+	if ot.meterProvider != nil {
+		errs = append(errs, ot.meterProvider.Shutdown(ctx))
+	}
+	if ot.tracerProvider != nil {
+		errs = append(errs, ot.tracerProvider.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// resource descreve o serviço nos traces e métricas exportados
+func (ot *OpenTel) resource() *resource.Resource {
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(ot.ServiceName),
+		semconv.ServiceVersionKey.String(ot.ServiceVersion),
+	)
+}
@@ -0,0 +1,317 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrCircuitOpen indica que a chamada foi recusada sem tentar o serviço de Customer
+var ErrCircuitOpen = errors.New("circuit breaker do serviço de customer está aberto")
+
+// CircuitState representa o estado do circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitOpenError é retornado enquanto o circuito está aberto, informando quando tentar novamente
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: tente novamente em %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitBreakerSettings define quando o circuito abre e por quanto tempo permanece aberto
+type CircuitBreakerSettings struct {
+	// FailureRatio é a proporção de falhas na janela que abre o circuito
+	FailureRatio float64
+	// MinRequests é o mínimo de chamadas na janela antes de avaliar a proporção de falhas
+	MinRequests int
+	// Window é a duração da janela de contagem enquanto o circuito está fechado
+	Window time.Duration
+	// Cooldown é o tempo em que o circuito fica aberto antes de permitir chamadas de teste
+	Cooldown time.Duration
+	// HalfOpenMaxRequests é o número de chamadas de teste simultâneas no estado half-open
+	HalfOpenMaxRequests int
+}
+
+// CustomerClientCircuitBreaker é um decorator que interrompe as chamadas ao serviço de Customer
+// enquanto ele falha, evitando acumular requisições presas em um serviço indisponível
+type CustomerClientCircuitBreaker struct {
+	client   Client
+	settings CircuitBreakerSettings
+	logger   *logging.StructuredLogger
+
+	transitions metric.Int64Counter
+	rejections  metric.Int64Counter
+
+	mu               sync.Mutex
+	state            CircuitState
+	windowStart      time.Time
+	requests         int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	// generation muda a cada transição, descartando resultados de chamadas liberadas em outro estado
+	generation uint64
+}
+
+// NewCustomerClientCircuitBreaker cria o decorator de circuit breaker em torno do client
+func NewCustomerClientCircuitBreaker(client Client, settings CircuitBreakerSettings) Client {
+	meter := otel.Meter("customer-client")
+	logger := logging.NewStructuredLogger("subscription-service")
+
+	// Em caso de erro o meter ainda devolve um instrumento utilizável (no-op), então o circuit
+	// breaker continua funcionando e a falha de registro apenas é logada
+	transitions, err := meter.Int64Counter("customer.circuit_breaker.transitions",
+		metric.WithDescription("Mudanças de estado do circuit breaker do serviço de Customer"))
+	logMetricRegistration(logger, "customer.circuit_breaker.transitions", err)
+
+	rejections, err := meter.Int64Counter("customer.circuit_breaker.rejections",
+		metric.WithDescription("Chamadas recusadas com o circuit breaker do serviço de Customer aberto"))
+	logMetricRegistration(logger, "customer.circuit_breaker.rejections", err)
+
+	breaker := &CustomerClientCircuitBreaker{
+		client:      client,
+		settings:    settings,
+		logger:      logger,
+		transitions: transitions,
+		rejections:  rejections,
+		state:       CircuitClosed,
+		windowStart: time.Now(),
+	}
+
+	// O estado atual é exposto como gauge: 0 fechado, 1 half-open, 2 aberto
+	_, err = meter.Int64ObservableGauge("customer.circuit_breaker.state",
+		metric.WithDescription("Estado atual do circuit breaker do serviço de Customer"),
+		metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+			observer.Observe(breaker.stateValue())
+			return nil
+		}))
+	logMetricRegistration(logger, "customer.circuit_breaker.state", err)
+
+	return breaker
+}
+
+// logMetricRegistration registra a falha ao criar um instrumento de métrica do circuit breaker
+func logMetricRegistration(logger *logging.StructuredLogger, name string, err error) {
+	if err == nil {
+		return
+	}
+
+	logger.Error(context.Background(), "CustomerCircuitBreaker", "Erro ao registrar métrica do circuit breaker", err, map[string]interface{}{
+		"metric": name,
+	})
+}
+
+// CreateCustomer executa a criação do customer protegida pelo circuit breaker
+func (b *CustomerClientCircuitBreaker) CreateCustomer(ctx context.Context, request CustomerRequest) (*CustomerResponse, error) {
+	return execute(ctx, b, func(ctx context.Context) (*CustomerResponse, error) {
+		return b.client.CreateCustomer(ctx, request)
+	})
+}
+
+// GetCustomerByID executa a busca por ID protegida pelo circuit breaker
+func (b *CustomerClientCircuitBreaker) GetCustomerByID(ctx context.Context, id string) (*CustomerResponse, error) {
+	return execute(ctx, b, func(ctx context.Context) (*CustomerResponse, error) {
+		return b.client.GetCustomerByID(ctx, id)
+	})
+}
+
+// FindCustomerByEmail executa a busca por email protegida pelo circuit breaker
+func (b *CustomerClientCircuitBreaker) FindCustomerByEmail(ctx context.Context, email string) (*CustomerResponse, error) {
+	return execute(ctx, b, func(ctx context.Context) (*CustomerResponse, error) {
+		return b.client.FindCustomerByEmail(ctx, email)
+	})
+}
+
+// DeleteCustomer executa a remoção do customer protegida pelo circuit breaker
+func (b *CustomerClientCircuitBreaker) DeleteCustomer(ctx context.Context, id string) error {
+	_, err := execute(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, b.client.DeleteCustomer(ctx, id)
+	})
+	return err
+}
+
+// execute passa a chamada pelo circuit breaker e registra o resultado
+func execute[T any](ctx context.Context, b *CustomerClientCircuitBreaker, call func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+
+	generation, err := b.allow(ctx)
+	if err != nil {
+		return zero, err
+	}
+
+	callerDone := ctx.Err() != nil
+	result, err := call(ctx)
+	b.record(ctx, generation, classifyBreakerOutcome(err, callerDone))
+
+	return result, err
+}
+
+// allow decide se a chamada pode seguir para o serviço de Customer
+func (b *CustomerClientCircuitBreaker) allow(ctx context.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	if b.state == CircuitOpen {
+		remaining := b.settings.Cooldown - now.Sub(b.openedAt)
+		if remaining > 0 {
+			return 0, b.reject(ctx, remaining)
+		}
+		b.transition(ctx, CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.halfOpenInFlight >= max(b.settings.HalfOpenMaxRequests, 1) {
+			return 0, b.reject(ctx, b.settings.Cooldown)
+		}
+		b.halfOpenInFlight++
+		return b.generation, nil
+	}
+
+	// Circuito fechado: reinicia a contagem quando a janela expira
+	if now.Sub(b.windowStart) >= b.settings.Window {
+		b.windowStart = now
+		b.requests = 0
+		b.failures = 0
+	}
+
+	return b.generation, nil
+}
+
+// record contabiliza o resultado de uma chamada e move o circuito de estado quando necessário
+func (b *CustomerClientCircuitBreaker) record(ctx context.Context, generation uint64, outcome breakerOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case CircuitHalfOpen:
+		b.halfOpenInFlight--
+		switch outcome {
+		case breakerFailure:
+			b.transition(ctx, CircuitOpen)
+		case breakerSuccess:
+			if b.halfOpenInFlight == 0 {
+				b.transition(ctx, CircuitClosed)
+			}
+		}
+
+	case CircuitClosed:
+		if outcome == breakerIgnored {
+			return
+		}
+		b.requests++
+		if outcome == breakerFailure {
+			b.failures++
+		}
+		if b.requests >= b.settings.MinRequests &&
+			float64(b.failures)/float64(b.requests) >= b.settings.FailureRatio {
+			b.transition(ctx, CircuitOpen)
+		}
+	}
+}
+
+// reject recusa a chamada sem contatar o serviço de Customer
+func (b *CustomerClientCircuitBreaker) reject(ctx context.Context, retryAfter time.Duration) error {
+	b.rejections.Add(ctx, 1, metric.WithAttributes(attribute.String("state", string(b.state))))
+	trace.SpanFromContext(ctx).AddEvent("customer.circuit_breaker.rejected", trace.WithAttributes(
+		attribute.String("circuit_breaker.state", string(b.state)),
+		attribute.Int64("circuit_breaker.retry_after_ms", retryAfter.Milliseconds()),
+	))
+	return &CircuitOpenError{RetryAfter: retryAfter}
+}
+
+// transition muda o estado do circuito, registrando span event, métrica e log
+func (b *CustomerClientCircuitBreaker) transition(ctx context.Context, to CircuitState) {
+	from := b.state
+	if from == to {
+		return
+	}
+
+	b.state = to
+	b.generation++
+	b.requests = 0
+	b.failures = 0
+	b.windowStart = time.Now()
+	b.halfOpenInFlight = 0
+	if to == CircuitOpen {
+		b.openedAt = time.Now()
+	}
+
+	attributes := []attribute.KeyValue{
+		attribute.String("circuit_breaker.from", string(from)),
+		attribute.String("circuit_breaker.to", string(to)),
+	}
+	trace.SpanFromContext(ctx).AddEvent("customer.circuit_breaker.state_change", trace.WithAttributes(attributes...))
+	b.transitions.Add(ctx, 1, metric.WithAttributes(attributes...))
+
+	b.logger.Info(ctx, "CustomerCircuitBreaker", "Circuit breaker do serviço de Customer mudou de estado", map[string]interface{}{
+		"from": string(from),
+		"to":   string(to),
+	})
+}
+
+// stateValue converte o estado atual para o valor do gauge
+func (b *CustomerClientCircuitBreaker) stateValue() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitHalfOpen:
+		return 1
+	case CircuitOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// breakerOutcome é o resultado de uma chamada do ponto de vista do circuit breaker
+type breakerOutcome int
+
+const (
+	breakerSuccess breakerOutcome = iota
+	breakerFailure
+	// breakerIgnored é uma chamada interrompida pelo chamador, que não diz nada sobre a saúde do serviço
+	breakerIgnored
+)
+
+// classifyBreakerOutcome classifica o resultado da chamada. Só a indisponibilidade do serviço de Customer
+// conta como falha: erros de negócio (customer inexistente, dados rejeitados) contam como sucesso, e
+// chamadas canceladas pelo chamador, ou feitas com o contexto já encerrado, não são contabilizadas
+func classifyBreakerOutcome(err error, callerDone bool) breakerOutcome {
+	switch {
+	case callerDone || errors.Is(err, context.Canceled):
+		return breakerIgnored
+	case errors.Is(err, ErrCustomerServiceUnavailable) || errors.Is(err, ErrCustomerServiceFailure):
+		return breakerFailure
+	default:
+		return breakerSuccess
+	}
+}
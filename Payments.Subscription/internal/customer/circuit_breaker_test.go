@@ -0,0 +1,267 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeClient devolve o erro configurado e conta as chamadas que chegaram ao serviço
+type fakeClient struct {
+	err   error
+	calls int
+	// block, quando informado, segura a chamada até ser fechado
+	block chan struct{}
+}
+
+func (f *fakeClient) do() error {
+	f.calls++
+	if f.block != nil {
+		<-f.block
+	}
+	return f.err
+}
+
+func (f *fakeClient) CreateCustomer(context.Context, CustomerRequest) (*CustomerResponse, error) {
+	if err := f.do(); err != nil {
+		return nil, err
+	}
+	return &CustomerResponse{ID: "customer-1"}, nil
+}
+
+func (f *fakeClient) GetCustomerByID(_ context.Context, id string) (*CustomerResponse, error) {
+	if err := f.do(); err != nil {
+		return nil, err
+	}
+	return &CustomerResponse{ID: id}, nil
+}
+
+func (f *fakeClient) FindCustomerByEmail(_ context.Context, email string) (*CustomerResponse, error) {
+	if err := f.do(); err != nil {
+		return nil, err
+	}
+	return &CustomerResponse{ID: "customer-1", Email: email}, nil
+}
+
+func (f *fakeClient) DeleteCustomer(context.Context, string) error {
+	return f.do()
+}
+
+var (
+	errUnavailable = fmt.Errorf("%w: status 503", ErrCustomerServiceUnavailable)
+	// errCallerCanceled é o erro do client quando o chamador cancela durante a espera entre tentativas
+	errCallerCanceled = fmt.Errorf("erro ao fazer request: %w: %w", ErrCustomerServiceUnavailable, context.Canceled)
+)
+
+func newTestBreaker(client Client, cooldown time.Duration) *CustomerClientCircuitBreaker {
+	return NewCustomerClientCircuitBreaker(client, CircuitBreakerSettings{
+		FailureRatio:        0.5,
+		MinRequests:         4,
+		Window:              time.Minute,
+		Cooldown:            cooldown,
+		HalfOpenMaxRequests: 1,
+	}).(*CustomerClientCircuitBreaker)
+}
+
+func (b *CustomerClientCircuitBreaker) currentState() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// run executa as chamadas do cenário e devolve o estado esperado
+		run       func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState
+		wantCalls int
+	}{
+		{
+			name: "permanece fechado abaixo do mínimo de chamadas",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				client.err = errUnavailable
+				for i := 0; i < 3; i++ {
+					b.GetCustomerByID(ctx, "customer-1")
+				}
+				return CircuitClosed
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "abre ao atingir a proporção de falhas e recusa sem chamar o serviço",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				b.GetCustomerByID(ctx, "customer-1")
+				b.GetCustomerByID(ctx, "customer-1")
+				client.err = errUnavailable
+				b.GetCustomerByID(ctx, "customer-1")
+				b.GetCustomerByID(ctx, "customer-1")
+
+				_, err := b.GetCustomerByID(ctx, "customer-1")
+				var openErr *CircuitOpenError
+				if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("error = %v, want CircuitOpenError", err)
+				}
+				if openErr.RetryAfter <= 0 || openErr.RetryAfter > cooldown {
+					t.Errorf("RetryAfter = %s, want within (0, %s]", openErr.RetryAfter, cooldown)
+				}
+				return CircuitOpen
+			},
+			wantCalls: 4,
+		},
+		{
+			name: "erros de negócio não abrem o circuito",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				client.err = ErrCustomerNotFound
+				for i := 0; i < 6; i++ {
+					if _, err := b.GetCustomerByID(ctx, "customer-1"); !errors.Is(err, ErrCustomerNotFound) {
+						t.Fatalf("error = %v, want %v", err, ErrCustomerNotFound)
+					}
+				}
+				return CircuitClosed
+			},
+			wantCalls: 6,
+		},
+		{
+			name: "cancelamento pelo chamador não abre o circuito",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				client.err = errCallerCanceled
+				for i := 0; i < 6; i++ {
+					b.GetCustomerByID(ctx, "customer-1")
+				}
+				return CircuitClosed
+			},
+			wantCalls: 6,
+		},
+		{
+			name: "chamadas com o contexto já encerrado não são contabilizadas",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				expired, cancel := context.WithTimeout(ctx, -time.Second)
+				defer cancel()
+
+				client.err = errUnavailable
+				for i := 0; i < 6; i++ {
+					b.GetCustomerByID(expired, "customer-1")
+				}
+				return CircuitClosed
+			},
+			wantCalls: 6,
+		},
+		{
+			name: "chamada de teste cancelada mantém o circuito semiaberto",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				client.err = errUnavailable
+				for i := 0; i < 4; i++ {
+					b.GetCustomerByID(ctx, "customer-1")
+				}
+				time.Sleep(cooldown + 5*time.Millisecond)
+
+				client.err = errCallerCanceled
+				b.GetCustomerByID(ctx, "customer-1")
+				if got := b.currentState(); got != CircuitHalfOpen {
+					t.Fatalf("state after canceled trial = %s, want %s", got, CircuitHalfOpen)
+				}
+
+				// A vaga da chamada de teste foi liberada para a próxima
+				client.err = nil
+				if _, err := b.GetCustomerByID(ctx, "customer-1"); err != nil {
+					t.Fatalf("second half-open call error = %v", err)
+				}
+				return CircuitClosed
+			},
+			wantCalls: 6,
+		},
+		{
+			name: "após o cooldown a chamada de teste bem-sucedida fecha o circuito",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				client.err = errUnavailable
+				for i := 0; i < 4; i++ {
+					b.GetCustomerByID(ctx, "customer-1")
+				}
+				time.Sleep(cooldown + 5*time.Millisecond)
+
+				client.err = nil
+				if _, err := b.GetCustomerByID(ctx, "customer-1"); err != nil {
+					t.Fatalf("half-open call error = %v", err)
+				}
+				return CircuitClosed
+			},
+			wantCalls: 5,
+		},
+		{
+			name: "falha na chamada de teste reabre o circuito",
+			run: func(t *testing.T, b *CustomerClientCircuitBreaker, client *fakeClient) CircuitState {
+				client.err = errUnavailable
+				for i := 0; i < 4; i++ {
+					b.GetCustomerByID(ctx, "customer-1")
+				}
+				time.Sleep(cooldown + 5*time.Millisecond)
+
+				b.GetCustomerByID(ctx, "customer-1")
+				if _, err := b.GetCustomerByID(ctx, "customer-1"); !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("error = %v, want %v", err, ErrCircuitOpen)
+				}
+				return CircuitOpen
+			},
+			wantCalls: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{}
+			breaker := newTestBreaker(client, cooldown)
+
+			wantState := tt.run(t, breaker, client)
+
+			if got := breaker.currentState(); got != wantState {
+				t.Errorf("state = %s, want %s", got, wantState)
+			}
+			if client.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", client.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpenLimitsTrialCalls(t *testing.T) {
+	const cooldown = 10 * time.Millisecond
+	ctx := context.Background()
+
+	client := &fakeClient{err: errUnavailable}
+	breaker := newTestBreaker(client, cooldown)
+	for i := 0; i < 4; i++ {
+		breaker.DeleteCustomer(ctx, "customer-1")
+	}
+	time.Sleep(cooldown + 5*time.Millisecond)
+
+	// A primeira chamada de teste fica presa no serviço; a segunda deve ser recusada
+	client.err = nil
+	client.block = make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := breaker.CreateCustomer(ctx, CustomerRequest{Name: "Ana", Email: "ana@example.com"})
+		done <- err
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for breaker.currentState() != CircuitHalfOpen && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := breaker.FindCustomerByEmail(ctx, "ana@example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("concurrent half-open call error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	close(client.block)
+	if err := <-done; err != nil {
+		t.Fatalf("trial call error = %v", err)
+	}
+	if got := breaker.currentState(); got != CircuitClosed {
+		t.Errorf("state = %s, want %s", got, CircuitClosed)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"payments-subscription/internal/common/logging"
@@ -16,12 +20,30 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// Erros do serviço de Customer, usados para classificar falhas upstream
+var (
+	ErrCustomerServiceUnavailable = errors.New("serviço de customer indisponível")
+	ErrCustomerServiceFailure     = errors.New("serviço de customer retornou erro")
+	ErrCustomerRejected           = errors.New("serviço de customer rejeitou a requisição")
+	ErrCustomerNotFound           = errors.New("customer não encontrado")
+)
+
+// Client define as operações disponíveis no serviço de Customer
+type Client interface {
+	CreateCustomer(ctx context.Context, request CustomerRequest) (*CustomerResponse, error)
+	GetCustomerByID(ctx context.Context, id string) (*CustomerResponse, error)
+	FindCustomerByEmail(ctx context.Context, email string) (*CustomerResponse, error)
+	DeleteCustomer(ctx context.Context, id string) error
+}
+
 type CustomerClient struct {
-	baseURL    string
-	httpClient *http.Client
-	propagator propagation.TextMapPropagator
-	tracer     trace.Tracer
-	logger     *logging.StructuredLogger
+	baseURL     string
+	httpClient  *http.Client
+	timeout     time.Duration
+	retryPolicy RetryPolicy
+	propagator  propagation.TextMapPropagator
+	tracer      trace.Tracer
+	logger      *logging.StructuredLogger
 }
 
 type CustomerRequest struct {
@@ -35,13 +57,17 @@ type CustomerResponse struct {
 	Email string `json:"email"`
 }
 
-func NewCustomerClient(baseURL string) *CustomerClient {
+// NewCustomerClient cria o client; timeout é o deadline de cada operação, somando todas as tentativas,
+// as esperas entre elas e a leitura da resposta
+func NewCustomerClient(baseURL string, timeout time.Duration, retryPolicy RetryPolicy) *CustomerClient {
 	return &CustomerClient{
-		baseURL:    baseURL,
-		httpClient: &http.Client{},
-		propagator: otel.GetTextMapPropagator(),
-		tracer:     otel.GetTracerProvider().Tracer("customer-client"),
-		logger:     logging.NewStructuredLogger("subscription-service"),
+		baseURL:     baseURL,
+		httpClient:  &http.Client{},
+		timeout:     timeout,
+		retryPolicy: retryPolicy,
+		propagator:  otel.GetTextMapPropagator(),
+		tracer:      otel.GetTracerProvider().Tracer("customer-client"),
+		logger:      logging.NewStructuredLogger("subscription-service"),
 	}
 }
 
@@ -68,8 +94,6 @@ func (c *CustomerClient) CreateCustomer(ctx context.Context, request CustomerReq
 		attribute.String("correlation.id", correlationID),
 	)
 
-	url := c.baseURL
-
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		span.RecordError(err)
@@ -77,21 +101,239 @@ func (c *CustomerClient) CreateCustomer(ctx context.Context, request CustomerReq
 		return nil, fmt.Errorf("erro ao serializar request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	// O serviço de Customer não deduplica criações, então o POST é feito uma única vez: repeti-lo após
+	// um timeout ou 5xx em que a primeira tentativa foi gravada criaria um customer duplicado
+	var customerResponse CustomerResponse
+	if _, err := c.send(ctx, span, operation, http.MethodPost, c.baseURL, jsonData, &customerResponse); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("customer.id", customerResponse.ID),
+	)
+
+	// Log fim apenas se demorou muito
+	c.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"customer_id": customerResponse.ID,
+	})
+
+	return &customerResponse, nil
+}
+
+// GetCustomerByID busca um customer pelo ID; retorna ErrCustomerNotFound se ele não existir
+func (c *CustomerClient) GetCustomerByID(ctx context.Context, id string) (*CustomerResponse, error) {
+	startTime := time.Now()
+	operation := "CustomerClient.GetCustomerByID"
+
+	// Garantir que existe correlation ID
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+	correlationID := logging.GetCorrelationID(ctx)
+
+	c.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"customer_id": id,
+	})
+
+	ctx, span := c.tracer.Start(ctx, operation)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("customer.id", id),
+		attribute.String("correlation.id", correlationID),
+	)
+
+	requestURL := c.baseURL + "/" + url.PathEscape(id)
+
+	var customerResponse CustomerResponse
+	statusCode, err := c.send(ctx, span, operation, http.MethodGet, requestURL, nil, &customerResponse)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, id)
+	}
 	if err != nil {
-		span.RecordError(err)
-		c.logger.Error(ctx, operation, "Failed to create HTTP request", err, nil)
-		return nil, fmt.Errorf("erro ao criar request: %w", err)
+		return nil, err
+	}
+
+	c.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"customer_id": customerResponse.ID,
+	})
+
+	return &customerResponse, nil
+}
+
+// FindCustomerByEmail busca um customer pelo email; retorna ErrCustomerNotFound se nenhum customer usar o email
+func (c *CustomerClient) FindCustomerByEmail(ctx context.Context, email string) (*CustomerResponse, error) {
+	startTime := time.Now()
+	operation := "CustomerClient.FindCustomerByEmail"
+
+	// Garantir que existe correlation ID
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+	correlationID := logging.GetCorrelationID(ctx)
+
+	c.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"customer_email": email,
+	})
+
+	ctx, span := c.tracer.Start(ctx, operation)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("customer.email", email),
+		attribute.String("correlation.id", correlationID),
+	)
+
+	requestURL := c.baseURL + "?" + url.Values{"email": {email}}.Encode()
+
+	var customers []CustomerResponse
+	statusCode, err := c.send(ctx, span, operation, http.MethodGet, requestURL, nil, &customers)
+	if statusCode == http.StatusNotFound {
+		return nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Correlation-ID", correlationID)
+	// O filtro é conferido aqui também, já que a comparação de email não diferencia maiúsculas
+	for _, candidate := range customers {
+		if strings.EqualFold(candidate.Email, email) {
+			span.SetAttributes(attribute.String("customer.id", candidate.ID))
+			c.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+				"customer_id": candidate.ID,
+			})
+			return &candidate, nil
+		}
+	}
+
+	return nil, ErrCustomerNotFound
+}
+
+// DeleteCustomer remove um customer, usado para compensar uma criação que não pôde ser concluída.
+// Um customer já inexistente é tratado como sucesso, tornando a compensação idempotente
+func (c *CustomerClient) DeleteCustomer(ctx context.Context, id string) error {
+	startTime := time.Now()
+	operation := "CustomerClient.DeleteCustomer"
+
+	// Garantir que existe correlation ID
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+	correlationID := logging.GetCorrelationID(ctx)
+
+	c.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"customer_id": id,
+	})
+
+	ctx, span := c.tracer.Start(ctx, operation)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("customer.id", id),
+		attribute.String("correlation.id", correlationID),
+	)
+
+	requestURL := c.baseURL + "/" + url.PathEscape(id)
+
+	statusCode, err := c.send(ctx, span, operation, http.MethodDelete, requestURL, nil, nil)
+	if err != nil && statusCode != http.StatusNotFound {
+		return err
+	}
+
+	c.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"customer_id": id,
+		"status_code": statusCode,
+	})
+
+	return nil
+}
+
+// send executa a chamada HTTP ao serviço de Customer, propagando correlation ID e contexto de tracing,
+// e decodifica a resposta de sucesso em out (quando informado). Falhas transitórias são repetidas conforme
+// a RetryPolicy apenas em chamadas idempotentes (GET, DELETE). O status HTTP é retornado mesmo em
+// caso de erro
+func (c *CustomerClient) send(ctx context.Context, span trace.Span, operation, method, requestURL string, body []byte, out interface{}) (int, error) {
+	// O deadline vale para a operação inteira e é propagado às tentativas; um deadline anterior do
+	// chamador (ex: orçamento da requisição) continua prevalecendo se for menor
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	retryable := method == http.MethodGet || method == http.MethodDelete
+
+	maxAttempts := 1
+	if retryable {
+		maxAttempts = max(c.retryPolicy.MaxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, method, requestURL, body)
+
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+
+		eventAttributes := []attribute.KeyValue{
+			attribute.Int("http.attempt", attempt),
+			attribute.Int("http.status_code", statusCode),
+		}
+		if err != nil {
+			eventAttributes = append(eventAttributes, attribute.String("error", err.Error()))
+		}
+		span.AddEvent("customer.request.attempt", trace.WithAttributes(eventAttributes...))
+
+		transient := err != nil || c.retryPolicy.isRetryableStatus(statusCode)
+		if transient && attempt < maxAttempts && ctx.Err() == nil {
+			wait := c.retryPolicy.delay(attempt, resp)
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			c.logger.Info(ctx, operation, "Falha transitória no serviço de Customer, repetindo chamada", map[string]interface{}{
+				"attempt":      attempt,
+				"max_attempts": maxAttempts,
+				"status_code":  statusCode,
+				"retry_in_ms":  wait.Milliseconds(),
+			})
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				span.RecordError(ctx.Err())
+				return statusCode, fmt.Errorf("erro ao fazer request: %w: %w", ErrCustomerServiceUnavailable, ctx.Err())
+			case <-timer.C:
+			}
+			continue
+		}
+
+		span.SetAttributes(attribute.Int("http.attempts", attempt))
+		return c.handleResponse(ctx, span, operation, resp, err, attempt, out)
+	}
+}
+
+// attempt executa uma única tentativa da chamada HTTP
+func (c *CustomerClient) attempt(ctx context.Context, method, requestURL string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Correlation-ID", logging.GetCorrelationID(ctx))
 
 	// Propagar contexto de tracing via headers W3C
 	c.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
 
-	resp, err := c.httpClient.Do(req)
+	return c.httpClient.Do(req)
+}
 
+// handleResponse trata o resultado da última tentativa, classificando falhas e decodificando o sucesso
+func (c *CustomerClient) handleResponse(ctx context.Context, span trace.Span, operation string, resp *http.Response, err error, attempts int, out interface{}) (int, error) {
 	statusCode := 0
 	if resp != nil {
 		statusCode = resp.StatusCode
@@ -99,34 +341,80 @@ func (c *CustomerClient) CreateCustomer(ctx context.Context, request CustomerReq
 	}
 
 	if err != nil {
+		timeout := errors.Is(err, context.DeadlineExceeded)
 		c.logger.LogServiceCall(ctx, "Customer", statusCode, err)
+		c.logger.Error(ctx, operation, "Chamada ao serviço de Customer falhou", err, map[string]interface{}{
+			"attempts": attempts,
+			"timeout":  timeout,
+		})
+		span.SetAttributes(attribute.Bool("timeout", timeout))
 		span.RecordError(err)
-		return nil, fmt.Errorf("erro ao fazer request: %w", err)
+		return statusCode, fmt.Errorf("erro ao fazer request: %w: %w", ErrCustomerServiceUnavailable, err)
 	}
 
+	span.SetAttributes(attribute.Int("http.status_code", statusCode))
+
 	// Log para status codes de erro
-	if statusCode != http.StatusCreated && statusCode != http.StatusOK {
-		err := fmt.Errorf("customer service returned status code %d", statusCode)
+	if statusCode != http.StatusCreated && statusCode != http.StatusOK && statusCode != http.StatusNoContent {
+		err := fmt.Errorf("%w: customer service returned status code %d", statusError(statusCode), statusCode)
 		c.logger.LogServiceCall(ctx, "Customer", statusCode, nil)
+		if attempts > 1 {
+			c.logger.Error(ctx, operation, "Serviço de Customer falhou após novas tentativas", err, map[string]interface{}{
+				"attempts":    attempts,
+				"status_code": statusCode,
+			})
+		}
 		span.RecordError(err)
-		return nil, err
+		return statusCode, err
 	}
 
-	var customerResponse CustomerResponse
-	if err := json.NewDecoder(resp.Body).Decode(&customerResponse); err != nil {
+	if attempts > 1 {
+		c.logger.Info(ctx, operation, "Chamada ao serviço de Customer concluída após novas tentativas", map[string]interface{}{
+			"attempts": attempts,
+		})
+	}
+
+	if out == nil {
+		return statusCode, nil
+	}
+
+	if err := decodeResponse(resp.Body, out); err != nil {
 		span.RecordError(err)
 		c.logger.Error(ctx, operation, "Failed to decode response", err, nil)
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
+		return statusCode, fmt.Errorf("erro ao decodificar resposta: %w", err)
 	}
 
-	span.SetAttributes(
-		attribute.String("customer.id", customerResponse.ID),
-	)
+	return statusCode, nil
+}
 
-	// Log fim apenas se demorou muito
-	c.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
-		"customer_id": customerResponse.ID,
-	})
+// decodeResponse decodifica a resposta do serviço de Customer, que pode vir dentro do envelope "data"
+func decodeResponse(body io.Reader, out interface{}) error {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
 
-	return &customerResponse, nil
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err == nil && len(envelope.Data) > 0 {
+		raw = envelope.Data
+	}
+
+	return json.Unmarshal(raw, out)
+}
+
+// statusError classifica o status HTTP de erro retornado pelo serviço de Customer
+func statusError(statusCode int) error {
+	switch {
+	case statusCode == http.StatusServiceUnavailable,
+		statusCode == http.StatusBadGateway,
+		statusCode == http.StatusGatewayTimeout,
+		statusCode == http.StatusTooManyRequests:
+		return ErrCustomerServiceUnavailable
+	case statusCode >= 400 && statusCode < 500:
+		return ErrCustomerRejected
+	default:
+		return ErrCustomerServiceFailure
+	}
 }
@@ -0,0 +1,70 @@
+package customer
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy define como as chamadas ao serviço de Customer são repetidas em falhas transitórias
+type RetryPolicy struct {
+	// MaxAttempts é o total de tentativas, incluindo a primeira
+	MaxAttempts int
+	// BaseDelay é a espera antes da segunda tentativa, dobrada a cada nova tentativa
+	BaseDelay time.Duration
+	// MaxDelay limita a espera entre tentativas, inclusive a pedida via Retry-After
+	MaxDelay time.Duration
+	// RetryableStatusCodes lista os status HTTP que indicam falha transitória
+	RetryableStatusCodes []int
+}
+
+// isRetryableStatus indica se o status HTTP deve gerar uma nova tentativa
+func (p RetryPolicy) isRetryableStatus(statusCode int) bool {
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// delay calcula a espera antes da próxima tentativa usando backoff exponencial com full jitter.
+// Se o serviço informou Retry-After, ele é respeitado, limitado a MaxDelay
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp); ok {
+		return min(wait, p.MaxDelay)
+	}
+
+	backoff := p.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		backoff = min(p.BaseDelay<<shift, p.MaxDelay)
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// retryAfter interpreta o header Retry-After, em segundos ou como data HTTP
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,199 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/common/problem"
+
+	"github.com/gorilla/mux"
+)
+
+// Códigos de erro devolvidos nas respostas problem+json do catálogo de planos
+const (
+	ErrorCodeInvalidRequest   = "invalid_request"
+	ErrorCodeValidationFailed = "validation_failed"
+	ErrorCodePlanNotFound     = "plan_not_found"
+	ErrorCodePlanArchived     = "plan_archived"
+	ErrorCodeTimeout          = "timeout"
+	ErrorCodeInternal         = "internal_error"
+)
+
+// SuccessResponse representa uma resposta de sucesso padronizada
+type SuccessResponse struct {
+	Data          interface{} `json:"data"`
+	Message       string      `json:"message,omitempty"`
+	CorrelationID string      `json:"correlation_id"`
+}
+
+// handler gerencia as requisições HTTP do catálogo de planos
+type handler struct {
+	service PlanServiceInterface
+}
+
+// NewPlanHandler cria uma nova instância do PlanHandler
+func NewPlanHandler(service PlanServiceInterface) *handler {
+	return &handler{
+		service: service,
+	}
+}
+
+// writeServiceError escreve a resposta problem+json de uma chamada ao serviço, classificando o erro
+func (h *handler) writeServiceError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	statusCode, code, detail := classifyError(err)
+	problem.Write(w, r, problem.New(statusCode, code, fmt.Sprintf("%s: %s", message, detail)))
+}
+
+// writeInvalidJSON responde a um corpo de requisição que não pôde ser decodificado
+func (h *handler) writeInvalidJSON(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, ErrorCodeInvalidRequest, "Invalid JSON format"))
+}
+
+// writeMissingID responde a uma requisição sem o ID do plano na rota
+func (h *handler) writeMissingID(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, ErrorCodeValidationFailed, "Plan ID is required"))
+}
+
+// writeSuccessResponse escreve uma resposta de sucesso padronizada
+func (h *handler) writeSuccessResponse(w http.ResponseWriter, r *http.Request, data interface{}, statusCode int, message string) {
+	correlationID := logging.GetCorrelationID(r.Context())
+
+	successResponse := SuccessResponse{
+		Data:          data,
+		Message:       message,
+		CorrelationID: correlationID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Correlation-ID", correlationID)
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(successResponse)
+}
+
+// classifyError traduz os erros do domínio de planos em status HTTP, código e descrição pública
+func classifyError(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrorCodeTimeout, "the operation did not complete in time"
+	case errors.Is(err, ErrPlanNotFound):
+		return http.StatusNotFound, ErrorCodePlanNotFound, "the plan was not found"
+	case errors.Is(err, ErrPlanArchived), errors.Is(err, ErrPlanAlreadyArchived):
+		return http.StatusConflict, ErrorCodePlanArchived, "the plan is archived"
+	case errors.Is(err, ErrInvalidPlanName):
+		return http.StatusBadRequest, ErrorCodeValidationFailed, "the plan name is invalid"
+	case errors.Is(err, ErrInvalidPrice):
+		return http.StatusBadRequest, ErrorCodeValidationFailed, "the plan price is invalid"
+	case errors.Is(err, ErrInvalidCurrency):
+		return http.StatusBadRequest, ErrorCodeValidationFailed, "the plan currency is invalid"
+	case errors.Is(err, ErrInvalidInterval):
+		return http.StatusBadRequest, ErrorCodeValidationFailed, "the plan billing interval is invalid"
+	case errors.Is(err, ErrInvalidTrialDays):
+		return http.StatusBadRequest, ErrorCodeValidationFailed, "the plan trial days are invalid"
+	default:
+		return http.StatusInternalServerError, ErrorCodeInternal, "an unexpected error occurred"
+	}
+}
+
+// CreatePlan handler para criar um plano
+func (h *handler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeInvalidJSON(w, r)
+		return
+	}
+
+	plan, err := h.service.CreatePlan(r.Context(), req)
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to create plan")
+		return
+	}
+
+	h.writeSuccessResponse(w, r, plan, http.StatusCreated, "Plan created successfully")
+}
+
+// GetPlanByID handler para buscar um plano pelo ID
+func (h *handler) GetPlanByID(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if id == "" {
+		h.writeMissingID(w, r)
+		return
+	}
+
+	plan, err := h.service.GetPlanByID(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to retrieve plan")
+		return
+	}
+
+	h.writeSuccessResponse(w, r, plan, http.StatusOK, "")
+}
+
+// GetAllPlans handler para listar os planos do catálogo
+func (h *handler) GetAllPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.service.GetAllPlans(r.Context())
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to retrieve plans")
+		return
+	}
+
+	h.writeSuccessResponse(w, r, plans, http.StatusOK, "")
+}
+
+// UpdatePlan handler para atualizar um plano
+func (h *handler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if id == "" {
+		h.writeMissingID(w, r)
+		return
+	}
+
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeInvalidJSON(w, r)
+		return
+	}
+
+	plan, err := h.service.UpdatePlan(r.Context(), id, req)
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to update plan")
+		return
+	}
+
+	h.writeSuccessResponse(w, r, plan, http.StatusOK, "Plan updated successfully")
+}
+
+// ArchivePlan handler para arquivar um plano
+func (h *handler) ArchivePlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if id == "" {
+		h.writeMissingID(w, r)
+		return
+	}
+
+	if err := h.service.ArchivePlan(r.Context(), id); err != nil {
+		h.writeServiceError(w, r, err, "Failed to archive plan")
+		return
+	}
+
+	result := map[string]string{
+		"message": "Plan archived successfully",
+		"id":      id,
+	}
+	h.writeSuccessResponse(w, r, result, http.StatusOK, "Plan archived successfully")
+}
+
+// RegisterRoutes registra as rotas do catálogo de planos
+func (h *handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/plans", h.CreatePlan).Methods("POST")
+	router.HandleFunc("/plans", h.GetAllPlans).Methods("GET")
+	router.HandleFunc("/plans/{id}", h.GetPlanByID).Methods("GET")
+	router.HandleFunc("/plans/{id}", h.UpdatePlan).Methods("PUT")
+	router.HandleFunc("/plans/{id}", h.ArchivePlan).Methods("DELETE")
+}
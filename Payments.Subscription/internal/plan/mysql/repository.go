@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"payments-subscription/internal/plan"
+)
+
+// planColumns lista as colunas lidas por scanPlan, na mesma ordem
+const planColumns = `id, name, description, price_amount, currency, billing_interval, billing_interval_days,
+		trial_days, features, status, created_at, updated_at`
+
+// rowScanner abstrai *sql.Row e *sql.Rows para o scan de um plano
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// MySQLPlanRepository implementa o PlanRepository usando MySQL
+type MySQLPlanRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPlanRepository cria uma nova instância do repositório MySQL de planos
+func NewMySQLPlanRepository(db *sql.DB) *MySQLPlanRepository {
+	return &MySQLPlanRepository{
+		db: db,
+	}
+}
+
+// Create cria um novo plano no banco de dados
+func (r *MySQLPlanRepository) Create(ctx context.Context, p *plan.Plan) error {
+	features, err := json.Marshal(p.Features())
+	if err != nil {
+		return fmt.Errorf("erro ao serializar features do plano: %w", err)
+	}
+
+	query := `
+		INSERT INTO plans (id, name, description, price_amount, currency, billing_interval, billing_interval_days,
+			trial_days, features, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		p.ID(),
+		p.Name(),
+		p.Description(),
+		p.PriceAmount(),
+		p.Currency(),
+		string(p.Interval()),
+		nullableInt(p.IntervalDays()),
+		p.TrialDays(),
+		features,
+		string(p.Status()),
+		p.CreatedAt(),
+		p.UpdatedAt(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("erro ao inserir plano no banco: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID busca um plano pelo ID no banco de dados
+func (r *MySQLPlanRepository) GetByID(ctx context.Context, id string) (*plan.Plan, error) {
+	query := `
+		SELECT ` + planColumns + `
+		FROM plans
+		WHERE id = ?
+	`
+
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	planEntity, err := scanPlan(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, plan.ErrPlanNotFound
+		}
+		return nil, err
+	}
+
+	return planEntity, nil
+}
+
+// GetAll busca todos os planos no banco de dados
+func (r *MySQLPlanRepository) GetAll(ctx context.Context) ([]*plan.Plan, error) {
+	query := `
+		SELECT ` + planColumns + `
+		FROM plans
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar planos no banco: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []*plan.Plan
+
+	for rows.Next() {
+		planEntity, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		plans = append(plans, planEntity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os planos: %w", err)
+	}
+
+	return plans, nil
+}
+
+// Update atualiza um plano existente no banco de dados
+func (r *MySQLPlanRepository) Update(ctx context.Context, p *plan.Plan) error {
+	features, err := json.Marshal(p.Features())
+	if err != nil {
+		return fmt.Errorf("erro ao serializar features do plano: %w", err)
+	}
+
+	query := `
+		UPDATE plans
+		SET name = ?, description = ?, price_amount = ?, currency = ?, billing_interval = ?,
+			billing_interval_days = ?, trial_days = ?, features = ?, status = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		p.Name(),
+		p.Description(),
+		p.PriceAmount(),
+		p.Currency(),
+		string(p.Interval()),
+		nullableInt(p.IntervalDays()),
+		p.TrialDays(),
+		features,
+		string(p.Status()),
+		p.UpdatedAt(),
+		p.ID(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar plano no banco: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return plan.ErrPlanNotFound
+	}
+
+	return nil
+}
+
+// scanPlan lê uma linha com planColumns e reconstrói o agregado
+func scanPlan(row rowScanner) (*plan.Plan, error) {
+	var snapshot plan.PlanSnapshot
+	var interval, status string
+	var intervalDays sql.NullInt64
+	var features []byte
+
+	err := row.Scan(
+		&snapshot.ID,
+		&snapshot.Name,
+		&snapshot.Description,
+		&snapshot.PriceAmount,
+		&snapshot.Currency,
+		&interval,
+		&intervalDays,
+		&snapshot.TrialDays,
+		&features,
+		&status,
+		&snapshot.CreatedAt,
+		&snapshot.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao fazer scan do plano: %w", err)
+	}
+
+	if err := json.Unmarshal(features, &snapshot.Features); err != nil {
+		return nil, fmt.Errorf("erro ao desserializar features do plano: %w", err)
+	}
+
+	snapshot.Interval = plan.Interval(interval)
+	snapshot.IntervalDays = int(intervalDays.Int64)
+	snapshot.Status = plan.PlanStatus(status)
+
+	return plan.ReconstructPlan(snapshot), nil
+}
+
+// nullableInt converte zero em NULL
+func nullableInt(v int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: v != 0}
+}
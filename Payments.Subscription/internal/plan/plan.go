@@ -0,0 +1,270 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan representa o agregado de plano do catálogo
+type Plan struct {
+	id           string
+	name         string
+	description  string
+	priceAmount  int64
+	currency     string
+	interval     Interval
+	intervalDays int
+	trialDays    int
+	features     []string
+	status       PlanStatus
+	createdAt    time.Time
+	updatedAt    time.Time
+}
+
+// PlanSnapshot contém o estado persistido usado para reconstruir o agregado
+type PlanSnapshot struct {
+	ID           string
+	Name         string
+	Description  string
+	PriceAmount  int64
+	Currency     string
+	Interval     Interval
+	IntervalDays int
+	TrialDays    int
+	Features     []string
+	Status       PlanStatus
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// PlanAttributes agrupa os dados editáveis de um plano
+type PlanAttributes struct {
+	Name         string
+	Description  string
+	PriceAmount  int64
+	Currency     string
+	Interval     Interval
+	IntervalDays int
+	TrialDays    int
+	Features     []string
+}
+
+// Interval representa o intervalo de cobrança do plano
+type Interval string
+
+const (
+	IntervalMonthly Interval = "monthly"
+	IntervalYearly  Interval = "yearly"
+	IntervalCustom  Interval = "custom"
+)
+
+// PlanStatus representa o status do plano no catálogo
+type PlanStatus string
+
+const (
+	PlanStatusActive   PlanStatus = "active"
+	PlanStatusArchived PlanStatus = "archived"
+)
+
+// Erros do domínio
+var (
+	ErrPlanNotFound        = errors.New("plano não encontrado")
+	ErrPlanArchived        = errors.New("plano arquivado")
+	ErrInvalidPlanName     = errors.New("nome do plano é obrigatório")
+	ErrInvalidPrice        = errors.New("preço do plano não pode ser negativo")
+	ErrInvalidCurrency     = errors.New("moeda deve ser um código ISO 4217 de 3 letras")
+	ErrInvalidInterval     = errors.New("intervalo de cobrança inválido")
+	ErrInvalidTrialDays    = errors.New("dias de trial não podem ser negativos")
+	ErrPlanAlreadyArchived = errors.New("plano já está arquivado")
+)
+
+// NewPlan cria um novo plano ativo
+func NewPlan(attributes PlanAttributes) (*Plan, error) {
+	attributes, err := validateAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	plan := &Plan{
+		id:        uuid.New().String(),
+		status:    PlanStatusActive,
+		createdAt: now,
+		updatedAt: now,
+	}
+	plan.apply(attributes)
+
+	return plan, nil
+}
+
+// ReconstructPlan reconstrói um plano a partir de dados persistidos
+func ReconstructPlan(snapshot PlanSnapshot) *Plan {
+	return &Plan{
+		id:           snapshot.ID,
+		name:         snapshot.Name,
+		description:  snapshot.Description,
+		priceAmount:  snapshot.PriceAmount,
+		currency:     snapshot.Currency,
+		interval:     snapshot.Interval,
+		intervalDays: snapshot.IntervalDays,
+		trialDays:    snapshot.TrialDays,
+		features:     snapshot.Features,
+		status:       snapshot.Status,
+		createdAt:    snapshot.CreatedAt,
+		updatedAt:    snapshot.UpdatedAt,
+	}
+}
+
+// Getters
+func (p *Plan) ID() string {
+	return p.id
+}
+
+func (p *Plan) Name() string {
+	return p.name
+}
+
+func (p *Plan) Description() string {
+	return p.description
+}
+
+// PriceAmount retorna o preço por período na menor unidade da moeda (ex: centavos)
+func (p *Plan) PriceAmount() int64 {
+	return p.priceAmount
+}
+
+func (p *Plan) Currency() string {
+	return p.currency
+}
+
+func (p *Plan) Interval() Interval {
+	return p.interval
+}
+
+func (p *Plan) IntervalDays() int {
+	return p.intervalDays
+}
+
+func (p *Plan) TrialDays() int {
+	return p.trialDays
+}
+
+func (p *Plan) Features() []string {
+	return p.features
+}
+
+func (p *Plan) Status() PlanStatus {
+	return p.status
+}
+
+func (p *Plan) CreatedAt() time.Time {
+	return p.createdAt
+}
+
+func (p *Plan) UpdatedAt() time.Time {
+	return p.updatedAt
+}
+
+// IsArchived verifica se o plano foi arquivado
+func (p *Plan) IsArchived() bool {
+	return p.status == PlanStatusArchived
+}
+
+// Update altera os dados do plano
+func (p *Plan) Update(attributes PlanAttributes) error {
+	if p.IsArchived() {
+		return ErrPlanArchived
+	}
+
+	attributes, err := validateAttributes(attributes)
+	if err != nil {
+		return err
+	}
+
+	p.apply(attributes)
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// Archive retira o plano do catálogo; subscriptions existentes continuam válidas
+func (p *Plan) Archive() error {
+	if p.IsArchived() {
+		return ErrPlanAlreadyArchived
+	}
+
+	p.status = PlanStatusArchived
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// apply copia os atributos já validados para o agregado
+func (p *Plan) apply(attributes PlanAttributes) {
+	p.name = attributes.Name
+	p.description = attributes.Description
+	p.priceAmount = attributes.PriceAmount
+	p.currency = attributes.Currency
+	p.interval = attributes.Interval
+	p.intervalDays = attributes.IntervalDays
+	p.trialDays = attributes.TrialDays
+	p.features = attributes.Features
+}
+
+// validateAttributes valida e normaliza os dados do plano
+func validateAttributes(attributes PlanAttributes) (PlanAttributes, error) {
+	attributes.Name = strings.TrimSpace(attributes.Name)
+	if attributes.Name == "" {
+		return attributes, ErrInvalidPlanName
+	}
+
+	if attributes.PriceAmount < 0 {
+		return attributes, ErrInvalidPrice
+	}
+
+	attributes.Currency = strings.ToUpper(strings.TrimSpace(attributes.Currency))
+	if len(attributes.Currency) != 3 {
+		return attributes, ErrInvalidCurrency
+	}
+
+	switch attributes.Interval {
+	case "":
+		attributes.Interval = IntervalMonthly
+		attributes.IntervalDays = 0
+	case IntervalMonthly, IntervalYearly:
+		attributes.IntervalDays = 0
+	case IntervalCustom:
+		if attributes.IntervalDays <= 0 {
+			return attributes, ErrInvalidInterval
+		}
+	default:
+		return attributes, ErrInvalidInterval
+	}
+
+	if attributes.TrialDays < 0 {
+		return attributes, ErrInvalidTrialDays
+	}
+
+	if attributes.Features == nil {
+		attributes.Features = []string{}
+	}
+
+	return attributes, nil
+}
+
+// PlanRepository define o contrato para persistência de planos
+type PlanRepository interface {
+	// Create cria um novo plano
+	Create(ctx context.Context, plan *Plan) error
+
+	// GetByID busca um plano pelo ID
+	GetByID(ctx context.Context, id string) (*Plan, error)
+
+	// GetAll busca todos os planos
+	GetAll(ctx context.Context) ([]*Plan, error)
+
+	// Update atualiza um plano existente
+	Update(ctx context.Context, plan *Plan) error
+}
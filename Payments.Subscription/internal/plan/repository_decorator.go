@@ -0,0 +1,53 @@
+package plan
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PlanRepositoryTracingDecorator é um decorator que adiciona tracing ao repositório de planos
+type PlanRepositoryTracingDecorator struct {
+	repository PlanRepository
+	tracer     trace.Tracer
+}
+
+// NewPlanRepositoryTracingDecorator cria uma nova instância do decorator de tracing
+func NewPlanRepositoryTracingDecorator(repository PlanRepository, tracer trace.Tracer) PlanRepository {
+	return &PlanRepositoryTracingDecorator{
+		repository: repository,
+		tracer:     tracer,
+	}
+}
+
+// Create adiciona tracing à operação de criação
+func (d *PlanRepositoryTracingDecorator) Create(ctx context.Context, plan *Plan) error {
+	ctx, span := d.tracer.Start(ctx, "PlanRepository.Create")
+	defer span.End()
+
+	return d.repository.Create(ctx, plan)
+}
+
+// GetByID adiciona tracing à operação de busca por ID
+func (d *PlanRepositoryTracingDecorator) GetByID(ctx context.Context, id string) (*Plan, error) {
+	ctx, span := d.tracer.Start(ctx, "PlanRepository.GetByID")
+	defer span.End()
+
+	return d.repository.GetByID(ctx, id)
+}
+
+// GetAll adiciona tracing à operação de busca de todos os planos
+func (d *PlanRepositoryTracingDecorator) GetAll(ctx context.Context) ([]*Plan, error) {
+	ctx, span := d.tracer.Start(ctx, "PlanRepository.GetAll")
+	defer span.End()
+
+	return d.repository.GetAll(ctx)
+}
+
+// Update adiciona tracing à operação de atualização
+func (d *PlanRepositoryTracingDecorator) Update(ctx context.Context, plan *Plan) error {
+	ctx, span := d.tracer.Start(ctx, "PlanRepository.Update")
+	defer span.End()
+
+	return d.repository.Update(ctx, plan)
+}
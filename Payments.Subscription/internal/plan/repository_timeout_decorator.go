@@ -0,0 +1,55 @@
+package plan
+
+import (
+	"context"
+	"time"
+)
+
+// PlanRepositoryTimeoutDecorator é um decorator que limita o tempo das operações no banco,
+// com orçamentos separados para leitura e escrita
+type PlanRepositoryTimeoutDecorator struct {
+	repository   PlanRepository
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// NewPlanRepositoryTimeoutDecorator cria uma nova instância do decorator de timeout
+func NewPlanRepositoryTimeoutDecorator(repository PlanRepository, readTimeout, writeTimeout time.Duration) PlanRepository {
+	return &PlanRepositoryTimeoutDecorator{
+		repository:   repository,
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Create aplica o timeout de escrita à criação
+func (d *PlanRepositoryTimeoutDecorator) Create(ctx context.Context, plan *Plan) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Create(ctx, plan)
+}
+
+// GetByID aplica o timeout de leitura à busca por ID
+func (d *PlanRepositoryTimeoutDecorator) GetByID(ctx context.Context, id string) (*Plan, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
+	defer cancel()
+
+	return d.repository.GetByID(ctx, id)
+}
+
+// GetAll aplica o timeout de leitura à listagem
+func (d *PlanRepositoryTimeoutDecorator) GetAll(ctx context.Context) ([]*Plan, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
+	defer cancel()
+
+	return d.repository.GetAll(ctx)
+}
+
+// Update aplica o timeout de escrita à atualização
+func (d *PlanRepositoryTimeoutDecorator) Update(ctx context.Context, plan *Plan) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Update(ctx, plan)
+}
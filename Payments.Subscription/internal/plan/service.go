@@ -0,0 +1,264 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+)
+
+// PlanService representa o serviço de aplicação do catálogo de planos
+type PlanService struct {
+	repository PlanRepository
+	logger     *logging.StructuredLogger
+}
+
+// NewPlanService cria uma nova instância do PlanService
+func NewPlanService(repository PlanRepository) *PlanService {
+	return &PlanService{
+		repository: repository,
+		logger:     logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// PlanRequest representa a requisição para criar ou atualizar um plano
+type PlanRequest struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	PriceAmount  int64    `json:"price_amount"`
+	Currency     string   `json:"currency"`
+	Interval     string   `json:"interval"`
+	IntervalDays int      `json:"interval_days,omitempty"`
+	TrialDays    int      `json:"trial_days"`
+	Features     []string `json:"features"`
+}
+
+// PlanResponse representa a resposta com dados do plano
+type PlanResponse struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	PriceAmount  int64    `json:"price_amount"`
+	Currency     string   `json:"currency"`
+	Interval     string   `json:"interval"`
+	IntervalDays int      `json:"interval_days,omitempty"`
+	TrialDays    int      `json:"trial_days"`
+	Features     []string `json:"features"`
+	Status       string   `json:"status"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+// PlanServiceInterface é uma interface para o PlanService
+type PlanServiceInterface interface {
+	CreatePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error)
+	GetPlanByID(ctx context.Context, id string) (*PlanResponse, error)
+	GetAllPlans(ctx context.Context) ([]*PlanResponse, error)
+	UpdatePlan(ctx context.Context, id string, req PlanRequest) (*PlanResponse, error)
+	ArchivePlan(ctx context.Context, id string) error
+}
+
+// CreatePlan cria um novo plano no catálogo
+func (s *PlanService) CreatePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	startTime := time.Now()
+	operation := "CreatePlan"
+
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+
+	s.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"plan_name": req.Name,
+	})
+
+	plan, err := NewPlan(req.toAttributes())
+	if err != nil {
+		s.logger.Error(ctx, operation, "Dados do plano inválidos", err, map[string]interface{}{
+			"plan_name": req.Name,
+		})
+		return nil, fmt.Errorf("erro ao criar plano: %w", err)
+	}
+
+	if err := s.repository.Create(ctx, plan); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao salvar plano no banco", err, map[string]interface{}{
+			"plan_id": plan.ID(),
+		})
+		return nil, fmt.Errorf("erro ao salvar plano: %w", err)
+	}
+
+	response := toPlanResponse(plan)
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"plan_id": response.ID,
+	})
+
+	return response, nil
+}
+
+// GetPlanByID busca um plano pelo ID
+func (s *PlanService) GetPlanByID(ctx context.Context, id string) (*PlanResponse, error) {
+	startTime := time.Now()
+	operation := "GetPlanByID"
+
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+
+	s.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"plan_id": id,
+	})
+
+	plan, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao buscar plano no banco", err, map[string]interface{}{
+			"plan_id": id,
+		})
+		return nil, fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+
+	response := toPlanResponse(plan)
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"plan_id": response.ID,
+	})
+
+	return response, nil
+}
+
+// GetAllPlans busca todos os planos do catálogo
+func (s *PlanService) GetAllPlans(ctx context.Context) ([]*PlanResponse, error) {
+	startTime := time.Now()
+	operation := "GetAllPlans"
+
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+
+	s.logger.OperationStart(ctx, operation, nil)
+
+	plans, err := s.repository.GetAll(ctx)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao buscar planos", err, nil)
+		return nil, fmt.Errorf("erro ao buscar planos: %w", err)
+	}
+
+	responses := make([]*PlanResponse, len(plans))
+	for i, plan := range plans {
+		responses[i] = toPlanResponse(plan)
+	}
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"total_found": len(responses),
+	})
+
+	return responses, nil
+}
+
+// UpdatePlan atualiza os dados de um plano ativo
+func (s *PlanService) UpdatePlan(ctx context.Context, id string, req PlanRequest) (*PlanResponse, error) {
+	startTime := time.Now()
+	operation := "UpdatePlan"
+
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+
+	s.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"plan_id": id,
+	})
+
+	plan, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao buscar plano", err, map[string]interface{}{
+			"plan_id": id,
+		})
+		return nil, fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+
+	if err := plan.Update(req.toAttributes()); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao atualizar dados do plano", err, map[string]interface{}{
+			"plan_id": id,
+		})
+		return nil, fmt.Errorf("erro ao atualizar plano: %w", err)
+	}
+
+	if err := s.repository.Update(ctx, plan); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao atualizar plano no banco", err, map[string]interface{}{
+			"plan_id": id,
+		})
+		return nil, fmt.Errorf("erro ao atualizar plano: %w", err)
+	}
+
+	response := toPlanResponse(plan)
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"plan_id": response.ID,
+	})
+
+	return response, nil
+}
+
+// ArchivePlan arquiva um plano, impedindo novas subscriptions nele
+func (s *PlanService) ArchivePlan(ctx context.Context, id string) error {
+	startTime := time.Now()
+	operation := "ArchivePlan"
+
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+
+	s.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"plan_id": id,
+	})
+
+	plan, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao buscar plano", err, map[string]interface{}{
+			"plan_id": id,
+		})
+		return fmt.Errorf("erro ao buscar plano: %w", err)
+	}
+
+	if err := plan.Archive(); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao arquivar plano", err, map[string]interface{}{
+			"plan_id": id,
+		})
+		return fmt.Errorf("erro ao arquivar plano: %w", err)
+	}
+
+	if err := s.repository.Update(ctx, plan); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao atualizar plano no banco", err, map[string]interface{}{
+			"plan_id": id,
+		})
+		return fmt.Errorf("erro ao arquivar plano: %w", err)
+	}
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"plan_id": id,
+	})
+
+	return nil
+}
+
+// toAttributes converte a requisição nos atributos do domínio
+func (r PlanRequest) toAttributes() PlanAttributes {
+	return PlanAttributes{
+		Name:         r.Name,
+		Description:  r.Description,
+		PriceAmount:  r.PriceAmount,
+		Currency:     r.Currency,
+		Interval:     Interval(r.Interval),
+		IntervalDays: r.IntervalDays,
+		TrialDays:    r.TrialDays,
+		Features:     r.Features,
+	}
+}
+
+// toPlanResponse converte um Plan para PlanResponse
+func toPlanResponse(plan *Plan) *PlanResponse {
+	return &PlanResponse{
+		ID:           plan.ID(),
+		Name:         plan.Name(),
+		Description:  plan.Description(),
+		PriceAmount:  plan.PriceAmount(),
+		Currency:     plan.Currency(),
+		Interval:     string(plan.Interval()),
+		IntervalDays: plan.IntervalDays(),
+		TrialDays:    plan.TrialDays(),
+		Features:     plan.Features(),
+		Status:       string(plan.Status()),
+		CreatedAt:    plan.CreatedAt().Format(time.RFC3339Nano),
+		UpdatedAt:    plan.UpdatedAt().Format(time.RFC3339Nano),
+	}
+}
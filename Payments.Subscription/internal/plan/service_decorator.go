@@ -0,0 +1,147 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PlanServiceTracingDecorator é um decorator que adiciona tracing e logging ao serviço de planos
+type PlanServiceTracingDecorator struct {
+	service PlanServiceInterface
+	tracer  trace.Tracer
+	logger  *logging.StructuredLogger
+}
+
+// NewPlanServiceTracingDecorator cria uma nova instância do decorator
+func NewPlanServiceTracingDecorator(service PlanServiceInterface, tracer trace.Tracer) PlanServiceInterface {
+	return &PlanServiceTracingDecorator{
+		service: service,
+		tracer:  tracer,
+		logger:  logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// addResponseToSpan adiciona dados da response ou o erro ao span
+func (d *PlanServiceTracingDecorator) addResponseToSpan(span trace.Span, resp interface{}, err error) {
+	if err != nil {
+		span.SetAttributes(
+			attribute.String("error", err.Error()),
+			attribute.Bool("timeout", errors.Is(err, context.DeadlineExceeded)),
+		)
+		return
+	}
+
+	if respJSON, err := json.Marshal(resp); err == nil {
+		span.SetAttributes(attribute.String("response", string(respJSON)))
+	}
+}
+
+// logFailure loga a falha do método com a duração da execução
+func (d *PlanServiceTracingDecorator) logFailure(ctx context.Context, methodName string, start time.Time, err error) {
+	if err == nil {
+		return
+	}
+
+	duration := time.Since(start)
+	d.logger.Error(ctx, "MethodExecution",
+		fmt.Sprintf("Method %s failed after %v", methodName, duration),
+		err,
+		map[string]interface{}{
+			"method":      methodName,
+			"duration_ms": duration.Milliseconds(),
+		})
+}
+
+// CreatePlan adiciona tracing e logging à criação de plano
+func (d *PlanServiceTracingDecorator) CreatePlan(ctx context.Context, req PlanRequest) (*PlanResponse, error) {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.CreatePlan")
+	defer span.End()
+
+	if reqJSON, err := json.Marshal(req); err == nil {
+		span.SetAttributes(attribute.String("request", string(reqJSON)))
+	}
+
+	response, err := d.service.CreatePlan(ctx, req)
+
+	d.addResponseToSpan(span, response, err)
+	d.logFailure(ctx, "CreatePlan", start, err)
+	return response, err
+}
+
+// GetPlanByID adiciona tracing e logging à busca de plano por ID
+func (d *PlanServiceTracingDecorator) GetPlanByID(ctx context.Context, id string) (*PlanResponse, error) {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.GetPlanByID")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("plan_id", id))
+
+	response, err := d.service.GetPlanByID(ctx, id)
+
+	d.addResponseToSpan(span, response, err)
+	d.logFailure(ctx, "GetPlanByID", start, err)
+	return response, err
+}
+
+// GetAllPlans adiciona tracing e logging à listagem de planos
+func (d *PlanServiceTracingDecorator) GetAllPlans(ctx context.Context) ([]*PlanResponse, error) {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.GetAllPlans")
+	defer span.End()
+
+	response, err := d.service.GetAllPlans(ctx)
+
+	if err != nil {
+		span.SetAttributes(attribute.String("error", err.Error()))
+	} else {
+		span.SetAttributes(attribute.Int("response.count", len(response)))
+	}
+
+	d.logFailure(ctx, "GetAllPlans", start, err)
+	return response, err
+}
+
+// UpdatePlan adiciona tracing e logging à atualização de plano
+func (d *PlanServiceTracingDecorator) UpdatePlan(ctx context.Context, id string, req PlanRequest) (*PlanResponse, error) {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.UpdatePlan")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("plan_id", id))
+	if reqJSON, err := json.Marshal(req); err == nil {
+		span.SetAttributes(attribute.String("request", string(reqJSON)))
+	}
+
+	response, err := d.service.UpdatePlan(ctx, id, req)
+
+	d.addResponseToSpan(span, response, err)
+	d.logFailure(ctx, "UpdatePlan", start, err)
+	return response, err
+}
+
+// ArchivePlan adiciona tracing e logging ao arquivamento de plano
+func (d *PlanServiceTracingDecorator) ArchivePlan(ctx context.Context, id string) error {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.ArchivePlan")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("plan_id", id))
+
+	err := d.service.ArchivePlan(ctx, id)
+
+	if err != nil {
+		span.SetAttributes(attribute.String("error", err.Error()))
+	}
+
+	d.logFailure(ctx, "ArchivePlan", start, err)
+	return err
+}
@@ -0,0 +1,75 @@
+package subscription
+
+import (
+	"errors"
+	"time"
+)
+
+// BillingIntervalUnit representa a unidade do intervalo de cobrança
+type BillingIntervalUnit string
+
+const (
+	BillingIntervalMonthly BillingIntervalUnit = "monthly"
+	BillingIntervalYearly  BillingIntervalUnit = "yearly"
+	BillingIntervalCustom  BillingIntervalUnit = "custom"
+)
+
+// ErrInvalidBillingInterval indica um intervalo de cobrança desconhecido ou sem duração
+var ErrInvalidBillingInterval = errors.New("intervalo de cobrança inválido")
+
+// BillingInterval é um value object para o intervalo de cobrança da subscription
+type BillingInterval struct {
+	unit BillingIntervalUnit
+	days int
+}
+
+// NewBillingInterval cria um BillingInterval. O intervalo custom exige a quantidade de dias;
+// um unit vazio assume cobrança mensal
+func NewBillingInterval(unit string, days int) (BillingInterval, error) {
+	switch BillingIntervalUnit(unit) {
+	case "", BillingIntervalMonthly:
+		return BillingInterval{unit: BillingIntervalMonthly}, nil
+	case BillingIntervalYearly:
+		return BillingInterval{unit: BillingIntervalYearly}, nil
+	case BillingIntervalCustom:
+		if days <= 0 {
+			return BillingInterval{}, ErrInvalidBillingInterval
+		}
+		return BillingInterval{unit: BillingIntervalCustom, days: days}, nil
+	default:
+		return BillingInterval{}, ErrInvalidBillingInterval
+	}
+}
+
+// Unit retorna a unidade do intervalo
+func (b BillingInterval) Unit() BillingIntervalUnit {
+	return b.unit
+}
+
+// Days retorna a quantidade de dias do intervalo custom (zero para os demais)
+func (b BillingInterval) Days() int {
+	return b.days
+}
+
+// NextPeriodEnd calcula o fim do período que começa em start
+func (b BillingInterval) NextPeriodEnd(start time.Time) time.Time {
+	switch b.unit {
+	case BillingIntervalYearly:
+		return addMonthsClamped(start, 12)
+	case BillingIntervalCustom:
+		return start.AddDate(0, 0, b.days)
+	default:
+		return addMonthsClamped(start, 1)
+	}
+}
+
+// addMonthsClamped soma meses mantendo o dia dentro do mês de destino (31/01 + 1 mês = 28/02)
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfTarget.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,114 @@
+package subscription
+
+import (
+	"errors"
+	"time"
+)
+
+// Erros do cancelamento agendado
+var (
+	ErrCancellationAlreadyScheduled = errors.New("cancelamento já agendado para o fim do período")
+	ErrNoScheduledCancellation      = errors.New("subscription não possui cancelamento agendado")
+	ErrPeriodEnded                  = errors.New("período corrente já terminou")
+)
+
+type SubscriptionCancellationScheduledEvent struct {
+	BaseEvent
+	Reason      string    `json:"reason"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+type SubscriptionCancellationUndoneEvent struct {
+	BaseEvent
+	CurrentPeriodEnd time.Time `json:"current_period_end"`
+}
+
+// CancelAtPeriodEnd indica se a subscription será cancelada quando o período corrente terminar
+func (s *Subscription) CancelAtPeriodEnd() bool {
+	return s.cancelAtPeriodEnd
+}
+
+// CancellationReason retorna o motivo do cancelamento agendado
+func (s *Subscription) CancellationReason() string {
+	return s.cancellationReason
+}
+
+// ScheduleCancellation agenda o cancelamento para o fim do período; o acesso continua até lá
+func (s *Subscription) ScheduleCancellation(reason string, now time.Time, correlationID string) error {
+	if s.status != SubscriptionStatusActive || !s.HasStartedBilling() {
+		return ErrInvalidStatusTransition
+	}
+
+	if s.cancelAtPeriodEnd {
+		return ErrCancellationAlreadyScheduled
+	}
+
+	s.cancelAtPeriodEnd = true
+	s.cancellationReason = reason
+	s.updatedAt = now
+
+	s.addEvent(SubscriptionCancellationScheduledEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionCancellationScheduled",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		Reason:      reason,
+		EffectiveAt: s.currentPeriodEnd,
+	})
+
+	return nil
+}
+
+// UndoScheduledCancellation desfaz o cancelamento agendado antes do fim do período. Depois do fim,
+// o cancelamento já está vencido e só aguarda o worker efetivá-lo
+func (s *Subscription) UndoScheduledCancellation(now time.Time, correlationID string) error {
+	if !s.cancelAtPeriodEnd {
+		return ErrNoScheduledCancellation
+	}
+
+	if s.status != SubscriptionStatusActive {
+		return ErrInvalidStatusTransition
+	}
+
+	if s.IsPeriodEnded(now) {
+		return ErrPeriodEnded
+	}
+
+	s.clearScheduledCancellation()
+	s.updatedAt = now
+
+	s.addEvent(SubscriptionCancellationUndoneEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionCancellationUndone",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		CurrentPeriodEnd: s.currentPeriodEnd,
+	})
+
+	return nil
+}
+
+// CompleteScheduledCancellation efetiva o cancelamento agendado quando o período termina
+func (s *Subscription) CompleteScheduledCancellation(now time.Time, correlationID string) error {
+	if !s.cancelAtPeriodEnd {
+		return ErrNoScheduledCancellation
+	}
+
+	if !s.IsPeriodEnded(now) {
+		return ErrPeriodNotEnded
+	}
+
+	return s.Cancel(s.cancellationReason, correlationID)
+}
+
+// clearScheduledCancellation remove o agendamento de cancelamento
+func (s *Subscription) clearScheduledCancellation() {
+	s.cancelAtPeriodEnd = false
+	s.cancellationReason = ""
+}
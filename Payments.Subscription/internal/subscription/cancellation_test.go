@@ -0,0 +1,92 @@
+package subscription
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionUndoScheduledCancellation(t *testing.T) {
+	periodStart := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		status        SubscriptionStatus
+		scheduled     bool
+		at            time.Time
+		wantErr       error
+		wantScheduled bool
+	}{
+		{"desfaz antes do fim do período", SubscriptionStatusActive, true, periodEnd.Add(-time.Hour), nil, false},
+		{"rejeita no fim exato do período", SubscriptionStatusActive, true, periodEnd, ErrPeriodEnded, true},
+		{"rejeita depois do fim do período", SubscriptionStatusActive, true, periodEnd.AddDate(0, 0, 2), ErrPeriodEnded, true},
+		{"rejeita sem cancelamento agendado", SubscriptionStatusActive, false, periodStart, ErrNoScheduledCancellation, false},
+		{"rejeita subscription suspensa", SubscriptionStatusSuspended, true, periodStart, ErrInvalidStatusTransition, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := SubscriptionSnapshot{
+				ID:                 "sub-1",
+				PlanID:             "basic",
+				CustomerID:         "customer-1",
+				Status:             tt.status,
+				BillingInterval:    string(BillingIntervalMonthly),
+				CurrentPeriodStart: periodStart,
+				CurrentPeriodEnd:   periodEnd,
+				CancelAtPeriodEnd:  tt.scheduled,
+			}
+			if tt.scheduled {
+				snapshot.CancellationReason = "muito caro"
+			}
+			subscription, err := ReconstructSubscription(snapshot)
+			if err != nil {
+				t.Fatalf("ReconstructSubscription() error = %v", err)
+			}
+
+			err = subscription.UndoScheduledCancellation(tt.at, "corr-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UndoScheduledCancellation() error = %v, want %v", err, tt.wantErr)
+			}
+			if subscription.CancelAtPeriodEnd() != tt.wantScheduled {
+				t.Errorf("CancelAtPeriodEnd() = %v, want %v", subscription.CancelAtPeriodEnd(), tt.wantScheduled)
+			}
+			if wantEvents := tt.wantErr == nil; (len(subscription.Events()) == 1) != wantEvents {
+				t.Errorf("events = %d, want event emitted = %v", len(subscription.Events()), wantEvents)
+			}
+		})
+	}
+}
+
+func TestValidateChangeStatusRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		reason         string
+		reasonRequired bool
+		wantCode       string
+	}{
+		{"motivo informado", "muito caro", true, ""},
+		{"motivo opcional ausente", "", false, ""},
+		{"motivo obrigatório ausente", "", true, FieldCodeRequired},
+		{"motivo no limite da coluna", strings.Repeat("é", maxReasonLength), true, ""},
+		{"motivo acima do limite da coluna", strings.Repeat("a", maxReasonLength+1), true, FieldCodeTooLong},
+		{"motivo opcional acima do limite", strings.Repeat("a", maxReasonLength+1), false, FieldCodeTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrors := ValidateChangeStatusRequest(ChangeStatusRequest{Reason: tt.reason}, tt.reasonRequired)
+			if tt.wantCode == "" {
+				if len(fieldErrors) != 0 {
+					t.Errorf("ValidateChangeStatusRequest() = %+v, want none", fieldErrors)
+				}
+				return
+			}
+			if len(fieldErrors) != 1 || fieldErrors[0].Field != "reason" || fieldErrors[0].Code != tt.wantCode {
+				t.Errorf("ValidateChangeStatusRequest() = %+v, want reason %s", fieldErrors, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Versão da especificação e content types usados nos envelopes CloudEvents
+const (
+	CloudEventsSpecVersion     = "1.0"
+	CloudEventsContentType     = "application/cloudevents+json"
+	CloudEventsDataContentType = "application/json"
+)
+
+// Erros do envelope CloudEvents
+var (
+	ErrInvalidCloudEventMode = errors.New("modo CloudEvents inválido")
+	ErrInvalidCloudEvent     = errors.New("envelope CloudEvents inválido")
+	// ErrNotCloudEvent indica uma mensagem sem envelope, nem structured nem binary
+	ErrNotCloudEvent = errors.New("mensagem não é um CloudEvent")
+)
+
+// CloudEventMode define como o envelope é gravado na mensagem
+type CloudEventMode string
+
+const (
+	// CloudEventModeStructured grava o envelope inteiro em JSON no corpo da mensagem
+	CloudEventModeStructured CloudEventMode = "structured"
+	// CloudEventModeBinary grava os atributos em cabeçalhos e apenas o data no corpo
+	CloudEventModeBinary CloudEventMode = "binary"
+)
+
+// ParseCloudEventMode converte o valor de configuração no modo CloudEvents
+func ParseCloudEventMode(value string) (CloudEventMode, error) {
+	switch mode := CloudEventMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case CloudEventModeStructured, CloudEventModeBinary:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidCloudEventMode, value)
+	}
+}
+
+// CloudEvent é o envelope CloudEvents 1.0 de um evento de domínio. O data leva apenas os campos do
+// evento; id, tipo, agregado, data de ocorrência e correlation ID vão nos atributos do envelope
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	TraceParent     string          `json:"traceparent,omitempty"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// NewCloudEvent monta o envelope do evento; o traceparent vem do span ativo no contexto
+func NewCloudEvent(ctx context.Context, source string, event DomainEvent) (CloudEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return CloudEvent{}, fmt.Errorf("erro ao serializar evento %s: %w", event.EventType(), err)
+	}
+
+	correlationID := event.CorrelationID()
+	if correlationID == "" {
+		correlationID = logging.GetCorrelationID(ctx)
+	}
+
+	trace := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, trace)
+
+	return CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              event.EventID(),
+		Source:          source,
+		Type:            event.EventType(),
+		Subject:         event.AggregateID(),
+		Time:            event.OccurredAt().UTC(),
+		DataContentType: CloudEventsDataContentType,
+		TraceParent:     trace.Get("traceparent"),
+		CorrelationID:   correlationID,
+		Data:            data,
+	}, nil
+}
+
+// Validate verifica os atributos obrigatórios da especificação
+func (e CloudEvent) Validate() error {
+	if e.SpecVersion != CloudEventsSpecVersion {
+		return fmt.Errorf("%w: specversion %q não suportada", ErrInvalidCloudEvent, e.SpecVersion)
+	}
+
+	for _, attribute := range []struct{ name, value string }{
+		{"id", e.ID},
+		{"source", e.Source},
+		{"type", e.Type},
+	} {
+		if attribute.value == "" {
+			return fmt.Errorf("%w: atributo %s é obrigatório", ErrInvalidCloudEvent, attribute.name)
+		}
+	}
+
+	return nil
+}
+
+// CloudEventBinding descreve como os atributos são gravados nos cabeçalhos de um protocolo no modo binary
+type CloudEventBinding struct {
+	prefix            string
+	contentTypeHeader string
+}
+
+// Bindings dos protocolos usados pelo serviço
+var (
+	HTTPBinding  = CloudEventBinding{prefix: "ce-", contentTypeHeader: "Content-Type"}
+	KafkaBinding = CloudEventBinding{prefix: "ce_", contentTypeHeader: "content-type"}
+	NATSBinding  = CloudEventBinding{prefix: "ce-", contentTypeHeader: "Content-Type"}
+)
+
+// Encode grava o envelope nos cabeçalhos e retorna o corpo da mensagem de acordo com o modo
+func (b CloudEventBinding) Encode(event CloudEvent, mode CloudEventMode, headers propagation.TextMapCarrier) ([]byte, error) {
+	if mode == CloudEventModeBinary {
+		for attribute, value := range event.attributes() {
+			headers.Set(b.prefix+attribute, value)
+		}
+		if event.DataContentType != "" {
+			headers.Set(b.contentTypeHeader, event.DataContentType)
+		}
+		return event.Data, nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar envelope CloudEvents: %w", err)
+	}
+	headers.Set(b.contentTypeHeader, CloudEventsContentType)
+	return body, nil
+}
+
+// Decode lê o envelope da mensagem, detectando o modo pelos cabeçalhos. Retorna ErrNotCloudEvent
+// quando a mensagem não traz envelope, para que o chamador aceite o formato anterior
+func (b CloudEventBinding) Decode(headers propagation.TextMapCarrier, body []byte) (CloudEvent, error) {
+	var event CloudEvent
+
+	contentType := headers.Get(b.contentTypeHeader)
+	switch {
+	case strings.HasPrefix(contentType, CloudEventsContentType):
+		if err := json.Unmarshal(body, &event); err != nil {
+			return CloudEvent{}, fmt.Errorf("%w: %v", ErrInvalidCloudEvent, err)
+		}
+	case headers.Get(b.prefix+"specversion") != "":
+		event = CloudEvent{
+			SpecVersion:     headers.Get(b.prefix + "specversion"),
+			ID:              headers.Get(b.prefix + "id"),
+			Source:          headers.Get(b.prefix + "source"),
+			Type:            headers.Get(b.prefix + "type"),
+			Subject:         headers.Get(b.prefix + "subject"),
+			DataContentType: contentType,
+			TraceParent:     headers.Get(b.prefix + "traceparent"),
+			CorrelationID:   headers.Get(b.prefix + "correlationid"),
+			Data:            body,
+		}
+		if raw := headers.Get(b.prefix + "time"); raw != "" {
+			occurredAt, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return CloudEvent{}, fmt.Errorf("%w: time %q não está no formato RFC 3339", ErrInvalidCloudEvent, raw)
+			}
+			event.Time = occurredAt
+		}
+	default:
+		return CloudEvent{}, ErrNotCloudEvent
+	}
+
+	if err := event.Validate(); err != nil {
+		return CloudEvent{}, err
+	}
+	return event, nil
+}
+
+// attributes lista os atributos preenchidos do envelope, exceto datacontenttype e data
+func (e CloudEvent) attributes() map[string]string {
+	attributes := map[string]string{
+		"specversion": e.SpecVersion,
+		"id":          e.ID,
+		"source":      e.Source,
+		"type":        e.Type,
+	}
+	if e.Subject != "" {
+		attributes["subject"] = e.Subject
+	}
+	if !e.Time.IsZero() {
+		attributes["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.TraceParent != "" {
+		attributes["traceparent"] = e.TraceParent
+	}
+	if e.CorrelationID != "" {
+		attributes["correlationid"] = e.CorrelationID
+	}
+	return attributes
+}
+
+// eventDecoder reconstrói o evento tipado a partir dos campos comuns e do data do envelope
+type eventDecoder func(base BaseEvent, data []byte) (DomainEvent, error)
+
+// eventDecoders registra, por tipo, como cada evento publicado ou consumido volta a ser tipado
+var eventDecoders = map[string]eventDecoder{
+	"SubscriptionRequested":             decodeEvent[SubscriptionRequestedEvent],
+	"SubscriptionReadyForActivation":    decodeEvent[SubscriptionReadyForActivationEvent],
+	"SubscriptionActivated":             decodeEvent[SubscriptionActivatedEvent],
+	"SubscriptionCancelled":             decodeEvent[SubscriptionCancelledEvent],
+	"SubscriptionSuspended":             decodeEvent[SubscriptionSuspendedEvent],
+	"SubscriptionResumed":               decodeEvent[SubscriptionResumedEvent],
+	"SubscriptionExpired":               decodeEvent[SubscriptionExpiredEvent],
+	"SubscriptionRenewed":               decodeEvent[SubscriptionRenewedEvent],
+	"SubscriptionTrialStarted":          decodeEvent[SubscriptionTrialStartedEvent],
+	"SubscriptionTrialWillEnd":          decodeEvent[SubscriptionTrialWillEndEvent],
+	"SubscriptionTrialEnded":            decodeEvent[SubscriptionTrialEndedEvent],
+	"SubscriptionPaymentMethodAttached": decodeEvent[SubscriptionPaymentMethodAttachedEvent],
+	"SubscriptionPlanChangeScheduled":   decodeEvent[SubscriptionPlanChangeScheduledEvent],
+	"SubscriptionPlanChanged":           decodeEvent[SubscriptionPlanChangedEvent],
+	"SubscriptionCancellationScheduled": decodeEvent[SubscriptionCancellationScheduledEvent],
+	"SubscriptionCancellationUndone":    decodeEvent[SubscriptionCancellationUndoneEvent],
+	CustomerCreatedEventType:            decodeCustomerEvent,
+	CustomerVerifiedEventType:           decodeCustomerEvent,
+}
+
+// DecodeCloudEvent converte o envelope no evento de domínio tipado correspondente ao seu type
+func DecodeCloudEvent(event CloudEvent) (DomainEvent, error) {
+	decoder, ok := eventDecoders[event.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEvent, event.Type)
+	}
+
+	base := BaseEvent{
+		eventID:       event.ID,
+		eventType:     event.Type,
+		aggregateID:   event.Subject,
+		occurredAt:    event.Time,
+		correlationID: event.CorrelationID,
+	}
+
+	return decoder(base, event.Data)
+}
+
+// decodeEvent deserializa o data no tipo T e restaura os campos comuns do evento
+func decodeEvent[T DomainEvent, PT interface {
+	*T
+	setBase(BaseEvent)
+}](base BaseEvent, data []byte) (DomainEvent, error) {
+	var event T
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("%w: payload inválido para %s: %v", ErrUnsupportedEvent, base.eventType, err)
+		}
+	}
+
+	PT(&event).setBase(base)
+	return event, nil
+}
+
+// decodeCustomerEvent usa a subscription do payload como agregado, já que o subject do envelope
+// publicado pelo serviço de Customer pode identificar o customer
+func decodeCustomerEvent(base BaseEvent, data []byte) (DomainEvent, error) {
+	decoded, err := decodeEvent[CustomerEvent](base, data)
+	if err != nil {
+		return nil, err
+	}
+
+	event := decoded.(CustomerEvent)
+	if event.SubscriptionID != "" {
+		event.aggregateID = event.SubscriptionID
+	}
+	return event, nil
+}
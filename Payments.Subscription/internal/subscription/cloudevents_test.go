@@ -0,0 +1,202 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const testSource = "/payments/subscription-service"
+
+func TestCloudEventRoundTrip(t *testing.T) {
+	requested, err := NewSubscription("plan-basic", "customer-1", BillingInterval{}, "corr-1")
+	if err != nil {
+		t.Fatalf("NewSubscription() error = %v", err)
+	}
+	customerCreated := NewCustomerEvent(CustomerCreatedEventType, "sub-1", "customer-1", "ana@example.com", "corr-2", time.Now())
+
+	// O span ativo fornece o traceparent do envelope
+	ctx, span := sdktrace.NewTracerProvider().Tracer("cloudevents-test").Start(context.Background(), "publish")
+	defer span.End()
+
+	tests := []struct {
+		name    string
+		event   DomainEvent
+		binding CloudEventBinding
+		mode    CloudEventMode
+		headers func() propagation.TextMapCarrier
+		// wantContentType é o content type gravado no cabeçalho do protocolo
+		wantContentType string
+		// wantAggregateID é o agregado após a decodificação
+		wantAggregateID string
+	}{
+		{"structured no Kafka", requested.Events()[0], KafkaBinding, CloudEventModeStructured, newMapCarrier, CloudEventsContentType, requested.ID().String()},
+		{"binary no Kafka", requested.Events()[0], KafkaBinding, CloudEventModeBinary, newMapCarrier, CloudEventsDataContentType, requested.ID().String()},
+		{"structured no HTTP", customerCreated, HTTPBinding, CloudEventModeStructured, newHeaderCarrier, CloudEventsContentType, "sub-1"},
+		{"binary no HTTP", customerCreated, HTTPBinding, CloudEventModeBinary, newHeaderCarrier, CloudEventsDataContentType, "sub-1"},
+		{"binary no NATS", customerCreated, NATSBinding, CloudEventModeBinary, newMapCarrier, CloudEventsDataContentType, "sub-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := NewCloudEvent(ctx, testSource, tt.event)
+			if err != nil {
+				t.Fatalf("NewCloudEvent() error = %v", err)
+			}
+			if envelope.TraceParent == "" {
+				t.Error("envelope sem traceparent")
+			}
+
+			headers := tt.headers()
+			body, err := tt.binding.Encode(envelope, tt.mode, headers)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got := headers.Get(tt.binding.contentTypeHeader); got != tt.wantContentType {
+				t.Errorf("content type = %q, want %q", got, tt.wantContentType)
+			}
+
+			decodedEnvelope, err := tt.binding.Decode(headers, body)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decodedEnvelope.Source != testSource || decodedEnvelope.TraceParent != envelope.TraceParent {
+				t.Errorf("envelope = %+v, want source %q and traceparent %q", decodedEnvelope, testSource, envelope.TraceParent)
+			}
+
+			decoded, err := DecodeCloudEvent(decodedEnvelope)
+			if err != nil {
+				t.Fatalf("DecodeCloudEvent() error = %v", err)
+			}
+			if decoded.EventID() != tt.event.EventID() {
+				t.Errorf("EventID = %q, want %q", decoded.EventID(), tt.event.EventID())
+			}
+			if decoded.EventType() != tt.event.EventType() {
+				t.Errorf("EventType = %q, want %q", decoded.EventType(), tt.event.EventType())
+			}
+			if decoded.AggregateID() != tt.wantAggregateID {
+				t.Errorf("AggregateID = %q, want %q", decoded.AggregateID(), tt.wantAggregateID)
+			}
+			if decoded.CorrelationID() != tt.event.CorrelationID() {
+				t.Errorf("CorrelationID = %q, want %q", decoded.CorrelationID(), tt.event.CorrelationID())
+			}
+			if !decoded.OccurredAt().Equal(tt.event.OccurredAt()) {
+				t.Errorf("OccurredAt = %v, want %v", decoded.OccurredAt(), tt.event.OccurredAt())
+			}
+
+			switch original := tt.event.(type) {
+			case SubscriptionRequestedEvent:
+				got, ok := decoded.(SubscriptionRequestedEvent)
+				if !ok || got.PlanID != original.PlanID || got.CustomerID != original.CustomerID {
+					t.Errorf("decoded = %+v, want %+v", decoded, original)
+				}
+			case CustomerEvent:
+				got, ok := decoded.(CustomerEvent)
+				if !ok || got.SubscriptionID != original.SubscriptionID || got.CustomerID != original.CustomerID || got.Email != original.Email {
+					t.Errorf("decoded = %+v, want %+v", decoded, original)
+				}
+			default:
+				t.Fatalf("evento %T sem verificação de payload", tt.event)
+			}
+		})
+	}
+}
+
+func TestNewCloudEventUsesContextCorrelationID(t *testing.T) {
+	event := NewCustomerEvent(CustomerCreatedEventType, "sub-1", "customer-1", "", "", time.Now())
+	ctx := logging.WithCorrelationID(context.Background(), "corr-from-context")
+
+	envelope, err := NewCloudEvent(ctx, testSource, event)
+	if err != nil {
+		t.Fatalf("NewCloudEvent() error = %v", err)
+	}
+	if envelope.CorrelationID != "corr-from-context" {
+		t.Errorf("CorrelationID = %q, want %q", envelope.CorrelationID, "corr-from-context")
+	}
+	if envelope.TraceParent != "" {
+		t.Errorf("TraceParent = %q, want empty without an active span", envelope.TraceParent)
+	}
+}
+
+func TestCloudEventBindingDecodeRejectsInvalidMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		wantErr error
+	}{
+		{"sem envelope", map[string]string{"content-type": "application/json"}, `{"subscription_id":"sub-1"}`, ErrNotCloudEvent},
+		{"structured com JSON inválido", map[string]string{"content-type": CloudEventsContentType}, `{"specversion":`, ErrInvalidCloudEvent},
+		{"structured com specversion não suportada", map[string]string{"content-type": CloudEventsContentType}, `{"specversion":"0.3","id":"evt-1","source":"/s","type":"SubscriptionActivated"}`, ErrInvalidCloudEvent},
+		{"structured sem id", map[string]string{"content-type": CloudEventsContentType}, `{"specversion":"1.0","source":"/s","type":"SubscriptionActivated"}`, ErrInvalidCloudEvent},
+		{"binary sem type", map[string]string{"ce_specversion": "1.0", "ce_id": "evt-1", "ce_source": "/s"}, `{}`, ErrInvalidCloudEvent},
+		{"binary com time fora do RFC 3339", map[string]string{"ce_specversion": "1.0", "ce_id": "evt-1", "ce_source": "/s", "ce_type": "SubscriptionActivated", "ce_time": "10/03/2026"}, `{}`, ErrInvalidCloudEvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := KafkaBinding.Decode(propagation.MapCarrier(tt.headers), []byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeCloudEventRejectsUnknownTypesAndPayloads(t *testing.T) {
+	tests := []struct {
+		name  string
+		event CloudEvent
+	}{
+		{"tipo desconhecido", CloudEvent{SpecVersion: CloudEventsSpecVersion, ID: "evt-1", Type: "CustomerDeleted"}},
+		{"payload incompatível com o tipo", CloudEvent{SpecVersion: CloudEventsSpecVersion, ID: "evt-1", Type: "SubscriptionRequested", Data: []byte(`{"plan_id":42}`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCloudEvent(tt.event); !errors.Is(err, ErrUnsupportedEvent) {
+				t.Errorf("DecodeCloudEvent() error = %v, want %v", err, ErrUnsupportedEvent)
+			}
+		})
+	}
+}
+
+func TestParseCloudEventMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    CloudEventMode
+		wantErr error
+	}{
+		{"structured", CloudEventModeStructured, nil},
+		{" Binary ", CloudEventModeBinary, nil},
+		{"batched", "", ErrInvalidCloudEventMode},
+		{"", "", ErrInvalidCloudEventMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, err := ParseCloudEventMode(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseCloudEventMode(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+			}
+			if mode != tt.want {
+				t.Errorf("ParseCloudEventMode(%q) = %q, want %q", tt.value, mode, tt.want)
+			}
+		})
+	}
+}
+
+func newMapCarrier() propagation.TextMapCarrier {
+	return propagation.MapCarrier{}
+}
+
+func newHeaderCarrier() propagation.TextMapCarrier {
+	return propagation.HeaderCarrier(http.Header{})
+}
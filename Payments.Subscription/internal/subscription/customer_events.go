@@ -0,0 +1,130 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+)
+
+// Tipos dos eventos emitidos pelo serviço de Customer em resposta ao SubscriptionRequested
+const (
+	CustomerCreatedEventType  = "CustomerCreated"
+	CustomerVerifiedEventType = "CustomerVerified"
+)
+
+// ErrUnsupportedEvent indica um evento que não é tratado pelo handler que o recebeu
+var ErrUnsupportedEvent = errors.New("evento não suportado")
+
+// CustomerEvent é o evento recebido do serviço de Customer com o customer criado ou encontrado
+// para uma subscription solicitada
+type CustomerEvent struct {
+	BaseEvent
+	SubscriptionID string `json:"subscription_id"`
+	CustomerID     string `json:"customer_id"`
+	Email          string `json:"email,omitempty"`
+}
+
+// NewCustomerEvent cria um evento de customer recebido de outro serviço
+func NewCustomerEvent(eventType, subscriptionID, customerID, email, correlationID string, occurredAt time.Time) CustomerEvent {
+	return CustomerEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     eventType,
+			aggregateID:   customerID,
+			occurredAt:    occurredAt,
+			correlationID: correlationID,
+		},
+		SubscriptionID: subscriptionID,
+		CustomerID:     customerID,
+		Email:          email,
+	}
+}
+
+// CustomerOnboardingHandler vincula o customer recebido à subscription pendente e a marca como
+// pronta para ativação, concluindo o onboarding assíncrono
+type CustomerOnboardingHandler struct {
+	repository SubscriptionRepository
+	logger     *logging.StructuredLogger
+}
+
+// NewCustomerOnboardingHandler cria uma nova instância do handler de onboarding
+func NewCustomerOnboardingHandler(repository SubscriptionRepository) *CustomerOnboardingHandler {
+	return &CustomerOnboardingHandler{
+		repository: repository,
+		logger:     logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// CanHandle indica se o handler trata o tipo de evento informado
+func (h *CustomerOnboardingHandler) CanHandle(eventType string) bool {
+	return eventType == CustomerCreatedEventType || eventType == CustomerVerifiedEventType
+}
+
+// Handle processa o evento de customer. Eventos repetidos para uma subscription já vinculada ao
+// mesmo customer são ignorados, já que o broker pode entregar a mesma mensagem mais de uma vez
+func (h *CustomerOnboardingHandler) Handle(ctx context.Context, event DomainEvent) error {
+	operation := "HandleCustomerEvent"
+
+	customerEvent, ok := event.(CustomerEvent)
+	if !ok || !h.CanHandle(event.EventType()) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedEvent, event.EventType())
+	}
+
+	if event.CorrelationID() != "" {
+		ctx = logging.WithCorrelationID(ctx, event.CorrelationID())
+	} else {
+		ctx = logging.EnsureCorrelationID(ctx, "subscription")
+	}
+
+	subscriptionID, err := NewSubscriptionIDFromString(customerEvent.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("ID inválido: %w", err)
+	}
+
+	subscription, err := h.repository.GetByID(ctx, subscriptionID)
+	if err != nil {
+		h.logger.Error(ctx, operation, "Erro ao buscar subscription do evento de customer", err, map[string]interface{}{
+			"subscription_id": customerEvent.SubscriptionID,
+			"event_type":      event.EventType(),
+		})
+		return fmt.Errorf("erro ao buscar subscription: %w", err)
+	}
+
+	if !subscription.AwaitingCustomer() && subscription.CustomerID().String() == customerEvent.CustomerID {
+		h.logger.Info(ctx, operation, "Evento de customer repetido ignorado", map[string]interface{}{
+			"subscription_id": customerEvent.SubscriptionID,
+			"customer_id":     customerEvent.CustomerID,
+			"event_type":      event.EventType(),
+		})
+		return nil
+	}
+
+	if err := subscription.LinkCustomer(customerEvent.CustomerID, time.Now(), logging.GetCorrelationID(ctx)); err != nil {
+		h.logger.Error(ctx, operation, "Erro ao vincular customer à subscription", err, map[string]interface{}{
+			"subscription_id": customerEvent.SubscriptionID,
+			"customer_id":     customerEvent.CustomerID,
+			"current_status":  string(subscription.Status()),
+		})
+		return fmt.Errorf("erro ao vincular customer: %w", err)
+	}
+
+	if err := h.repository.Update(ctx, subscription); err != nil {
+		h.logger.Error(ctx, operation, "Erro ao atualizar subscription no banco", err, map[string]interface{}{
+			"subscription_id": customerEvent.SubscriptionID,
+			"customer_id":     customerEvent.CustomerID,
+		})
+		return fmt.Errorf("erro ao atualizar subscription: %w", err)
+	}
+
+	h.logger.Info(ctx, operation, "Customer vinculado, subscription pronta para ativação", map[string]interface{}{
+		"subscription_id": customerEvent.SubscriptionID,
+		"customer_id":     customerEvent.CustomerID,
+		"event_type":      event.EventType(),
+		"status":          string(subscription.Status()),
+	})
+
+	return nil
+}
@@ -0,0 +1,370 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DeadLetterSource indica em que etapa o evento falhou
+type DeadLetterSource string
+
+const (
+	// DeadLetterSourcePublish são eventos do outbox que esgotaram as tentativas de publicação
+	DeadLetterSourcePublish DeadLetterSource = "publish"
+	// DeadLetterSourceHandler são eventos recebidos que esgotaram as tentativas dos handlers
+	DeadLetterSourceHandler DeadLetterSource = "handler"
+)
+
+// IsValid indica se a origem é uma das origens conhecidas
+func (s DeadLetterSource) IsValid() bool {
+	return s == DeadLetterSourcePublish || s == DeadLetterSourceHandler
+}
+
+// DeadLetterStatus representa a situação do evento na dead-letter queue
+type DeadLetterStatus string
+
+const (
+	DeadLetterStatusPending DeadLetterStatus = "pending"
+	// DeadLetterStatusReplaying indica um reprocessamento em andamento, reservado por uma requisição
+	DeadLetterStatusReplaying DeadLetterStatus = "replaying"
+	DeadLetterStatusReplayed  DeadLetterStatus = "replayed"
+	DeadLetterStatusDiscarded DeadLetterStatus = "discarded"
+)
+
+// DeadLetterReplayLease é o tempo após o qual a reserva de um reprocessamento interrompido (por
+// exemplo, por uma queda do processo) pode ser retomada por outro reprocessamento
+const DeadLetterReplayLease = 5 * time.Minute
+
+// IsValid indica se o status é um dos status conhecidos
+func (s DeadLetterStatus) IsValid() bool {
+	switch s {
+	case DeadLetterStatusPending, DeadLetterStatusReplaying, DeadLetterStatusReplayed, DeadLetterStatusDiscarded:
+		return true
+	default:
+		return false
+	}
+}
+
+// Erros da dead-letter queue
+var (
+	ErrDeadLetterNotFound   = errors.New("evento não encontrado na dead-letter queue")
+	ErrDeadLetterNotPending = errors.New("evento da dead-letter queue já foi reprocessado, descartado ou está em reprocessamento")
+)
+
+// Limites da listagem da dead-letter queue
+const (
+	DefaultDeadLetterPageSize = 50
+	MaxDeadLetterPageSize     = 200
+)
+
+// DeadLetterEvent é um evento que falhou de forma definitiva, guardado com o erro, as tentativas e
+// os cabeçalhos originais para inspeção, reprocessamento ou descarte
+type DeadLetterEvent struct {
+	ID             string            `json:"id"`
+	EventID        string            `json:"event_id"`
+	Source         DeadLetterSource  `json:"source"`
+	Status         DeadLetterStatus  `json:"status"`
+	EventType      string            `json:"event_type"`
+	AggregateID    string            `json:"aggregate_id"`
+	CorrelationID  string            `json:"correlation_id"`
+	Handler        string            `json:"handler,omitempty"`
+	Payload        json.RawMessage   `json:"payload"`
+	Headers        map[string]string `json:"headers"`
+	Error          string            `json:"error"`
+	Attempts       int               `json:"attempts"`
+	ReplayAttempts int               `json:"replay_attempts"`
+	OccurredAt     time.Time         `json:"occurred_at"`
+	CreatedAt      time.Time         `json:"created_at"`
+	ResolvedAt     *time.Time        `json:"resolved_at,omitempty"`
+}
+
+// NewDeadLetterEvent cria o registro da dead-letter queue de um evento que falhou. Os cabeçalhos
+// guardam o contexto de trace e o correlation ID presentes no momento da falha
+func NewDeadLetterEvent(ctx context.Context, source DeadLetterSource, event DomainEvent, handler string, attempts int, cause error) (*DeadLetterEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento %s: %w", event.EventType(), err)
+	}
+
+	correlationID := event.CorrelationID()
+	if correlationID == "" {
+		correlationID = logging.GetCorrelationID(ctx)
+	}
+
+	headers := map[string]string{
+		"event-type": event.EventType(),
+	}
+	if correlationID != "" {
+		headers["X-Correlation-ID"] = correlationID
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+
+	errorMessage := ""
+	if cause != nil {
+		errorMessage = cause.Error()
+	}
+
+	return &DeadLetterEvent{
+		ID:            uuid.New().String(),
+		EventID:       event.EventID(),
+		Source:        source,
+		Status:        DeadLetterStatusPending,
+		EventType:     event.EventType(),
+		AggregateID:   event.AggregateID(),
+		CorrelationID: correlationID,
+		Handler:       handler,
+		Payload:       payload,
+		Headers:       headers,
+		Error:         errorMessage,
+		Attempts:      attempts,
+		OccurredAt:    event.OccurredAt(),
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// toDomainEvent recupera o evento com o payload original, no mesmo formato usado pelo outbox. O ID
+// da mensagem é o do evento original, não o do registro na dead-letter queue, para que o evento seja
+// republicado com o mesmo ID
+func (e *DeadLetterEvent) toDomainEvent() DomainEvent {
+	return NewOutboxEvent(&OutboxMessage{
+		ID:            e.EventID,
+		EventID:       e.EventID,
+		AggregateID:   e.AggregateID,
+		EventType:     e.EventType,
+		CorrelationID: e.CorrelationID,
+		Payload:       e.Payload,
+		OccurredAt:    e.OccurredAt,
+		CreatedAt:     e.CreatedAt,
+	})
+}
+
+// IsPending indica se o evento ainda aguarda reprocessamento ou descarte
+func (e *DeadLetterEvent) IsPending() bool {
+	return e.Status == DeadLetterStatusPending
+}
+
+// DeadLetterFilter filtra a listagem da dead-letter queue
+type DeadLetterFilter struct {
+	EventType     string
+	AggregateID   string
+	CorrelationID string
+	Source        DeadLetterSource
+	Status        DeadLetterStatus
+	Limit         int
+}
+
+// DeadLetterRepository define o contrato de persistência da dead-letter queue
+type DeadLetterRepository interface {
+	Create(ctx context.Context, event *DeadLetterEvent) error
+	GetByID(ctx context.Context, id string) (*DeadLetterEvent, error)
+	List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetterEvent, error)
+	// ClaimReplay reserva o evento para reprocessamento, de pending para replaying. Reservas anteriores a
+	// staleBefore são de reprocessamentos interrompidos e podem ser retomadas. Retorna
+	// ErrDeadLetterNotPending se o evento já foi resolvido ou está reservado por outro reprocessamento
+	ClaimReplay(ctx context.Context, id string, staleBefore time.Time) error
+	// Resolve muda o status do evento de from para to; retorna ErrDeadLetterNotPending se o evento não
+	// está mais em from
+	Resolve(ctx context.Context, id string, from, to DeadLetterStatus, resolvedAt time.Time) error
+	// RecordReplayFailure registra uma tentativa de reprocessamento que falhou e libera a reserva,
+	// devolvendo o evento para pending
+	RecordReplayFailure(ctx context.Context, id string, replayErr error) error
+}
+
+// DeadLetterPoisonHandler grava na dead-letter queue os eventos envenenados do EventDispatcher
+type DeadLetterPoisonHandler struct {
+	repository DeadLetterRepository
+	logger     *logging.StructuredLogger
+}
+
+// NewDeadLetterPoisonHandler cria uma nova instância do DeadLetterPoisonHandler
+func NewDeadLetterPoisonHandler(repository DeadLetterRepository) *DeadLetterPoisonHandler {
+	return &DeadLetterPoisonHandler{
+		repository: repository,
+		logger:     logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// HandlePoison implementa PoisonHandler
+func (h *DeadLetterPoisonHandler) HandlePoison(ctx context.Context, event DomainEvent, handlerName string, attempts int, cause error) error {
+	deadLetter, err := NewDeadLetterEvent(ctx, DeadLetterSourceHandler, event, handlerName, attempts, cause)
+	if err != nil {
+		return err
+	}
+
+	if err := h.repository.Create(ctx, deadLetter); err != nil {
+		return fmt.Errorf("erro ao gravar evento na dead-letter queue: %w", err)
+	}
+
+	h.logger.Error(ctx, "DeadLetterQueue", "Evento enviado para a dead-letter queue", cause, map[string]interface{}{
+		"dead_letter_id": deadLetter.ID,
+		"event_type":     deadLetter.EventType,
+		"aggregate_id":   deadLetter.AggregateID,
+		"handler":        handlerName,
+		"attempts":       attempts,
+		"source":         string(DeadLetterSourceHandler),
+	})
+	return nil
+}
+
+// DeadLetterService expõe as operações administrativas da dead-letter queue
+type DeadLetterService struct {
+	repository DeadLetterRepository
+	publisher  EventPublisher
+	dispatcher *EventDispatcher
+	logger     *logging.StructuredLogger
+}
+
+// NewDeadLetterService cria uma nova instância do DeadLetterService
+func NewDeadLetterService(repository DeadLetterRepository, publisher EventPublisher, dispatcher *EventDispatcher) *DeadLetterService {
+	return &DeadLetterService{
+		repository: repository,
+		publisher:  publisher,
+		dispatcher: dispatcher,
+		logger:     logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// List lista os eventos da dead-letter queue, mais recentes primeiro
+func (s *DeadLetterService) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetterEvent, error) {
+	if filter.Limit == 0 {
+		filter.Limit = DefaultDeadLetterPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxDeadLetterPageSize {
+		return nil, ErrInvalidPageSize
+	}
+
+	events, err := s.repository.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar dead-letter queue: %w", err)
+	}
+	return events, nil
+}
+
+// Get busca um evento da dead-letter queue pelo ID
+func (s *DeadLetterService) Get(ctx context.Context, id string) (*DeadLetterEvent, error) {
+	event, err := s.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar evento da dead-letter queue: %w", err)
+	}
+	return event, nil
+}
+
+// Replay reprocessa o evento pela mesma etapa em que falhou: eventos de publish são publicados de
+// novo e eventos de handler voltam para o dispatcher, sem gerar uma nova entrada na dead-letter queue.
+// O evento é reservado antes do reprocessamento, então requisições concorrentes não o repetem
+func (s *DeadLetterService) Replay(ctx context.Context, id string) (*DeadLetterEvent, error) {
+	operation := "ReplayDeadLetter"
+
+	event, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.ClaimReplay(ctx, id, time.Now().Add(-DeadLetterReplayLease)); err != nil {
+		return nil, fmt.Errorf("erro ao reprocessar evento %s: %w", id, err)
+	}
+	event.Status = DeadLetterStatusReplaying
+
+	if event.CorrelationID != "" {
+		ctx = logging.WithCorrelationID(ctx, event.CorrelationID)
+	}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(event.Headers))
+
+	var replayErr error
+	switch event.Source {
+	case DeadLetterSourcePublish:
+		replayErr = s.publisher.Publish(ctx, event.toDomainEvent())
+	case DeadLetterSourceHandler:
+		replayErr = s.redispatch(ctx, event)
+	default:
+		replayErr = fmt.Errorf("%w: origem %s", ErrUnsupportedEvent, event.Source)
+	}
+
+	if replayErr != nil {
+		s.logger.Error(ctx, operation, "Falha ao reprocessar evento da dead-letter queue", replayErr, map[string]interface{}{
+			"dead_letter_id": id,
+			"event_type":     event.EventType,
+			"source":         string(event.Source),
+		})
+		// A reserva precisa ser liberada mesmo que a requisição tenha sido cancelada
+		if err := s.repository.RecordReplayFailure(context.WithoutCancel(ctx), id, replayErr); err != nil {
+			s.logger.Error(ctx, operation, "Erro ao registrar falha do reprocessamento", err, map[string]interface{}{
+				"dead_letter_id": id,
+			})
+		}
+		return nil, fmt.Errorf("erro ao reprocessar evento %s: %w", id, replayErr)
+	}
+
+	if err := s.resolve(context.WithoutCancel(ctx), event, DeadLetterStatusReplayed); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, operation, "Evento da dead-letter queue reprocessado", map[string]interface{}{
+		"dead_letter_id": id,
+		"event_type":     event.EventType,
+		"source":         string(event.Source),
+	})
+	return event, nil
+}
+
+// Discard marca o evento como descartado, sem reprocessá-lo
+func (s *DeadLetterService) Discard(ctx context.Context, id string) (*DeadLetterEvent, error) {
+	event, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !event.IsPending() {
+		return nil, fmt.Errorf("erro ao descartar evento %s: %w", id, ErrDeadLetterNotPending)
+	}
+
+	if err := s.resolve(ctx, event, DeadLetterStatusDiscarded); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "DiscardDeadLetter", "Evento da dead-letter queue descartado", map[string]interface{}{
+		"dead_letter_id": id,
+		"event_type":     event.EventType,
+	})
+	return event, nil
+}
+
+// redispatch reconstrói o evento tipado a partir do payload, preservando o ID original, e o entrega aos handlers
+func (s *DeadLetterService) redispatch(ctx context.Context, event *DeadLetterEvent) error {
+	domainEvent, err := DecodeCloudEvent(CloudEvent{
+		SpecVersion:   CloudEventsSpecVersion,
+		ID:            event.EventID,
+		Type:          event.EventType,
+		Subject:       event.AggregateID,
+		Time:          event.OccurredAt,
+		CorrelationID: event.CorrelationID,
+		Data:          event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.dispatcher.Replay(ctx, domainEvent)
+}
+
+// resolve grava o novo status do evento a partir do status atual (pending no descarte, replaying no
+// reprocessamento)
+func (s *DeadLetterService) resolve(ctx context.Context, event *DeadLetterEvent, status DeadLetterStatus) error {
+	resolvedAt := time.Now()
+	if err := s.repository.Resolve(ctx, event.ID, event.Status, status, resolvedAt); err != nil {
+		return fmt.Errorf("erro ao atualizar evento %s da dead-letter queue: %w", event.ID, err)
+	}
+
+	event.Status = status
+	event.ResolvedAt = &resolvedAt
+	return nil
+}
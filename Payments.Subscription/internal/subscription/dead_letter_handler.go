@@ -0,0 +1,105 @@
+package subscription
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/common/problem"
+
+	"github.com/gorilla/mux"
+)
+
+// DeadLetterHandler expõe as rotas administrativas da dead-letter queue
+type DeadLetterHandler struct {
+	service *DeadLetterService
+}
+
+// NewDeadLetterHandler cria uma nova instância do DeadLetterHandler
+func NewDeadLetterHandler(service *DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas da dead-letter queue no subrouter administrativo (/admin), que
+// aplica a autenticação
+func (h *DeadLetterHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/dead-letters", h.ListDeadLetters).Methods("GET")
+	router.HandleFunc("/dead-letters/{id}", h.GetDeadLetter).Methods("GET")
+	router.HandleFunc("/dead-letters/{id}/replay", h.ReplayDeadLetter).Methods("POST")
+	router.HandleFunc("/dead-letters/{id}/discard", h.DiscardDeadLetter).Methods("POST")
+}
+
+// ListDeadLetters handler para listar a dead-letter queue filtrando por tipo, agregado ou correlation ID
+func (h *DeadLetterHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	filter, fieldErrors := ParseDeadLetterFilter(r.URL.Query())
+	if len(fieldErrors) > 0 {
+		p := problem.New(http.StatusBadRequest, ErrorCodeValidationFailed, "The request contains invalid fields")
+		problem.Write(w, r, p.WithErrors(fieldErrors))
+		return
+	}
+
+	events, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to retrieve dead-lettered events")
+		return
+	}
+
+	h.writeResponse(w, r, events, "")
+}
+
+// GetDeadLetter handler para inspecionar um evento da dead-letter queue
+func (h *DeadLetterHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	event, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to retrieve dead-lettered event")
+		return
+	}
+
+	h.writeResponse(w, r, event, "")
+}
+
+// ReplayDeadLetter handler para reprocessar um evento da dead-letter queue
+func (h *DeadLetterHandler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	event, err := h.service.Replay(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to replay dead-lettered event")
+		return
+	}
+
+	h.writeResponse(w, r, event, "Dead-lettered event replayed successfully")
+}
+
+// DiscardDeadLetter handler para descartar um evento da dead-letter queue
+func (h *DeadLetterHandler) DiscardDeadLetter(w http.ResponseWriter, r *http.Request) {
+	event, err := h.service.Discard(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to discard dead-lettered event")
+		return
+	}
+
+	h.writeResponse(w, r, event, "Dead-lettered event discarded successfully")
+}
+
+// writeServiceError escreve a resposta problem+json de uma chamada ao serviço, classificando o erro
+func (h *DeadLetterHandler) writeServiceError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	classification := classifyError(err)
+	detail := fmt.Sprintf("%s: %s", message, classification.detail)
+	problem.Write(w, r, problem.New(classification.statusCode, classification.code, detail))
+}
+
+// writeResponse escreve uma resposta de sucesso padronizada
+func (h *DeadLetterHandler) writeResponse(w http.ResponseWriter, r *http.Request, data interface{}, message string) {
+	correlationID := logging.GetCorrelationID(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Correlation-ID", correlationID)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{
+		Data:          data,
+		Message:       message,
+		CorrelationID: correlationID,
+	})
+}
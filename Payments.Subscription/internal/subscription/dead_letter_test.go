@@ -0,0 +1,192 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryDeadLetterRepository guarda os eventos da dead-letter queue em memória
+type memoryDeadLetterRepository struct {
+	events map[string]*DeadLetterEvent
+}
+
+func (r *memoryDeadLetterRepository) Create(_ context.Context, event *DeadLetterEvent) error {
+	r.events[event.ID] = event
+	return nil
+}
+
+func (r *memoryDeadLetterRepository) GetByID(_ context.Context, id string) (*DeadLetterEvent, error) {
+	event, ok := r.events[id]
+	if !ok {
+		return nil, ErrDeadLetterNotFound
+	}
+	return event, nil
+}
+
+func (r *memoryDeadLetterRepository) List(context.Context, DeadLetterFilter) ([]*DeadLetterEvent, error) {
+	return nil, nil
+}
+
+func (r *memoryDeadLetterRepository) ClaimReplay(ctx context.Context, id string, _ time.Time) error {
+	return r.Resolve(ctx, id, DeadLetterStatusPending, DeadLetterStatusReplaying, time.Time{})
+}
+
+func (r *memoryDeadLetterRepository) Resolve(_ context.Context, id string, from, to DeadLetterStatus, _ time.Time) error {
+	event, ok := r.events[id]
+	if !ok {
+		return ErrDeadLetterNotFound
+	}
+	if event.Status != from {
+		return ErrDeadLetterNotPending
+	}
+	event.Status = to
+	return nil
+}
+
+func (r *memoryDeadLetterRepository) RecordReplayFailure(_ context.Context, id string, _ error) error {
+	event := r.events[id]
+	event.ReplayAttempts++
+	event.Status = DeadLetterStatusPending
+	return nil
+}
+
+// capturingPublisher guarda os eventos publicados; onPublish, quando informado, roda durante a publicação
+type capturingPublisher struct {
+	published []DomainEvent
+	onPublish func() error
+}
+
+func (p *capturingPublisher) Publish(_ context.Context, event DomainEvent) error {
+	if p.onPublish != nil {
+		if err := p.onPublish(); err != nil {
+			return err
+		}
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func newPublishDeadLetter(t *testing.T) *DeadLetterEvent {
+	t.Helper()
+	message := &OutboxMessage{ID: "outbox-1", EventID: "event-1", AggregateID: "sub-1", EventType: "SubscriptionActivated", Payload: []byte(`{}`), OccurredAt: time.Now()}
+	deadLetter, err := NewDeadLetterEvent(context.Background(), DeadLetterSourcePublish, NewOutboxEvent(message), "", 5, errors.New("broker indisponível"))
+	if err != nil {
+		t.Fatalf("NewDeadLetterEvent() error = %v", err)
+	}
+	return deadLetter
+}
+
+func TestDeadLetterServiceReplayClaimsTheEvent(t *testing.T) {
+	ctx := context.Background()
+	deadLetter := newPublishDeadLetter(t)
+	repository := &memoryDeadLetterRepository{events: map[string]*DeadLetterEvent{deadLetter.ID: deadLetter}}
+	publisher := &capturingPublisher{}
+	service := NewDeadLetterService(repository, publisher, nil)
+
+	// Um segundo reprocessamento que chega durante a publicação encontra o evento reservado
+	var concurrentErr error
+	publisher.onPublish = func() error {
+		publisher.onPublish = nil
+		_, concurrentErr = service.Replay(ctx, deadLetter.ID)
+		return nil
+	}
+
+	if _, err := service.Replay(ctx, deadLetter.ID); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if !errors.Is(concurrentErr, ErrDeadLetterNotPending) {
+		t.Errorf("concurrent Replay() error = %v, want %v", concurrentErr, ErrDeadLetterNotPending)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("published = %d events, want 1", len(publisher.published))
+	}
+	if deadLetter.Status != DeadLetterStatusReplayed {
+		t.Errorf("status = %s, want %s", deadLetter.Status, DeadLetterStatusReplayed)
+	}
+}
+
+func TestDeadLetterServiceReplayFailureReleasesTheClaim(t *testing.T) {
+	ctx := context.Background()
+	deadLetter := newPublishDeadLetter(t)
+	repository := &memoryDeadLetterRepository{events: map[string]*DeadLetterEvent{deadLetter.ID: deadLetter}}
+	publisher := &capturingPublisher{onPublish: func() error { return errors.New("broker indisponível") }}
+	service := NewDeadLetterService(repository, publisher, nil)
+
+	if _, err := service.Replay(ctx, deadLetter.ID); err == nil {
+		t.Fatal("Replay() error = nil, want publish failure")
+	}
+	if deadLetter.Status != DeadLetterStatusPending || deadLetter.ReplayAttempts != 1 {
+		t.Errorf("status = %s, replay attempts = %d, want %s and 1", deadLetter.Status, deadLetter.ReplayAttempts, DeadLetterStatusPending)
+	}
+
+	// Com a reserva liberada, o evento pode ser reprocessado de novo
+	publisher.onPublish = nil
+	if _, err := service.Replay(ctx, deadLetter.ID); err != nil {
+		t.Fatalf("second Replay() error = %v", err)
+	}
+	if deadLetter.Status != DeadLetterStatusReplayed {
+		t.Errorf("status = %s, want %s", deadLetter.Status, DeadLetterStatusReplayed)
+	}
+}
+
+func TestDeadLetterServiceReplayKeepsOriginalEventID(t *testing.T) {
+	tests := []struct {
+		name        string
+		message     *OutboxMessage
+		wantEventID string
+	}{
+		{
+			name:        "mensagem com ID de evento",
+			message:     &OutboxMessage{ID: "outbox-1", EventID: "event-1"},
+			wantEventID: "event-1",
+		},
+		{
+			name:        "mensagem sem ID de evento usa o ID da mensagem do outbox",
+			message:     &OutboxMessage{ID: "outbox-2"},
+			wantEventID: "outbox-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tt.message.AggregateID = "sub-1"
+			tt.message.EventType = "SubscriptionActivated"
+			tt.message.Payload = []byte(`{"subscription_id":"sub-1"}`)
+			tt.message.OccurredAt = time.Now()
+
+			deadLetter, err := NewDeadLetterEvent(ctx, DeadLetterSourcePublish, NewOutboxEvent(tt.message), "", 5, errors.New("broker indisponível"))
+			if err != nil {
+				t.Fatalf("NewDeadLetterEvent() error = %v", err)
+			}
+			if deadLetter.ID == tt.wantEventID {
+				t.Fatalf("dead-letter ID %q must differ from the event ID", deadLetter.ID)
+			}
+
+			repository := &memoryDeadLetterRepository{events: map[string]*DeadLetterEvent{deadLetter.ID: deadLetter}}
+			publisher := &capturingPublisher{}
+			service := NewDeadLetterService(repository, publisher, nil)
+
+			replayed, err := service.Replay(ctx, deadLetter.ID)
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if replayed.Status != DeadLetterStatusReplayed {
+				t.Errorf("status = %s, want %s", replayed.Status, DeadLetterStatusReplayed)
+			}
+
+			if len(publisher.published) != 1 {
+				t.Fatalf("published = %d events, want 1", len(publisher.published))
+			}
+			event := publisher.published[0]
+			if event.EventID() != tt.wantEventID {
+				t.Errorf("EventID = %q, want %q", event.EventID(), tt.wantEventID)
+			}
+			if event.EventType() != tt.message.EventType || event.AggregateID() != tt.message.AggregateID {
+				t.Errorf("event = %s/%s, want %s/%s", event.EventType(), event.AggregateID(), tt.message.EventType, tt.message.AggregateID)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"payments-subscription/internal/customer"
+	"payments-subscription/internal/plan"
+)
+
+// Códigos de erro devolvidos nas respostas problem+json para que os clientes possam tratar cada caso
+const (
+	ErrorCodeInvalidRequest             = "invalid_request"
+	ErrorCodeValidationFailed           = "validation_failed"
+	ErrorCodeSubscriptionNotFound       = "subscription_not_found"
+	ErrorCodePlanNotFound               = "plan_not_found"
+	ErrorCodePlanArchived               = "plan_archived"
+	ErrorCodePlanChangeNotAllowed       = "plan_change_not_allowed"
+	ErrorCodeInvalidStatusTransition    = "invalid_status_transition"
+	ErrorCodeConcurrentModification     = "concurrent_modification"
+	ErrorCodeCustomerAlreadyLinked      = "customer_already_linked"
+	ErrorCodeUnsupportedEvent           = "unsupported_event"
+	ErrorCodeDeadLetterNotFound         = "dead_letter_not_found"
+	ErrorCodeDeadLetterNotPending       = "dead_letter_not_pending"
+	ErrorCodeCustomerNotFound           = "customer_not_found"
+	ErrorCodeCustomerRejected           = "customer_rejected"
+	ErrorCodeCustomerServiceError       = "customer_service_error"
+	ErrorCodeCustomerServiceUnavailable = "customer_service_unavailable"
+	ErrorCodeTimeout                    = "timeout"
+	ErrorCodeInternal                   = "internal_error"
+)
+
+// errorClassification associa um erro ao status HTTP, ao código e à descrição pública da resposta,
+// evitando expor a mensagem interna do erro ao cliente
+type errorClassification struct {
+	statusCode int
+	code       string
+	detail     string
+}
+
+// errorClassifications é avaliada em ordem com errors.Is; o primeiro erro encontrado na cadeia decide
+var errorClassifications = []struct {
+	target         error
+	classification errorClassification
+}{
+	// Orçamento de tempo esgotado, seja da requisição, do banco ou do serviço de Customer
+	{context.DeadlineExceeded, errorClassification{http.StatusGatewayTimeout, ErrorCodeTimeout, "the operation did not complete in time"}},
+
+	// Validação dos dados da requisição
+	{ErrInvalidSubscriptionID, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the subscription ID is invalid"}},
+	{ErrInvalidCustomerID, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the customer ID is invalid"}},
+	{ErrInvalidPlanID, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the plan ID is invalid"}},
+	{ErrInvalidTrialDays, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the number of trial days is invalid"}},
+	{ErrInvalidPaymentMethodID, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the payment method ID is invalid"}},
+	{ErrInvalidPlanChangeTiming, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the plan change timing is invalid"}},
+	{ErrInvalidCursor, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the pagination cursor is invalid"}},
+	{ErrInvalidSortOrder, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the sort order is invalid"}},
+	{ErrInvalidPageSize, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the page size is invalid"}},
+	{ErrInvalidStatusFilter, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the status filter is invalid"}},
+	{ErrInvalidDateRange, errorClassification{http.StatusBadRequest, ErrorCodeValidationFailed, "the created date range is invalid"}},
+
+	// Recursos inexistentes
+	{ErrSubscriptionNotFound, errorClassification{http.StatusNotFound, ErrorCodeSubscriptionNotFound, "the subscription was not found"}},
+	{ErrDeadLetterNotFound, errorClassification{http.StatusNotFound, ErrorCodeDeadLetterNotFound, "the dead-lettered event was not found"}},
+
+	// Conflitos de estado
+	{ErrConcurrentModification, errorClassification{http.StatusConflict, ErrorCodeConcurrentModification, "the subscription was modified by another request, reload it and try again"}},
+	{ErrInvalidStatusTransition, errorClassification{http.StatusConflict, ErrorCodeInvalidStatusTransition, "the subscription status does not allow this operation"}},
+	{ErrPeriodNotEnded, errorClassification{http.StatusConflict, ErrorCodeInvalidStatusTransition, "the current billing period has not ended yet"}},
+	{ErrPeriodEnded, errorClassification{http.StatusConflict, ErrorCodeInvalidStatusTransition, "the current billing period has already ended"}},
+	{ErrCancellationAlreadyScheduled, errorClassification{http.StatusConflict, ErrorCodeInvalidStatusTransition, "a cancellation is already scheduled for this subscription"}},
+	{ErrNoScheduledCancellation, errorClassification{http.StatusConflict, ErrorCodeInvalidStatusTransition, "there is no scheduled cancellation for this subscription"}},
+	{ErrCustomerAlreadyLinked, errorClassification{http.StatusConflict, ErrorCodeCustomerAlreadyLinked, "the subscription is already linked to another customer"}},
+	{ErrDeadLetterNotPending, errorClassification{http.StatusConflict, ErrorCodeDeadLetterNotPending, "the dead-lettered event was already replayed or discarded, or is being replayed"}},
+
+	// Eventos recebidos de outros serviços
+	{ErrInvalidCloudEvent, errorClassification{http.StatusBadRequest, ErrorCodeInvalidRequest, "the CloudEvents envelope is invalid"}},
+	{ErrUnsupportedEvent, errorClassification{http.StatusUnprocessableEntity, ErrorCodeUnsupportedEvent, "the event type is not supported"}},
+
+	// Regras de negócio sobre o plano informado
+	{plan.ErrPlanNotFound, errorClassification{http.StatusUnprocessableEntity, ErrorCodePlanNotFound, "the requested plan does not exist"}},
+	{plan.ErrPlanArchived, errorClassification{http.StatusUnprocessableEntity, ErrorCodePlanArchived, "the requested plan is archived and does not accept new subscriptions"}},
+	{ErrInvalidBillingInterval, errorClassification{http.StatusUnprocessableEntity, ErrorCodeValidationFailed, "the plan billing interval is invalid"}},
+	{ErrSamePlan, errorClassification{http.StatusUnprocessableEntity, ErrorCodePlanChangeNotAllowed, "the subscription is already on the requested plan"}},
+	{ErrCurrencyMismatch, errorClassification{http.StatusUnprocessableEntity, ErrorCodePlanChangeNotAllowed, "the new plan must use the same currency as the current plan"}},
+	{ErrBillingIntervalChange, errorClassification{http.StatusUnprocessableEntity, ErrorCodePlanChangeNotAllowed, "a plan with a different billing interval can only take effect at the end of the current period"}},
+
+	// Falhas do serviço de Customer
+	{customer.ErrCircuitOpen, errorClassification{http.StatusServiceUnavailable, ErrorCodeCustomerServiceUnavailable, "the customer service is temporarily unavailable, try again later"}},
+	{customer.ErrCustomerNotFound, errorClassification{http.StatusUnprocessableEntity, ErrorCodeCustomerNotFound, "the informed customer does not exist"}},
+	{customer.ErrCustomerRejected, errorClassification{http.StatusUnprocessableEntity, ErrorCodeCustomerRejected, "the customer service rejected the customer data"}},
+	{customer.ErrCustomerServiceUnavailable, errorClassification{http.StatusServiceUnavailable, ErrorCodeCustomerServiceUnavailable, "the customer service is temporarily unavailable"}},
+	{customer.ErrCustomerServiceFailure, errorClassification{http.StatusBadGateway, ErrorCodeCustomerServiceError, "the customer service failed to process the request"}},
+}
+
+// classifyError traduz um erro da camada de serviço no status HTTP, código e descrição da resposta
+func classifyError(err error) errorClassification {
+	for _, candidate := range errorClassifications {
+		if errors.Is(err, candidate.target) {
+			return candidate.classification
+		}
+	}
+
+	return errorClassification{http.StatusInternalServerError, ErrorCodeInternal, "an unexpected error occurred"}
+}
@@ -0,0 +1,321 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrEventPoisoned indica que o evento esgotou as tentativas (ou não pode ser tratado) e foi entregue
+// ao PoisonHandler; quem recebeu a mensagem deve confirmá-la em vez de pedir nova entrega
+var ErrEventPoisoned = errors.New("evento encaminhado ao tratamento de mensagens envenenadas")
+
+// DispatchRetryPolicy decide se a falha de um handler deve ser tentada novamente e após quanto tempo
+type DispatchRetryPolicy interface {
+	// NextDelay recebe o número da tentativa que falhou (a partir de 1) e o erro retornado
+	NextDelay(attempt int, err error) (time.Duration, bool)
+}
+
+// ExponentialRetryPolicy repete falhas transitórias com backoff exponencial e full jitter
+type ExponentialRetryPolicy struct {
+	// MaxAttempts é o total de tentativas, incluindo a primeira
+	MaxAttempts int
+	// BaseDelay é a espera antes da segunda tentativa, dobrada a cada nova tentativa
+	BaseDelay time.Duration
+	// MaxDelay limita a espera entre tentativas
+	MaxDelay time.Duration
+}
+
+// permanentDispatchErrors são erros que se repetiriam em qualquer nova tentativa
+var permanentDispatchErrors = []error{
+	ErrUnsupportedEvent,
+	ErrInvalidSubscriptionID,
+	ErrInvalidCustomerID,
+	ErrInvalidStatusTransition,
+	ErrCustomerAlreadyLinked,
+}
+
+// NextDelay implementa DispatchRetryPolicy
+func (p ExponentialRetryPolicy) NextDelay(attempt int, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	for _, permanent := range permanentDispatchErrors {
+		if errors.Is(err, permanent) {
+			return 0, false
+		}
+	}
+
+	backoff := p.MaxDelay
+	if shift := attempt - 1; shift < 32 {
+		backoff = min(p.BaseDelay<<shift, p.MaxDelay)
+	}
+	if backoff <= 0 {
+		return 0, true
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1)), true
+}
+
+// PoisonHandler recebe os eventos que nenhum handler conseguiu processar
+type PoisonHandler interface {
+	// HandlePoison recebe o handler que falhou (vazio quando nenhum handler trata o evento),
+	// o número de tentativas feitas e o último erro
+	HandlePoison(ctx context.Context, event DomainEvent, handlerName string, attempts int, cause error) error
+}
+
+// LoggingPoisonHandler apenas registra o evento envenenado no log
+type LoggingPoisonHandler struct {
+	logger *logging.StructuredLogger
+}
+
+// NewLoggingPoisonHandler cria uma nova instância do LoggingPoisonHandler
+func NewLoggingPoisonHandler() *LoggingPoisonHandler {
+	return &LoggingPoisonHandler{
+		logger: logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// HandlePoison implementa PoisonHandler
+func (h *LoggingPoisonHandler) HandlePoison(ctx context.Context, event DomainEvent, handlerName string, attempts int, cause error) error {
+	h.logger.Error(ctx, "EventDispatcher", "Evento descartado após esgotar as tentativas", cause, map[string]interface{}{
+		"event_type":   event.EventType(),
+		"aggregate_id": event.AggregateID(),
+		"handler":      handlerName,
+		"attempts":     attempts,
+	})
+	return nil
+}
+
+// EventDispatcher roteia eventos recebidos para os EventHandler registrados. Cada handler é executado
+// com as tentativas da política de retry; ao esgotá-las o evento vai para o PoisonHandler. A entrega é
+// at-least-once: o evento só deve ser confirmado na origem quando Dispatch não retornar erro ou quando
+// o erro for ErrEventPoisoned, então os handlers precisam ser idempotentes
+type EventDispatcher struct {
+	mu          sync.RWMutex
+	handlers    []EventHandler
+	retryPolicy DispatchRetryPolicy
+	poison      PoisonHandler
+	slots       chan struct{}
+	inFlight    sync.WaitGroup
+	tracer      trace.Tracer
+	logger      *logging.StructuredLogger
+}
+
+// NewEventDispatcher cria um dispatcher que executa no máximo concurrency eventos ao mesmo tempo
+func NewEventDispatcher(tracer trace.Tracer, concurrency int, retryPolicy DispatchRetryPolicy, poison PoisonHandler) *EventDispatcher {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if poison == nil {
+		poison = NewLoggingPoisonHandler()
+	}
+
+	return &EventDispatcher{
+		retryPolicy: retryPolicy,
+		poison:      poison,
+		slots:       make(chan struct{}, concurrency),
+		tracer:      tracer,
+		logger:      logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// Register adiciona um handler; um evento é entregue a todos os handlers que declaram tratá-lo
+func (d *EventDispatcher) Register(handlers ...EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, handlers...)
+}
+
+// CanDispatch indica se algum handler registrado trata o tipo de evento
+func (d *EventDispatcher) CanDispatch(eventType string) bool {
+	return len(d.handlersFor(eventType)) > 0
+}
+
+// Dispatch processa o evento de forma síncrona, aguardando uma vaga de concorrência
+func (d *EventDispatcher) Dispatch(ctx context.Context, event DomainEvent) error {
+	if err := d.acquire(ctx); err != nil {
+		return err
+	}
+	defer d.release()
+
+	return d.dispatch(ctx, event, true)
+}
+
+// Replay processa o evento como Dispatch, mas devolve a falha ao chamador em vez de encaminhá-lo ao
+// PoisonHandler; usado no reprocessamento da dead-letter queue
+func (d *EventDispatcher) Replay(ctx context.Context, event DomainEvent) error {
+	if err := d.acquire(ctx); err != nil {
+		return err
+	}
+	defer d.release()
+
+	return d.dispatch(ctx, event, false)
+}
+
+// Submit aguarda uma vaga de concorrência e processa o evento em background, chamando done com o
+// resultado. Retorna erro apenas se o contexto for cancelado antes de conseguir a vaga
+func (d *EventDispatcher) Submit(ctx context.Context, event DomainEvent, done func(error)) error {
+	if err := d.acquire(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		defer d.release()
+		done(d.dispatch(ctx, event, true))
+	}()
+	return nil
+}
+
+// Wait aguarda os eventos em processamento, usado no encerramento do consumer
+func (d *EventDispatcher) Wait() {
+	d.inFlight.Wait()
+}
+
+// acquire reserva uma vaga de concorrência
+func (d *EventDispatcher) acquire(ctx context.Context) error {
+	select {
+	case d.slots <- struct{}{}:
+		d.inFlight.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release libera a vaga de concorrência
+func (d *EventDispatcher) release() {
+	<-d.slots
+	d.inFlight.Done()
+}
+
+// handlersFor lista os handlers registrados para o tipo de evento
+func (d *EventDispatcher) handlersFor(eventType string) []EventHandler {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var matched []EventHandler
+	for _, handler := range d.handlers {
+		if handler.CanHandle(eventType) {
+			matched = append(matched, handler)
+		}
+	}
+	return matched
+}
+
+// dispatch entrega o evento a cada handler registrado para o seu tipo; com poison desligado as falhas
+// definitivas são devolvidas ao chamador
+func (d *EventDispatcher) dispatch(ctx context.Context, event DomainEvent, poison bool) error {
+	handlers := d.handlersFor(event.EventType())
+	if len(handlers) == 0 {
+		cause := fmt.Errorf("%w: %s", ErrUnsupportedEvent, event.EventType())
+		if !poison {
+			return cause
+		}
+		return d.handlePoison(ctx, event, "", 0, cause)
+	}
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := d.runHandler(ctx, handler, event, poison); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runHandler executa um handler com as tentativas da política de retry, um span por tentativa
+func (d *EventDispatcher) runHandler(ctx context.Context, handler EventHandler, event DomainEvent, poison bool) error {
+	name := handlerName(handler)
+
+	for attempt := 1; ; attempt++ {
+		err := d.attempt(ctx, handler, name, event, attempt)
+		if err == nil {
+			return nil
+		}
+
+		// Cancelamento não é falha do handler: o evento volta para a origem e será entregue de novo
+		if ctx.Err() != nil {
+			return fmt.Errorf("processamento do evento %s interrompido: %w", event.EventType(), ctx.Err())
+		}
+
+		delay, retry := d.retryPolicy.NextDelay(attempt, err)
+		if !retry {
+			if !poison {
+				return fmt.Errorf("handler %s falhou após %d tentativas: %w", name, attempt, err)
+			}
+			return d.handlePoison(ctx, event, name, attempt, err)
+		}
+
+		d.logger.Info(ctx, "EventDispatcher", "Falha no handler, nova tentativa agendada", map[string]interface{}{
+			"event_type": event.EventType(),
+			"handler":    name,
+			"attempt":    attempt,
+			"delay_ms":   delay.Milliseconds(),
+			"error":      err.Error(),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("processamento do evento %s interrompido: %w", event.EventType(), ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt executa uma tentativa do handler dentro do seu próprio span
+func (d *EventDispatcher) attempt(ctx context.Context, handler EventHandler, name string, event DomainEvent, attempt int) error {
+	ctx, span := d.tracer.Start(ctx, "EventHandler."+name)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("event_type", event.EventType()),
+		attribute.String("aggregate_id", event.AggregateID()),
+		attribute.String("handler", name),
+		attribute.Int("attempt", attempt),
+	)
+
+	err := handler.Handle(ctx, event)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// handlePoison entrega o evento ao PoisonHandler. Se o PoisonHandler falhar, o erro é devolvido sem
+// ErrEventPoisoned para que a origem entregue o evento novamente
+func (d *EventDispatcher) handlePoison(ctx context.Context, event DomainEvent, handler string, attempts int, cause error) error {
+	if err := d.poison.HandlePoison(ctx, event, handler, attempts, cause); err != nil {
+		d.logger.Error(ctx, "EventDispatcher", "Erro ao tratar evento envenenado", err, map[string]interface{}{
+			"event_type": event.EventType(),
+			"handler":    handler,
+		})
+		return fmt.Errorf("erro ao tratar evento envenenado %s: %w", event.EventType(), err)
+	}
+
+	return fmt.Errorf("%w: %w", ErrEventPoisoned, cause)
+}
+
+// handlerName identifica o handler nos spans e logs pelo nome do tipo
+func handlerName(handler EventHandler) string {
+	name := fmt.Sprintf("%T", handler)
+	name = strings.TrimPrefix(name, "*")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
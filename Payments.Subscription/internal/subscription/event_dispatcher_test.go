@@ -0,0 +1,291 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var errTransient = errors.New("falha transitória")
+
+// scriptedHandler trata CustomerCreated devolvendo os erros configurados em sequência e nil depois
+type scriptedHandler struct {
+	mu     sync.Mutex
+	errs   []error
+	calls  int
+	cancel context.CancelFunc
+}
+
+func (h *scriptedHandler) CanHandle(eventType string) bool {
+	return eventType == CustomerCreatedEventType
+}
+
+func (h *scriptedHandler) Handle(context.Context, DomainEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.cancel != nil {
+		h.cancel()
+	}
+	if h.calls <= len(h.errs) {
+		return h.errs[h.calls-1]
+	}
+	return nil
+}
+
+type poisonCall struct {
+	eventType string
+	handler   string
+	attempts  int
+	cause     error
+}
+
+// fakePoisonHandler registra as chamadas e devolve o erro configurado
+type fakePoisonHandler struct {
+	mu    sync.Mutex
+	err   error
+	calls []poisonCall
+}
+
+func (p *fakePoisonHandler) HandlePoison(_ context.Context, event DomainEvent, handlerName string, attempts int, cause error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, poisonCall{eventType: event.EventType(), handler: handlerName, attempts: attempts, cause: cause})
+	return p.err
+}
+
+func repeatErr(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func TestExponentialRetryPolicyNextDelay(t *testing.T) {
+	policy := ExponentialRetryPolicy{MaxAttempts: 4, BaseDelay: 10 * time.Millisecond, MaxDelay: 25 * time.Millisecond}
+
+	tests := []struct {
+		name      string
+		policy    ExponentialRetryPolicy
+		attempt   int
+		err       error
+		wantRetry bool
+		// maxDelay é o teto do jitter para a tentativa
+		maxDelay time.Duration
+	}{
+		{"primeira falha espera até o delay base", policy, 1, errTransient, true, 10 * time.Millisecond},
+		{"segunda falha dobra o delay", policy, 2, errTransient, true, 20 * time.Millisecond},
+		{"delay limitado pelo máximo", policy, 3, errTransient, true, 25 * time.Millisecond},
+		{"para ao atingir o máximo de tentativas", policy, 4, errTransient, false, 0},
+		{"evento não suportado não é repetido", policy, 1, fmt.Errorf("%w: CustomerDeleted", ErrUnsupportedEvent), false, 0},
+		{"transição inválida não é repetida", policy, 1, fmt.Errorf("ativar: %w", ErrInvalidStatusTransition), false, 0},
+		{"customer já vinculado não é repetido", policy, 1, ErrCustomerAlreadyLinked, false, 0},
+		{"sem delay repete imediatamente", ExponentialRetryPolicy{MaxAttempts: 2}, 1, errTransient, true, 0},
+		{"deslocamento grande usa o máximo", ExponentialRetryPolicy{MaxAttempts: 100, BaseDelay: time.Second, MaxDelay: time.Minute}, 40, errTransient, true, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				delay, retry := tt.policy.NextDelay(tt.attempt, tt.err)
+				if retry != tt.wantRetry {
+					t.Fatalf("NextDelay(%d) retry = %v, want %v", tt.attempt, retry, tt.wantRetry)
+				}
+				if delay < 0 || delay > tt.maxDelay {
+					t.Fatalf("NextDelay(%d) delay = %s, want within [0, %s]", tt.attempt, delay, tt.maxDelay)
+				}
+			}
+		})
+	}
+}
+
+func TestEventDispatcherDispatch(t *testing.T) {
+	retryPolicy := ExponentialRetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	errDLQOffline := errors.New("dead-letter queue indisponível")
+
+	tests := []struct {
+		name      string
+		eventType string
+		errs      []error
+		poisonErr error
+
+		wantCalls int
+		wantErr   error
+		// wantPoisoned indica se o erro deve ser ErrEventPoisoned, liberando a confirmação na origem
+		wantPoisoned bool
+		wantPoison   *poisonCall
+	}{
+		{
+			name:      "sucesso na primeira tentativa",
+			eventType: CustomerCreatedEventType,
+			wantCalls: 1,
+		},
+		{
+			name:      "falha transitória é repetida até o sucesso",
+			eventType: CustomerCreatedEventType,
+			errs:      repeatErr(errTransient, 2),
+			wantCalls: 3,
+		},
+		{
+			name:         "esgotar as tentativas envia ao PoisonHandler",
+			eventType:    CustomerCreatedEventType,
+			errs:         repeatErr(errTransient, 3),
+			wantCalls:    3,
+			wantErr:      errTransient,
+			wantPoisoned: true,
+			wantPoison:   &poisonCall{eventType: CustomerCreatedEventType, handler: "scriptedHandler", attempts: 3, cause: errTransient},
+		},
+		{
+			name:         "erro permanente vai ao PoisonHandler sem repetir",
+			eventType:    CustomerCreatedEventType,
+			errs:         []error{ErrCustomerAlreadyLinked},
+			wantCalls:    1,
+			wantErr:      ErrCustomerAlreadyLinked,
+			wantPoisoned: true,
+			wantPoison:   &poisonCall{eventType: CustomerCreatedEventType, handler: "scriptedHandler", attempts: 1, cause: ErrCustomerAlreadyLinked},
+		},
+		{
+			name:         "evento sem handler vai ao PoisonHandler",
+			eventType:    CustomerVerifiedEventType,
+			wantErr:      ErrUnsupportedEvent,
+			wantPoisoned: true,
+			wantPoison:   &poisonCall{eventType: CustomerVerifiedEventType, handler: "", attempts: 0, cause: ErrUnsupportedEvent},
+		},
+		{
+			name:       "falha do PoisonHandler mantém o evento para nova entrega",
+			eventType:  CustomerCreatedEventType,
+			errs:       repeatErr(errTransient, 3),
+			poisonErr:  errDLQOffline,
+			wantCalls:  3,
+			wantErr:    errDLQOffline,
+			wantPoison: &poisonCall{eventType: CustomerCreatedEventType, handler: "scriptedHandler", attempts: 3, cause: errTransient},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &scriptedHandler{errs: tt.errs}
+			poison := &fakePoisonHandler{err: tt.poisonErr}
+			dispatcher := NewEventDispatcher(noop.NewTracerProvider().Tracer(""), 1, retryPolicy, poison)
+			dispatcher.Register(handler)
+
+			event := NewCustomerEvent(tt.eventType, "sub-1", "customer-1", "", "corr-1", time.Now())
+			err := dispatcher.Dispatch(context.Background(), event)
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Dispatch() error = %v, want nil", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Dispatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrEventPoisoned); got != tt.wantPoisoned {
+				t.Errorf("errors.Is(err, ErrEventPoisoned) = %v, want %v", got, tt.wantPoisoned)
+			}
+			if handler.calls != tt.wantCalls {
+				t.Errorf("handler calls = %d, want %d", handler.calls, tt.wantCalls)
+			}
+
+			if tt.wantPoison == nil {
+				if len(poison.calls) != 0 {
+					t.Errorf("poison calls = %+v, want none", poison.calls)
+				}
+				return
+			}
+			if len(poison.calls) != 1 {
+				t.Fatalf("poison calls = %+v, want 1", poison.calls)
+			}
+			got := poison.calls[0]
+			if got.eventType != tt.wantPoison.eventType || got.handler != tt.wantPoison.handler || got.attempts != tt.wantPoison.attempts {
+				t.Errorf("poison call = %+v, want %+v", got, *tt.wantPoison)
+			}
+			if !errors.Is(got.cause, tt.wantPoison.cause) {
+				t.Errorf("poison cause = %v, want %v", got.cause, tt.wantPoison.cause)
+			}
+		})
+	}
+}
+
+func TestEventDispatcherReplayReturnsFailureWithoutPoison(t *testing.T) {
+	handler := &scriptedHandler{errs: repeatErr(errTransient, 2)}
+	poison := &fakePoisonHandler{}
+	dispatcher := NewEventDispatcher(noop.NewTracerProvider().Tracer(""), 1, ExponentialRetryPolicy{MaxAttempts: 2}, poison)
+	dispatcher.Register(handler)
+
+	event := NewCustomerEvent(CustomerCreatedEventType, "sub-1", "customer-1", "", "corr-1", time.Now())
+	err := dispatcher.Replay(context.Background(), event)
+
+	if !errors.Is(err, errTransient) || errors.Is(err, ErrEventPoisoned) {
+		t.Errorf("Replay() error = %v, want %v without %v", err, errTransient, ErrEventPoisoned)
+	}
+	if handler.calls != 2 {
+		t.Errorf("handler calls = %d, want 2", handler.calls)
+	}
+	if len(poison.calls) != 0 {
+		t.Errorf("poison calls = %+v, want none", poison.calls)
+	}
+}
+
+func TestEventDispatcherCancellationIsNotPoison(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// O handler falha e cancela o contexto, como num encerramento durante o processamento
+	handler := &scriptedHandler{errs: repeatErr(errTransient, 5), cancel: cancel}
+	poison := &fakePoisonHandler{}
+	dispatcher := NewEventDispatcher(noop.NewTracerProvider().Tracer(""), 1, ExponentialRetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second}, poison)
+	dispatcher.Register(handler)
+
+	event := NewCustomerEvent(CustomerCreatedEventType, "sub-1", "customer-1", "", "corr-1", time.Now())
+	err := dispatcher.Dispatch(ctx, event)
+
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrEventPoisoned) {
+		t.Errorf("Dispatch() error = %v, want %v without %v", err, context.Canceled, ErrEventPoisoned)
+	}
+	if handler.calls != 1 {
+		t.Errorf("handler calls = %d, want 1", handler.calls)
+	}
+	if len(poison.calls) != 0 {
+		t.Errorf("poison calls = %+v, want none", poison.calls)
+	}
+}
+
+func TestEventDispatcherSubmitLimitsConcurrency(t *testing.T) {
+	dispatcher := NewEventDispatcher(noop.NewTracerProvider().Tracer(""), 1, ExponentialRetryPolicy{MaxAttempts: 1}, &fakePoisonHandler{})
+	block := make(chan struct{})
+	dispatcher.Register(blockingHandler(block))
+
+	event := NewCustomerEvent(CustomerCreatedEventType, "sub-1", "customer-1", "", "corr-1", time.Now())
+	done := make(chan error, 1)
+	if err := dispatcher.Submit(context.Background(), event, func(err error) { done <- err }); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	// Sem vaga livre, o segundo Submit desiste quando o contexto expira
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := dispatcher.Submit(ctx, event, func(error) {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second Submit() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(block)
+	if err := <-done; err != nil {
+		t.Errorf("done error = %v, want nil", err)
+	}
+	dispatcher.Wait()
+}
+
+// blockingHandler segura o processamento até o canal ser fechado
+type blockingHandler chan struct{}
+
+func (h blockingHandler) CanHandle(string) bool { return true }
+
+func (h blockingHandler) Handle(context.Context, DomainEvent) error {
+	<-h
+	return nil
+}
@@ -15,20 +15,27 @@ type EventPublisher interface {
 
 // InMemoryEventPublisher implementa um publisher simples em memória para demonstração
 type InMemoryEventPublisher struct {
+	source string
 	logger *logging.StructuredLogger
 }
 
-// NewInMemoryEventPublisher cria uma nova instância do publisher
-func NewInMemoryEventPublisher() *InMemoryEventPublisher {
+// NewInMemoryEventPublisher cria uma nova instância do publisher; source é o atributo source do envelope CloudEvents
+func NewInMemoryEventPublisher(source string) *InMemoryEventPublisher {
 	return &InMemoryEventPublisher{
+		source: source,
 		logger: logging.NewStructuredLogger("subscription-service"),
 	}
 }
 
 // Publish publica um evento (implementação simples para demonstração)
 func (p *InMemoryEventPublisher) Publish(ctx context.Context, event DomainEvent) error {
-	// Serializa o evento para JSON
-	eventData, err := json.Marshal(event)
+	// Serializa o evento no envelope CloudEvents (modo structured)
+	cloudEvent, err := NewCloudEvent(ctx, p.source, event)
+	if err != nil {
+		return err
+	}
+
+	eventData, err := json.Marshal(cloudEvent)
 	if err != nil {
 		return fmt.Errorf("erro ao serializar evento: %w", err)
 	}
@@ -37,6 +44,7 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event DomainEvent)
 	p.logger.Info(ctx, "EventPublished",
 		fmt.Sprintf("Event published: %s", event.EventType()),
 		map[string]interface{}{
+			"event_id":   event.EventID(),
 			"event_type": event.EventType(),
 			"event_data": string(eventData),
 		})
@@ -49,34 +57,3 @@ type EventHandler interface {
 	Handle(ctx context.Context, event DomainEvent) error
 	CanHandle(eventType string) bool
 }
-
-// SubscriptionEventService gerencia a publicação de eventos de subscription
-type SubscriptionEventService struct {
-	publisher EventPublisher
-}
-
-// NewSubscriptionEventService cria uma nova instância do serviço de eventos
-func NewSubscriptionEventService(publisher EventPublisher) *SubscriptionEventService {
-	return &SubscriptionEventService{
-		publisher: publisher,
-	}
-}
-
-// PublishSubscriptionEvents publica todos os eventos pendentes de uma subscription
-func (s *SubscriptionEventService) PublishSubscriptionEvents(ctx context.Context, subscription *Subscription) error {
-
-	events := subscription.Events()
-	if len(events) == 0 {
-		return nil
-	}
-
-	for _, event := range events {
-		if err := s.publisher.Publish(ctx, event); err != nil {
-			return fmt.Errorf("erro ao publicar evento %s: %w", event.EventType(), err)
-		}
-	}
-
-	// Limpa os eventos após publicação
-	subscription.ClearEvents()
-	return nil
-}
@@ -1,23 +1,22 @@
 package subscription
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 
 	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/common/problem"
+	"payments-subscription/internal/customer"
 
 	"github.com/gorilla/mux"
 )
 
-// ErrorResponse representa uma resposta de erro padronizada
-type ErrorResponse struct {
-	Error         string `json:"error"`
-	Message       string `json:"message"`
-	CorrelationID string `json:"correlation_id"`
-	StatusCode    int    `json:"status_code"`
-}
-
 // SuccessResponse representa uma resposta de sucesso padronizada
 type SuccessResponse struct {
 	Data          interface{} `json:"data"`
@@ -25,6 +24,13 @@ type SuccessResponse struct {
 	CorrelationID string      `json:"correlation_id"`
 }
 
+// PageResponse representa uma resposta paginada, com o cursor da próxima página
+type PageResponse struct {
+	Data          interface{} `json:"data"`
+	NextCursor    *string     `json:"next_cursor"`
+	CorrelationID string      `json:"correlation_id"`
+}
+
 // handler gerencia as requisições HTTP para Subscription
 type handler struct {
 	service SubscriptionServiceInterface
@@ -37,21 +43,34 @@ func NewSubscriptionHandler(service SubscriptionServiceInterface) *handler {
 	}
 }
 
-// writeErrorResponse escreve uma resposta de erro padronizada
-func (h *handler) writeErrorResponse(w http.ResponseWriter, r *http.Request, err error, statusCode int, message string) {
-	correlationID := logging.GetCorrelationID(r.Context())
+// writeServiceError escreve a resposta problem+json de uma chamada ao serviço, classificando o erro
+func (h *handler) writeServiceError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	classification := classifyError(err)
+	detail := fmt.Sprintf("%s: %s", message, classification.detail)
 
-	errorResponse := ErrorResponse{
-		Error:         err.Error(),
-		Message:       message,
-		CorrelationID: correlationID,
-		StatusCode:    statusCode,
+	// Com o circuit breaker aberto, informa ao cliente quando vale tentar novamente
+	var circuitOpen *customer.CircuitOpenError
+	if errors.As(err, &circuitOpen) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(circuitOpen.RetryAfter.Seconds()))))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Correlation-ID", correlationID)
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(errorResponse)
+	problem.Write(w, r, problem.New(classification.statusCode, classification.code, detail))
+}
+
+// writeInvalidJSON responde a um corpo de requisição que não pôde ser decodificado
+func (h *handler) writeInvalidJSON(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, ErrorCodeInvalidRequest, "Invalid JSON format"))
+}
+
+// writeMissingID responde a uma requisição sem o ID da subscription na rota
+func (h *handler) writeMissingID(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(http.StatusBadRequest, ErrorCodeValidationFailed, "Subscription ID is required"))
+}
+
+// writeValidationErrors responde com a lista de campos inválidos da requisição
+func (h *handler) writeValidationErrors(w http.ResponseWriter, r *http.Request, fieldErrors []problem.FieldError) {
+	p := problem.New(http.StatusBadRequest, ErrorCodeValidationFailed, "The request contains invalid fields")
+	problem.Write(w, r, p.WithErrors(fieldErrors))
 }
 
 // writeSuccessResponse escreve uma resposta de sucesso padronizada
@@ -70,17 +89,47 @@ func (h *handler) writeSuccessResponse(w http.ResponseWriter, r *http.Request, d
 	json.NewEncoder(w).Encode(successResponse)
 }
 
+// writePageResponse escreve uma página da listagem de subscriptions
+func (h *handler) writePageResponse(w http.ResponseWriter, r *http.Request, page *SubscriptionListResponse) {
+	correlationID := logging.GetCorrelationID(r.Context())
+
+	pageResponse := PageResponse{
+		Data:          page.Subscriptions,
+		CorrelationID: correlationID,
+	}
+	if page.NextCursor != "" {
+		pageResponse.NextCursor = &page.NextCursor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Correlation-ID", correlationID)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pageResponse)
+}
+
 // CreateSubscription handler para criar uma subscription
 func (h *handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	var req CreateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeErrorResponse(w, r, err, http.StatusBadRequest, "Invalid JSON format")
+		h.writeInvalidJSON(w, r)
+		return
+	}
+
+	if fieldErrors := ValidateCreateSubscriptionRequest(req); len(fieldErrors) > 0 {
+		h.writeValidationErrors(w, r, fieldErrors)
 		return
 	}
 
 	subscription, err := h.service.CreateSubscription(r.Context(), req)
 	if err != nil {
-		h.writeErrorResponse(w, r, err, http.StatusInternalServerError, "Failed to create subscription")
+		h.writeServiceError(w, r, err, "Failed to create subscription")
+		return
+	}
+
+	// No onboarding assíncrono a subscription fica pendente até o evento do serviço de Customer
+	if subscription.AwaitingCustomer {
+		w.Header().Set("Location", "/subscriptions/"+subscription.ID)
+		h.writeSuccessResponse(w, r, subscription, http.StatusAccepted, "Subscription requested, awaiting customer onboarding")
 		return
 	}
 
@@ -93,31 +142,58 @@ func (h *handler) GetSubscriptionByID(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	if id == "" {
-		h.writeErrorResponse(w, r,
-			fmt.Errorf("missing subscription ID"),
-			http.StatusBadRequest,
-			"Subscription ID is required")
+		h.writeMissingID(w, r)
 		return
 	}
 
 	subscription, err := h.service.GetSubscriptionByID(r.Context(), id)
 	if err != nil {
-		h.writeErrorResponse(w, r, err, http.StatusNotFound, "Subscription not found")
+		h.writeServiceError(w, r, err, "Failed to retrieve subscription")
 		return
 	}
 
 	h.writeSuccessResponse(w, r, subscription, http.StatusOK, "")
 }
 
-// GetAllSubscriptions handler para buscar todas as subscriptions
-func (h *handler) GetAllSubscriptions(w http.ResponseWriter, r *http.Request) {
-	subscriptions, err := h.service.GetAllSubscriptions(r.Context())
+// ListSubscriptions handler para listar subscriptions com paginação, filtros e ordenação
+func (h *handler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	query, fieldErrors := ParseListSubscriptionsQuery(r.URL.Query())
+	if len(fieldErrors) > 0 {
+		h.writeValidationErrors(w, r, fieldErrors)
+		return
+	}
+
+	page, err := h.service.ListSubscriptions(r.Context(), query)
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to retrieve subscriptions")
+		return
+	}
+
+	h.writePageResponse(w, r, page)
+}
+
+// ListCustomerSubscriptions handler para listar as subscriptions de um customer
+func (h *handler) ListCustomerSubscriptions(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["customerId"]
+
+	if customerID == "" {
+		problem.Write(w, r, problem.New(http.StatusBadRequest, ErrorCodeValidationFailed, "Customer ID is required"))
+		return
+	}
+
+	query, fieldErrors := ParseListSubscriptionsQuery(r.URL.Query())
+	if len(fieldErrors) > 0 {
+		h.writeValidationErrors(w, r, fieldErrors)
+		return
+	}
+
+	page, err := h.service.ListCustomerSubscriptions(r.Context(), customerID, query)
 	if err != nil {
-		h.writeErrorResponse(w, r, err, http.StatusInternalServerError, "Failed to retrieve subscriptions")
+		h.writeServiceError(w, r, err, "Failed to retrieve customer subscriptions")
 		return
 	}
 
-	h.writeSuccessResponse(w, r, subscriptions, http.StatusOK, "")
+	h.writePageResponse(w, r, page)
 }
 
 // ActivateSubscription handler para ativar uma subscription
@@ -126,10 +202,7 @@ func (h *handler) ActivateSubscription(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	if id == "" {
-		h.writeErrorResponse(w, r,
-			fmt.Errorf("missing subscription ID"),
-			http.StatusBadRequest,
-			"Subscription ID is required")
+		h.writeMissingID(w, r)
 		return
 	}
 
@@ -138,7 +211,7 @@ func (h *handler) ActivateSubscription(w http.ResponseWriter, r *http.Request) {
 
 	err := h.service.ActivateSubscription(r.Context(), id, correlationID)
 	if err != nil {
-		h.writeErrorResponse(w, r, err, http.StatusInternalServerError, "Failed to activate subscription")
+		h.writeServiceError(w, r, err, "Failed to activate subscription")
 		return
 	}
 
@@ -149,10 +222,154 @@ func (h *handler) ActivateSubscription(w http.ResponseWriter, r *http.Request) {
 	h.writeSuccessResponse(w, r, result, http.StatusOK, "Subscription activated successfully")
 }
 
+// CancelSubscription handler para cancelar uma subscription
+func (h *handler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, true, "cancel", "Subscription cancelled successfully", h.service.CancelSubscription)
+}
+
+// SuspendSubscription handler para suspender uma subscription
+func (h *handler) SuspendSubscription(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, true, "suspend", "Subscription suspended successfully", h.service.SuspendSubscription)
+}
+
+// ResumeSubscription handler para reativar uma subscription suspensa
+func (h *handler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, false, "resume", "Subscription resumed successfully", h.service.ResumeSubscription)
+}
+
+// ScheduleCancellation handler para agendar o cancelamento no fim do período
+func (h *handler) ScheduleCancellation(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, true, "schedule cancellation of", "Subscription cancellation scheduled successfully", h.service.ScheduleCancellation)
+}
+
+// UndoScheduledCancellation handler para desfazer o cancelamento agendado
+func (h *handler) UndoScheduledCancellation(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, false, "undo scheduled cancellation of", "Subscription scheduled cancellation undone successfully", h.service.UndoScheduledCancellation)
+}
+
+// AttachPaymentMethod handler para associar um meio de pagamento à subscription
+func (h *handler) AttachPaymentMethod(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.writeMissingID(w, r)
+		return
+	}
+
+	var req AttachPaymentMethodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeInvalidJSON(w, r)
+		return
+	}
+
+	if fieldErrors := ValidateAttachPaymentMethodRequest(req); len(fieldErrors) > 0 {
+		h.writeValidationErrors(w, r, fieldErrors)
+		return
+	}
+
+	// Tenta extrair correlation ID do header (opcional)
+	correlationID := r.Header.Get("X-Correlation-ID")
+
+	if err := h.service.AttachPaymentMethod(r.Context(), id, req.PaymentMethodID, correlationID); err != nil {
+		h.writeServiceError(w, r, err, "Failed to attach payment method")
+		return
+	}
+
+	result := map[string]string{
+		"message": "Payment method attached successfully",
+		"id":      id,
+	}
+	h.writeSuccessResponse(w, r, result, http.StatusOK, "Payment method attached successfully")
+}
+
+// ChangePlan handler para trocar o plano de uma subscription
+func (h *handler) ChangePlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.writeMissingID(w, r)
+		return
+	}
+
+	var req ChangePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeInvalidJSON(w, r)
+		return
+	}
+
+	if fieldErrors := ValidateChangePlanRequest(req); len(fieldErrors) > 0 {
+		h.writeValidationErrors(w, r, fieldErrors)
+		return
+	}
+
+	// Tenta extrair correlation ID do header (opcional)
+	correlationID := r.Header.Get("X-Correlation-ID")
+
+	result, err := h.service.ChangePlan(r.Context(), id, req, correlationID)
+	if err != nil {
+		h.writeServiceError(w, r, err, "Failed to change subscription plan")
+		return
+	}
+
+	h.writeSuccessResponse(w, r, result, http.StatusOK, "Subscription plan changed successfully")
+}
+
+// handleStatusChange trata as requisições de mudança de status que recebem um motivo no corpo
+func (h *handler) handleStatusChange(
+	w http.ResponseWriter,
+	r *http.Request,
+	reasonRequired bool,
+	action, successMessage string,
+	change func(ctx context.Context, id, reason, correlationID string) error,
+) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		h.writeMissingID(w, r)
+		return
+	}
+
+	var req ChangeStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.writeInvalidJSON(w, r)
+		return
+	}
+
+	if fieldErrors := ValidateChangeStatusRequest(req, reasonRequired); len(fieldErrors) > 0 {
+		h.writeValidationErrors(w, r, fieldErrors)
+		return
+	}
+
+	// Tenta extrair correlation ID do header (opcional)
+	correlationID := r.Header.Get("X-Correlation-ID")
+
+	if err := change(r.Context(), id, req.Reason, correlationID); err != nil {
+		h.writeServiceError(w, r, err, fmt.Sprintf("Failed to %s subscription", action))
+		return
+	}
+
+	result := map[string]string{
+		"message": successMessage,
+		"id":      id,
+	}
+	h.writeSuccessResponse(w, r, result, http.StatusOK, successMessage)
+}
+
 // RegisterRoutes registra as rotas da subscription
 func (h *handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/subscriptions", h.CreateSubscription).Methods("POST")
-	router.HandleFunc("/subscriptions", h.GetAllSubscriptions).Methods("GET")
+	router.HandleFunc("/subscriptions", h.ListSubscriptions).Methods("GET")
 	router.HandleFunc("/subscriptions/{id}", h.GetSubscriptionByID).Methods("GET")
+	router.HandleFunc("/customers/{customerId}/subscriptions", h.ListCustomerSubscriptions).Methods("GET")
 	router.HandleFunc("/subscriptions/{id}/activate", h.ActivateSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/cancel", h.CancelSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/suspend", h.SuspendSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/resume", h.ResumeSubscription).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/payment-method", h.AttachPaymentMethod).Methods("PUT")
+	router.HandleFunc("/subscriptions/{id}/change-plan", h.ChangePlan).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/cancel-at-period-end", h.ScheduleCancellation).Methods("POST")
+	router.HandleFunc("/subscriptions/{id}/cancel-at-period-end", h.UndoScheduledCancellation).Methods("DELETE")
 }
@@ -0,0 +1,115 @@
+package subscription
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/common/problem"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// InboundEventRequest representa um evento entregue por outro serviço via HTTP
+type InboundEventRequest struct {
+	Type           string    `json:"type"`
+	SubscriptionID string    `json:"subscription_id"`
+	CustomerID     string    `json:"customer_id"`
+	Email          string    `json:"email,omitempty"`
+	CorrelationID  string    `json:"correlation_id,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// ToDomainEvent converte a requisição no evento correspondente ao tipo informado
+func (r InboundEventRequest) ToDomainEvent(correlationID string) (DomainEvent, error) {
+	if r.CorrelationID != "" {
+		correlationID = r.CorrelationID
+	}
+
+	occurredAt := r.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	switch r.Type {
+	case CustomerCreatedEventType, CustomerVerifiedEventType:
+		return NewCustomerEvent(r.Type, r.SubscriptionID, r.CustomerID, r.Email, correlationID, occurredAt), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEvent, r.Type)
+	}
+}
+
+// InboundEventHandler recebe eventos de outros serviços via HTTP e os entrega ao EventDispatcher
+type InboundEventHandler struct {
+	dispatcher *EventDispatcher
+}
+
+// NewInboundEventHandler cria uma nova instância do receptor de eventos
+func NewInboundEventHandler(dispatcher *EventDispatcher) *InboundEventHandler {
+	return &InboundEventHandler{
+		dispatcher: dispatcher,
+	}
+}
+
+// ReceiveEvent handler para receber um evento e processá-lo de forma síncrona. Aceita CloudEvents
+// nos modos structured e binary, além do formato InboundEventRequest. Responde 204 quando o evento é
+// processado e 202 quando ele é encaminhado ao tratamento de mensagens envenenadas
+func (h *InboundEventHandler) ReceiveEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		problem.Write(w, r, problem.New(http.StatusBadRequest, ErrorCodeInvalidRequest, "Failed to read request body"))
+		return
+	}
+
+	var event DomainEvent
+	cloudEvent, err := HTTPBinding.Decode(propagation.HeaderCarrier(r.Header), body)
+	switch {
+	case err == nil:
+		event, err = DecodeCloudEvent(cloudEvent)
+	case errors.Is(err, ErrNotCloudEvent):
+		var req InboundEventRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			problem.Write(w, r, problem.New(http.StatusBadRequest, ErrorCodeInvalidRequest, "Invalid JSON format"))
+			return
+		}
+
+		if fieldErrors := ValidateInboundEventRequest(req); len(fieldErrors) > 0 {
+			p := problem.New(http.StatusBadRequest, ErrorCodeValidationFailed, "The event contains invalid fields")
+			problem.Write(w, r, p.WithErrors(fieldErrors))
+			return
+		}
+
+		event, err = req.ToDomainEvent(logging.GetCorrelationID(ctx))
+	}
+	if err == nil {
+		err = h.dispatcher.Dispatch(ctx, event)
+	}
+	// O evento envenenado já foi gravado pelo PoisonHandler: responde 202 para que a origem não reenvie,
+	// assim como o consumer do NATS confirma a mensagem
+	if errors.Is(err, ErrEventPoisoned) {
+		w.Header().Set("X-Correlation-ID", logging.GetCorrelationID(ctx))
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err != nil {
+		classification := classifyError(err)
+		detail := fmt.Sprintf("Failed to process event: %s", classification.detail)
+		problem.Write(w, r, problem.New(classification.statusCode, classification.code, detail))
+		return
+	}
+
+	w.Header().Set("X-Correlation-ID", logging.GetCorrelationID(ctx))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegisterRoutes registra a rota de recebimento de eventos
+func (h *InboundEventHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/internal/events", h.ReceiveEvent).Methods("POST")
+}
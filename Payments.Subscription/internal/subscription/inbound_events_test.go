@@ -0,0 +1,48 @@
+package subscription
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestInboundEventHandlerReceiveEvent(t *testing.T) {
+	body := `{"type":"CustomerCreated","subscription_id":"sub-1","customer_id":"customer-1"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		handlerErr error
+		poisonErr  error
+		wantStatus int
+	}{
+		{"evento processado", body, nil, nil, http.StatusNoContent},
+		{"evento envenenado é aceito para não ser reenviado", body, ErrCustomerAlreadyLinked, nil, http.StatusAccepted},
+		{"falha da dead-letter queue devolve erro para nova entrega", body, ErrCustomerAlreadyLinked, errors.New("dead-letter queue indisponível"), http.StatusInternalServerError},
+		{"tipo desconhecido é rejeitado", `{"type":"CustomerDeleted","subscription_id":"sub-1","customer_id":"customer-1"}`, nil, nil, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &scriptedHandler{}
+			if tt.handlerErr != nil {
+				handler.errs = []error{tt.handlerErr}
+			}
+			dispatcher := NewEventDispatcher(noop.NewTracerProvider().Tracer(""), 1, ExponentialRetryPolicy{MaxAttempts: 1}, &fakePoisonHandler{err: tt.poisonErr})
+			dispatcher.Register(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/internal/events", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			NewInboundEventHandler(dispatcher).ReceiveEvent(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// headerCarrier adapta os cabeçalhos de um kgo.Record ao propagation.TextMapCarrier do OpenTelemetry
+type headerCarrier kgo.Record
+
+// Get retorna o valor do primeiro cabeçalho com a chave informada
+func (c *headerCarrier) Get(key string) string {
+	for _, header := range c.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set substitui o cabeçalho com a chave informada, ou o adiciona se ainda não existir
+func (c *headerCarrier) Set(key, value string) {
+	for i, header := range c.Headers {
+		if header.Key == key {
+			c.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.Headers = append(c.Headers, kgo.RecordHeader{Key: key, Value: []byte(value)})
+}
+
+// Keys lista as chaves dos cabeçalhos do record
+func (c *headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Headers))
+	for _, header := range c.Headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
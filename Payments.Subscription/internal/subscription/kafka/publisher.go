@@ -0,0 +1,179 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/subscription"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Cabeçalhos gravados em cada mensagem além do contexto de trace (traceparent/tracestate)
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	EventTypeHeader     = "event-type"
+)
+
+// ErrNoBrokers indica que nenhum broker foi configurado para o publisher
+var ErrNoBrokers = errors.New("nenhum broker Kafka configurado")
+
+// Config reúne as configurações do publisher Kafka
+type Config struct {
+	Brokers    []string
+	Topic      string
+	ClientID   string
+	AckTimeout time.Duration
+	// Source é o atributo source dos envelopes CloudEvents publicados
+	Source string
+	// Mode define se o envelope vai inteiro no valor (structured) ou nos cabeçalhos ce_ (binary)
+	Mode subscription.CloudEventMode
+}
+
+// EventPublisher publica eventos de domínio em um tópico Kafka. As mensagens usam o AggregateID
+// como chave, então todos os eventos de uma subscription caem na mesma partição e mantêm a ordem
+type EventPublisher struct {
+	client     *kgo.Client
+	topic      string
+	ackTimeout time.Duration
+	source     string
+	mode       subscription.CloudEventMode
+	tracer     trace.Tracer
+	logger     *logging.StructuredLogger
+}
+
+// NewEventPublisher cria o cliente Kafka do publisher. O produce aguarda a confirmação de todas as
+// réplicas em sincronia (acks=all) e usa o particionador por chave compatível com o cliente Java
+func NewEventPublisher(cfg Config, tracer trace.Tracer) (*EventPublisher, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, ErrNoBrokers
+	}
+
+	options := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.DefaultProduceTopic(cfg.Topic),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordPartitioner(kgo.StickyKeyPartitioner(nil)),
+		kgo.ProduceRequestTimeout(cfg.AckTimeout),
+	}
+	if cfg.ClientID != "" {
+		options = append(options, kgo.ClientID(cfg.ClientID))
+	}
+
+	client, err := kgo.NewClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar cliente Kafka: %w", err)
+	}
+
+	return &EventPublisher{
+		client:     client,
+		topic:      cfg.Topic,
+		ackTimeout: cfg.AckTimeout,
+		source:     cfg.Source,
+		mode:       cfg.Mode,
+		tracer:     tracer,
+		logger:     logging.NewStructuredLogger("subscription-service"),
+	}, nil
+}
+
+// Publish envia o evento e aguarda a confirmação do broker dentro do tempo de ack configurado
+func (p *EventPublisher) Publish(ctx context.Context, event subscription.DomainEvent) (err error) {
+	ctx, span := p.tracer.Start(ctx, "Kafka.Publish "+p.topic, trace.WithSpanKind(trace.SpanKindProducer))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	span.SetAttributes(
+		attribute.String("messaging.system", "kafka"),
+		attribute.String("messaging.destination.name", p.topic),
+		attribute.String("messaging.kafka.message.key", event.AggregateID()),
+		attribute.String("event_type", event.EventType()),
+	)
+
+	cloudEvent, err := subscription.NewCloudEvent(ctx, p.source, event)
+	if err != nil {
+		return err
+	}
+
+	record := &kgo.Record{
+		Topic: p.topic,
+		Key:   []byte(event.AggregateID()),
+		Headers: []kgo.RecordHeader{
+			{Key: EventTypeHeader, Value: []byte(event.EventType())},
+		},
+		Timestamp: event.OccurredAt(),
+	}
+
+	record.Value, err = subscription.KafkaBinding.Encode(cloudEvent, p.mode, (*headerCarrier)(record))
+	if err != nil {
+		return err
+	}
+
+	correlationID := event.CorrelationID()
+	if correlationID == "" {
+		correlationID = logging.GetCorrelationID(ctx)
+	}
+	if correlationID != "" {
+		record.Headers = append(record.Headers, kgo.RecordHeader{Key: CorrelationIDHeader, Value: []byte(correlationID)})
+	}
+
+	// Propaga o span do produce para que o consumidor continue o mesmo trace
+	otel.GetTextMapPropagator().Inject(ctx, (*headerCarrier)(record))
+
+	if p.ackTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.ackTimeout)
+		defer cancel()
+	}
+
+	// O ProduceSync ignora o contexto depois que o produce request foi enviado; a espera pelo ack é
+	// limitada aqui para que um broker sem resposta não prenda o relay. A mensagem pode ainda ser
+	// gravada depois do timeout e ser reenviada pelo outbox, como em qualquer entrega at-least-once
+	acked := make(chan error, 1)
+	p.client.Produce(ctx, record, func(_ *kgo.Record, err error) {
+		acked <- err
+	})
+
+	select {
+	case err = <-acked:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao publicar evento %s no Kafka: %w", event.EventType(), err)
+	}
+
+	span.SetAttributes(
+		attribute.Int("messaging.kafka.destination.partition", int(record.Partition)),
+		attribute.Int64("messaging.kafka.message.offset", record.Offset),
+	)
+
+	p.logger.Info(ctx, "EventPublished",
+		fmt.Sprintf("Event published: %s", event.EventType()),
+		map[string]interface{}{
+			"event_id":     event.EventID(),
+			"event_type":   event.EventType(),
+			"aggregate_id": event.AggregateID(),
+			"topic":        p.topic,
+			"partition":    record.Partition,
+			"offset":       record.Offset,
+		})
+
+	return nil
+}
+
+// Close aguarda as mensagens em voo e encerra o cliente Kafka
+func (p *EventPublisher) Close() {
+	p.client.Close()
+}
@@ -0,0 +1,177 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/subscription"
+
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const testTopic = "subscription-events"
+
+// newTestCluster sobe um broker Kafka em memória com o tópico de eventos já criado
+func newTestCluster(t *testing.T) *kfake.Cluster {
+	t.Helper()
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(3, testTopic))
+	if err != nil {
+		t.Fatalf("kfake.NewCluster() error = %v", err)
+	}
+	t.Cleanup(cluster.Close)
+	return cluster
+}
+
+func newTestPublisher(t *testing.T, cluster *kfake.Cluster, mode subscription.CloudEventMode, ackTimeout time.Duration) *EventPublisher {
+	t.Helper()
+
+	// O traceparent só é gravado quando há um propagador e um span com contexto válido
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	tracer := sdktrace.NewTracerProvider().Tracer("kafka-publisher-test")
+
+	publisher, err := NewEventPublisher(Config{
+		Brokers:    cluster.ListenAddrs(),
+		Topic:      testTopic,
+		ClientID:   "payments-subscription-test",
+		AckTimeout: ackTimeout,
+		Source:     "/payments-subscription",
+		Mode:       mode,
+	}, tracer)
+	if err != nil {
+		t.Fatalf("NewEventPublisher() error = %v", err)
+	}
+	t.Cleanup(publisher.Close)
+	return publisher
+}
+
+// consumeOne lê o primeiro record publicado no tópico de eventos
+func consumeOne(t *testing.T, cluster *kfake.Cluster) *kgo.Record {
+	t.Helper()
+
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics(testTopic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		t.Fatalf("kgo.NewClient() error = %v", err)
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for {
+		fetches := consumer.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			t.Fatalf("nenhum record consumido: %v", err)
+		}
+		if errs := fetches.Errors(); len(errs) > 0 {
+			t.Fatalf("PollFetches() error = %v", errs[0].Err)
+		}
+		if records := fetches.Records(); len(records) > 0 {
+			return records[0]
+		}
+	}
+}
+
+func TestEventPublisherPublish(t *testing.T) {
+	tests := []struct {
+		name string
+		mode subscription.CloudEventMode
+		// wantHeaders são os cabeçalhos do envelope exigidos pelo modo, além dos comuns
+		wantHeaders []string
+	}{
+		{"modo structured", subscription.CloudEventModeStructured, []string{"content-type"}},
+		{"modo binary", subscription.CloudEventModeBinary, []string{"ce_id", "ce_type", "ce_specversion", "content-type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestCluster(t)
+			publisher := newTestPublisher(t, cluster, tt.mode, 5*time.Second)
+
+			event := subscription.NewCustomerEvent(subscription.CustomerCreatedEventType, "sub-1", "customer-1", "ana@example.com", "corr-1", time.Now())
+			if err := publisher.Publish(context.Background(), event); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+
+			record := consumeOne(t, cluster)
+			if got := string(record.Key); got != event.AggregateID() {
+				t.Errorf("key = %q, want %q", got, event.AggregateID())
+			}
+
+			headers := (*headerCarrier)(record)
+			if got := headers.Get(EventTypeHeader); got != event.EventType() {
+				t.Errorf("header %s = %q, want %q", EventTypeHeader, got, event.EventType())
+			}
+			if got := headers.Get(CorrelationIDHeader); got != "corr-1" {
+				t.Errorf("header %s = %q, want %q", CorrelationIDHeader, got, "corr-1")
+			}
+			if got := headers.Get("traceparent"); got == "" {
+				t.Error("header traceparent ausente")
+			}
+			for _, key := range tt.wantHeaders {
+				if headers.Get(key) == "" {
+					t.Errorf("header %s ausente", key)
+				}
+			}
+
+			cloudEvent, err := subscription.KafkaBinding.Decode(headers, record.Value)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if cloudEvent.ID != event.EventID() {
+				t.Errorf("envelope id = %q, want %q", cloudEvent.ID, event.EventID())
+			}
+		})
+	}
+}
+
+func TestEventPublisherPublishUsesContextCorrelationID(t *testing.T) {
+	cluster := newTestCluster(t)
+	publisher := newTestPublisher(t, cluster, subscription.CloudEventModeStructured, 5*time.Second)
+
+	event := subscription.NewCustomerEvent(subscription.CustomerCreatedEventType, "sub-1", "customer-1", "", "", time.Now())
+	ctx := logging.WithCorrelationID(context.Background(), "corr-from-context")
+	if err := publisher.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	record := consumeOne(t, cluster)
+	if got := (*headerCarrier)(record).Get(CorrelationIDHeader); got != "corr-from-context" {
+		t.Errorf("header %s = %q, want %q", CorrelationIDHeader, got, "corr-from-context")
+	}
+}
+
+func TestEventPublisherPublishAckTimeout(t *testing.T) {
+	cluster := newTestCluster(t)
+
+	// O broker recebe os produce requests mas nunca os confirma
+	cluster.ControlKey(int16(kmsg.Produce), func(kmsg.Request) (kmsg.Response, error, bool) {
+		cluster.KeepControl()
+		return nil, nil, true
+	})
+
+	const ackTimeout = 200 * time.Millisecond
+	publisher := newTestPublisher(t, cluster, subscription.CloudEventModeStructured, ackTimeout)
+
+	event := subscription.NewCustomerEvent(subscription.CustomerCreatedEventType, "sub-1", "customer-1", "", "corr-1", time.Now())
+
+	start := time.Now()
+	err := publisher.Publish(context.Background(), event)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Publish() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 10*ackTimeout {
+		t.Errorf("Publish() levou %s, want próximo de %s", elapsed, ackTimeout)
+	}
+}
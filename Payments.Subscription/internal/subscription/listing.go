@@ -0,0 +1,137 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Limites de página da listagem de subscriptions
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListSortOrder define a ordenação da listagem de subscriptions
+type ListSortOrder string
+
+const (
+	SortCreatedAtDesc ListSortOrder = "-created_at"
+	SortCreatedAtAsc  ListSortOrder = "created_at"
+)
+
+// Erros da listagem de subscriptions
+var (
+	ErrInvalidCursor       = errors.New("cursor de paginação inválido")
+	ErrInvalidSortOrder    = errors.New("ordenação inválida")
+	ErrInvalidPageSize     = errors.New("tamanho de página inválido")
+	ErrInvalidStatusFilter = errors.New("filtro de status inválido")
+	ErrInvalidDateRange    = errors.New("intervalo de datas inválido")
+)
+
+// ParseListSortOrder converte o parâmetro de ordenação; vazio equivale às mais recentes primeiro
+func ParseListSortOrder(value string) (ListSortOrder, error) {
+	switch ListSortOrder(value) {
+	case "":
+		return SortCreatedAtDesc, nil
+	case SortCreatedAtDesc, SortCreatedAtAsc:
+		return ListSortOrder(value), nil
+	default:
+		return "", ErrInvalidSortOrder
+	}
+}
+
+// IsDescending indica se a ordenação é decrescente
+func (o ListSortOrder) IsDescending() bool {
+	return o != SortCreatedAtAsc
+}
+
+// ListCursor identifica a última subscription retornada, pela chave (created_at, id)
+type ListCursor struct {
+	CreatedAt time.Time
+	ID        string
+	Sort      ListSortOrder
+}
+
+// cursorPayload é a forma serializada do cursor antes da codificação em base64
+type cursorPayload struct {
+	CreatedAt time.Time     `json:"c"`
+	ID        string        `json:"i"`
+	Sort      ListSortOrder `json:"s"`
+}
+
+// Encode serializa o cursor em uma string opaca para o cliente
+func (c ListCursor) Encode() string {
+	payload, _ := json.Marshal(cursorPayload{CreatedAt: c.CreatedAt.UTC(), ID: c.ID, Sort: c.Sort})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeListCursor interpreta um cursor recebido do cliente
+func DecodeListCursor(value string) (*ListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if payload.ID == "" || payload.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &ListCursor{CreatedAt: payload.CreatedAt, ID: payload.ID, Sort: payload.Sort}, nil
+}
+
+// ListSubscriptionsQuery define os filtros, a ordenação e a página da listagem de subscriptions
+type ListSubscriptionsQuery struct {
+	Status      SubscriptionStatus
+	PlanID      string
+	CustomerID  string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Sort        ListSortOrder
+	After       *ListCursor
+	Limit       int
+}
+
+// Validate verifica a consistência da consulta e aplica os valores padrão
+func (q *ListSubscriptionsQuery) Validate() error {
+	if q.Status != "" && !q.Status.IsValid() {
+		return ErrInvalidStatusFilter
+	}
+
+	if q.CreatedFrom != nil && q.CreatedTo != nil && !q.CreatedFrom.Before(*q.CreatedTo) {
+		return ErrInvalidDateRange
+	}
+
+	if q.Sort == "" {
+		q.Sort = SortCreatedAtDesc
+	}
+	if _, err := ParseListSortOrder(string(q.Sort)); err != nil {
+		return err
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return ErrInvalidPageSize
+	}
+
+	// O cursor só é válido para a mesma ordenação em que foi gerado
+	if q.After != nil && q.After.Sort != q.Sort {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
+
+// SubscriptionPage é uma página da listagem de subscriptions
+type SubscriptionPage struct {
+	Subscriptions []*Subscription
+	NextCursor    *ListCursor
+}
@@ -0,0 +1,107 @@
+package subscription
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestListCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, time.March, 10, 14, 30, 15, 123456000, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor ListCursor
+	}{
+		{"ordenação decrescente", ListCursor{CreatedAt: createdAt, ID: "b6a1c0e2-0000-4000-8000-000000000001", Sort: SortCreatedAtDesc}},
+		{"ordenação crescente", ListCursor{CreatedAt: createdAt, ID: "b6a1c0e2-0000-4000-8000-000000000002", Sort: SortCreatedAtAsc}},
+		{"horário fora de UTC é normalizado", ListCursor{CreatedAt: createdAt.In(time.FixedZone("BRT", -3*60*60)), ID: "sub-3", Sort: SortCreatedAtDesc}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeListCursor(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodeListCursor() error = %v", err)
+			}
+			if !decoded.CreatedAt.Equal(tt.cursor.CreatedAt) {
+				t.Errorf("CreatedAt = %v, want %v", decoded.CreatedAt, tt.cursor.CreatedAt)
+			}
+			if decoded.ID != tt.cursor.ID {
+				t.Errorf("ID = %q, want %q", decoded.ID, tt.cursor.ID)
+			}
+			if decoded.Sort != tt.cursor.Sort {
+				t.Errorf("Sort = %q, want %q", decoded.Sort, tt.cursor.Sort)
+			}
+		})
+	}
+}
+
+func TestDecodeListCursorRejectsInvalidValues(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+	valid := ListCursor{CreatedAt: time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC), ID: "sub-1", Sort: SortCreatedAtDesc}.Encode()
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"vazio", ""},
+		{"fora do alfabeto base64", "not a cursor!"},
+		{"base64 padrão com padding", base64.StdEncoding.EncodeToString([]byte(`{"c":"2026-03-10T00:00:00Z","i":"sub-1","s":"-created_at"}`))},
+		{"JSON truncado", valid[:len(valid)-6]},
+		{"JSON inválido", encode(`{"c":`)},
+		{"sem id", encode(`{"c":"2026-03-10T00:00:00Z","s":"-created_at"}`)},
+		{"sem created_at", encode(`{"i":"sub-1","s":"-created_at"}`)},
+		{"created_at com tipo errado", encode(`{"c":12345,"i":"sub-1","s":"-created_at"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeListCursor(tt.value); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeListCursor(%q) error = %v, want %v", tt.value, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestListSubscriptionsQueryValidate(t *testing.T) {
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	descCursor := &ListCursor{CreatedAt: from, ID: "sub-1", Sort: SortCreatedAtDesc}
+
+	tests := []struct {
+		name      string
+		query     ListSubscriptionsQuery
+		wantErr   error
+		wantSort  ListSortOrder
+		wantLimit int
+	}{
+		{"aplica os valores padrão", ListSubscriptionsQuery{}, nil, SortCreatedAtDesc, DefaultPageSize},
+		{"cursor da mesma ordenação", ListSubscriptionsQuery{After: descCursor, Limit: 5}, nil, SortCreatedAtDesc, 5},
+		{"cursor gerado em outra ordenação", ListSubscriptionsQuery{Sort: SortCreatedAtAsc, After: descCursor}, ErrInvalidCursor, SortCreatedAtAsc, DefaultPageSize},
+		{"ordenação desconhecida", ListSubscriptionsQuery{Sort: "plan_id"}, ErrInvalidSortOrder, "plan_id", 0},
+		{"página acima do máximo", ListSubscriptionsQuery{Limit: MaxPageSize + 1}, ErrInvalidPageSize, SortCreatedAtDesc, MaxPageSize + 1},
+		{"página negativa", ListSubscriptionsQuery{Limit: -1}, ErrInvalidPageSize, SortCreatedAtDesc, -1},
+		{"status desconhecido", ListSubscriptionsQuery{Status: "paused"}, ErrInvalidStatusFilter, "", 0},
+		{"intervalo de datas invertido", ListSubscriptionsQuery{CreatedFrom: &to, CreatedTo: &from}, ErrInvalidDateRange, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			err := query.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if query.Sort != tt.wantSort {
+				t.Errorf("Sort = %q, want %q", query.Sort, tt.wantSort)
+			}
+			if query.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", query.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"payments-subscription/internal/subscription"
+	"strings"
+	"time"
+)
+
+// deadLetterColumns lista as colunas lidas por scanDeadLetter, na mesma ordem
+const deadLetterColumns = `id, event_id, source, status, event_type, aggregate_id, correlation_id, handler, payload, headers,
+		error, attempts, replay_attempts, occurred_at, created_at, resolved_at`
+
+// MySQLDeadLetterRepository implementa o DeadLetterRepository usando MySQL
+type MySQLDeadLetterRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLDeadLetterRepository cria uma nova instância do repositório da dead-letter queue
+func NewMySQLDeadLetterRepository(db *sql.DB) *MySQLDeadLetterRepository {
+	return &MySQLDeadLetterRepository{
+		db: db,
+	}
+}
+
+// Create grava o evento na dead-letter queue
+func (r *MySQLDeadLetterRepository) Create(ctx context.Context, event *subscription.DeadLetterEvent) error {
+	headers, err := json.Marshal(event.Headers)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar cabeçalhos do evento: %w", err)
+	}
+
+	query := `
+		INSERT INTO dead_letter_events (id, event_id, source, status, event_type, aggregate_id, correlation_id,
+			handler, payload, headers, error, attempts, replay_attempts, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		event.ID,
+		event.EventID,
+		string(event.Source),
+		string(event.Status),
+		event.EventType,
+		event.AggregateID,
+		event.CorrelationID,
+		event.Handler,
+		[]byte(event.Payload),
+		headers,
+		event.Error,
+		event.Attempts,
+		event.ReplayAttempts,
+		event.OccurredAt,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir evento na dead-letter queue: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID busca um evento da dead-letter queue pelo ID
+func (r *MySQLDeadLetterRepository) GetByID(ctx context.Context, id string) (*subscription.DeadLetterEvent, error) {
+	query := `
+		SELECT ` + deadLetterColumns + `
+		FROM dead_letter_events
+		WHERE id = ?
+	`
+
+	event, err := scanDeadLetter(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, subscription.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar evento da dead-letter queue: %w", err)
+	}
+
+	return event, nil
+}
+
+// List lista os eventos que atendem ao filtro, mais recentes primeiro
+func (r *MySQLDeadLetterRepository) List(ctx context.Context, filter subscription.DeadLetterFilter) ([]*subscription.DeadLetterEvent, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.EventType != "" {
+		conditions = append(conditions, "event_type = ?")
+		args = append(args, filter.EventType)
+	}
+	if filter.AggregateID != "" {
+		conditions = append(conditions, "aggregate_id = ?")
+		args = append(args, filter.AggregateID)
+	}
+	if filter.CorrelationID != "" {
+		conditions = append(conditions, "correlation_id = ?")
+		args = append(args, filter.CorrelationID)
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "source = ?")
+		args = append(args, string(filter.Source))
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(filter.Status))
+	}
+
+	query := `SELECT ` + deadLetterColumns + ` FROM dead_letter_events`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar eventos da dead-letter queue: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*subscription.DeadLetterEvent{}
+
+	for rows.Next() {
+		event, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan do evento da dead-letter queue: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os eventos da dead-letter queue: %w", err)
+	}
+
+	return events, nil
+}
+
+// ClaimReplay reserva o evento para reprocessamento; o filtro por status impede que duas réplicas
+// reprocessem o mesmo evento, salvo quando a reserva anterior expirou
+func (r *MySQLDeadLetterRepository) ClaimReplay(ctx context.Context, id string, staleBefore time.Time) error {
+	query := `
+		UPDATE dead_letter_events
+		SET status = 'replaying', replay_claimed_at = ?
+		WHERE id = ? AND (status = 'pending' OR (status = 'replaying' AND replay_claimed_at < ?))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id, staleBefore)
+	if err != nil {
+		return fmt.Errorf("erro ao reservar evento da dead-letter queue: %w", err)
+	}
+
+	return r.checkUpdated(ctx, result, id)
+}
+
+// Resolve muda o status do evento; o filtro pelo status de origem impede que duas réplicas
+// reprocessem ou descartem o mesmo evento
+func (r *MySQLDeadLetterRepository) Resolve(ctx context.Context, id string, from, to subscription.DeadLetterStatus, resolvedAt time.Time) error {
+	query := `
+		UPDATE dead_letter_events
+		SET status = ?, resolved_at = ?, replay_claimed_at = NULL
+		WHERE id = ? AND status = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, string(to), resolvedAt, id, string(from))
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar evento da dead-letter queue: %w", err)
+	}
+
+	return r.checkUpdated(ctx, result, id)
+}
+
+// checkUpdated diferencia, quando nenhuma linha foi atualizada, o evento inexistente do evento que
+// não está mais no status esperado
+func (r *MySQLDeadLetterRepository) checkUpdated(ctx context.Context, result sql.Result, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM dead_letter_events WHERE id = ?)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar evento da dead-letter queue: %w", err)
+	}
+	if !exists {
+		return subscription.ErrDeadLetterNotFound
+	}
+
+	return subscription.ErrDeadLetterNotPending
+}
+
+// RecordReplayFailure incrementa as tentativas de reprocessamento, guarda o último erro e libera a reserva
+func (r *MySQLDeadLetterRepository) RecordReplayFailure(ctx context.Context, id string, replayErr error) error {
+	query := `
+		UPDATE dead_letter_events
+		SET replay_attempts = replay_attempts + 1, error = ?, status = 'pending', replay_claimed_at = NULL
+		WHERE id = ? AND status = 'replaying'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, replayErr.Error(), id); err != nil {
+		return fmt.Errorf("erro ao registrar falha de reprocessamento: %w", err)
+	}
+
+	return nil
+}
+
+// scanDeadLetter lê uma linha de dead_letter_events
+func scanDeadLetter(row rowScanner) (*subscription.DeadLetterEvent, error) {
+	event := &subscription.DeadLetterEvent{}
+
+	var source, status string
+	var payload, headers []byte
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(
+		&event.ID,
+		&event.EventID,
+		&source,
+		&status,
+		&event.EventType,
+		&event.AggregateID,
+		&event.CorrelationID,
+		&event.Handler,
+		&payload,
+		&headers,
+		&event.Error,
+		&event.Attempts,
+		&event.ReplayAttempts,
+		&event.OccurredAt,
+		&event.CreatedAt,
+		&resolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Source = subscription.DeadLetterSource(source)
+	event.Status = subscription.DeadLetterStatus(status)
+	event.Payload = payload
+
+	if err := json.Unmarshal(headers, &event.Headers); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar cabeçalhos do evento: %w", err)
+	}
+
+	if resolvedAt.Valid {
+		event.ResolvedAt = &resolvedAt.Time
+	}
+
+	return event, nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"payments-subscription/internal/subscription"
+	"strings"
+	"time"
+)
+
+// MySQLOutboxRepository implementa o OutboxRepository usando MySQL
+type MySQLOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLOutboxRepository cria uma nova instância do repositório de outbox
+func NewMySQLOutboxRepository(db *sql.DB) *MySQLOutboxRepository {
+	return &MySQLOutboxRepository{
+		db: db,
+	}
+}
+
+// insertOutboxMessages grava os eventos do agregado no outbox usando a transação informada
+func insertOutboxMessages(ctx context.Context, tx *sql.Tx, events []subscription.DomainEvent) error {
+	query := `
+		INSERT INTO outbox (id, event_id, aggregate_id, event_type, correlation_id, traceparent, payload, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	for _, event := range events {
+		message, err := subscription.NewOutboxMessage(ctx, event)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, query,
+			message.ID,
+			message.EventID,
+			message.AggregateID,
+			message.EventType,
+			message.CorrelationID,
+			message.TraceParent,
+			message.Payload,
+			message.OccurredAt,
+			message.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao inserir evento %s no outbox: %w", message.EventType, err)
+		}
+	}
+
+	return nil
+}
+
+// ClaimPending reserva mensagens pendentes para o relay, evitando que réplicas processem as mesmas linhas.
+// Uma mensagem fica de fora enquanto existir outra mais antiga do mesmo agregado que já falhou ou está
+// reservada por outro relay; mensagens já enviadas para a dead-letter queue não bloqueiam o agregado.
+// Como a ordenação só é verificada na consulta dos candidatos, depois da reserva a ordem do agregado é
+// conferida de novo e as mensagens que ainda têm uma anterior fora deste lote são liberadas
+func (r *MySQLOutboxRepository) ClaimPending(ctx context.Context, owner string, limit, maxAttempts int, lease time.Duration) ([]*subscription.OutboxMessage, error) {
+	now := time.Now()
+
+	candidatesQuery := `
+		SELECT o.id
+		FROM outbox o
+		WHERE o.published_at IS NULL
+		  AND o.attempts < ?
+		  AND (o.locked_until IS NULL OR o.locked_until < ?)
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM outbox older
+			WHERE older.aggregate_id = o.aggregate_id
+			  AND older.published_at IS NULL
+			  AND older.attempts < ?
+			  AND (older.created_at < o.created_at OR (older.created_at = o.created_at AND older.id < o.id))
+			  AND (older.attempts > 0 OR older.locked_until >= ?)
+		  )
+		ORDER BY o.created_at, o.id
+		LIMIT ?
+	`
+
+	candidateRows, err := r.db.QueryContext(ctx, candidatesQuery, maxAttempts, now, maxAttempts, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar mensagens pendentes do outbox: %w", err)
+	}
+
+	var ids []interface{}
+	for candidateRows.Next() {
+		var id string
+		if err := candidateRows.Scan(&id); err != nil {
+			candidateRows.Close()
+			return nil, fmt.Errorf("erro ao fazer scan da mensagem pendente do outbox: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	candidateRows.Close()
+	if err := candidateRows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as mensagens pendentes do outbox: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+
+	// A condição do lease é verificada de novo: outra réplica pode ter reservado a linha entre as duas consultas
+	claimQuery := `
+		UPDATE outbox
+		SET locked_by = ?, locked_until = ?
+		WHERE id IN (` + placeholders + `)
+		  AND published_at IS NULL
+		  AND (locked_until IS NULL OR locked_until < ?)
+	`
+
+	claimArgs := append([]interface{}{owner, now.Add(lease)}, ids...)
+	claimArgs = append(claimArgs, now)
+	if _, err := r.db.ExecContext(ctx, claimQuery, claimArgs...); err != nil {
+		return nil, fmt.Errorf("erro ao reservar mensagens do outbox: %w", err)
+	}
+
+	// blocked indica uma mensagem anterior do agregado, ainda não publicada, que não está reservada por
+	// este relay: outra réplica pode tê-la reservado entre a busca dos candidatos e a reserva
+	query := `
+		SELECT o.id, o.event_id, o.aggregate_id, o.event_type, o.correlation_id, o.traceparent, o.payload, o.occurred_at,
+			o.attempts, COALESCE(o.last_error, ''), o.created_at,
+			EXISTS (
+				SELECT 1
+				FROM outbox older
+				WHERE older.aggregate_id = o.aggregate_id
+				  AND older.published_at IS NULL
+				  AND older.attempts < ?
+				  AND (older.created_at < o.created_at OR (older.created_at = o.created_at AND older.id < o.id))
+				  AND (older.locked_by IS NULL OR older.locked_by <> ? OR older.locked_until < ?)
+			) AS blocked
+		FROM outbox o
+		WHERE o.id IN (` + placeholders + `) AND o.locked_by = ? AND o.published_at IS NULL
+		ORDER BY o.created_at, o.id
+	`
+
+	queryArgs := append([]interface{}{maxAttempts, owner, now}, ids...)
+	queryArgs = append(queryArgs, owner)
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar mensagens do outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*subscription.OutboxMessage
+	var blocked []bool
+
+	for rows.Next() {
+		message := &subscription.OutboxMessage{}
+		var isBlocked bool
+
+		err := rows.Scan(
+			&message.ID,
+			&message.EventID,
+			&message.AggregateID,
+			&message.EventType,
+			&message.CorrelationID,
+			&message.TraceParent,
+			&message.Payload,
+			&message.OccurredAt,
+			&message.Attempts,
+			&message.LastError,
+			&message.CreatedAt,
+			&isBlocked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan da mensagem do outbox: %w", err)
+		}
+
+		messages = append(messages, message)
+		blocked = append(blocked, isBlocked)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as mensagens do outbox: %w", err)
+	}
+	rows.Close()
+
+	ready, held := holdBlockedAggregates(messages, blocked)
+	for _, message := range held {
+		if err := r.Release(ctx, message.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return ready, nil
+}
+
+// holdBlockedAggregates separa as mensagens bloqueadas por uma mensagem anterior fora do lote. As mensagens
+// seguintes do mesmo agregado também ficam retidas, para que nenhuma seja publicada fora de ordem
+func holdBlockedAggregates(messages []*subscription.OutboxMessage, blocked []bool) (ready, held []*subscription.OutboxMessage) {
+	heldAggregates := make(map[string]bool)
+
+	for i, message := range messages {
+		if blocked[i] || heldAggregates[message.AggregateID] {
+			heldAggregates[message.AggregateID] = true
+			held = append(held, message)
+			continue
+		}
+		ready = append(ready, message)
+	}
+
+	return ready, held
+}
+
+// MarkPublished marca a mensagem como publicada e libera o lease
+func (r *MySQLOutboxRepository) MarkPublished(ctx context.Context, id string, publishedAt time.Time) error {
+	query := `
+		UPDATE outbox
+		SET published_at = ?, locked_by = NULL, locked_until = NULL
+		WHERE id = ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, publishedAt, id); err != nil {
+		return fmt.Errorf("erro ao marcar mensagem do outbox como publicada: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed incrementa as tentativas, registra o erro e libera o lease para nova tentativa
+func (r *MySQLOutboxRepository) MarkFailed(ctx context.Context, id string, publishErr error) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = ?, locked_by = NULL, locked_until = NULL
+		WHERE id = ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, publishErr.Error(), id); err != nil {
+		return fmt.Errorf("erro ao registrar falha da mensagem do outbox: %w", err)
+	}
+
+	return nil
+}
+
+// Release libera o lease da mensagem sem registrar tentativa
+func (r *MySQLOutboxRepository) Release(ctx context.Context, id string) error {
+	query := `
+		UPDATE outbox
+		SET locked_by = NULL, locked_until = NULL
+		WHERE id = ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("erro ao liberar mensagem do outbox: %w", err)
+	}
+
+	return nil
+}
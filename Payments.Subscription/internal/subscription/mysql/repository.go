@@ -5,9 +5,24 @@ import (
 	"database/sql"
 	"fmt"
 	"payments-subscription/internal/subscription"
+	"strings"
 	"time"
 )
 
+// initialVersion é a versão gravada na criação de uma subscription
+const initialVersion = 1
+
+// subscriptionColumns lista as colunas lidas por scanSubscription, na mesma ordem
+const subscriptionColumns = `id, plan_id, customer_id, status, billing_interval, billing_interval_days,
+		current_period_start, current_period_end, trial_ends_at, trial_end_notified, payment_method_id,
+		requested_trial_days, pending_plan_id, pending_billing_interval, pending_billing_interval_days,
+		cancel_at_period_end, cancellation_reason, version, created_at, updated_at`
+
+// rowScanner abstrai *sql.Row e *sql.Rows para o scan de uma subscription
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 // MySQLSubscriptionRepository implementa o SubscriptionRepository usando MySQL
 type MySQLSubscriptionRepository struct {
 	db *sql.DB
@@ -20,192 +35,404 @@ func NewMySQLSubscriptionRepository(db *sql.DB) *MySQLSubscriptionRepository {
 	}
 }
 
-// Create cria uma nova subscription no banco de dados
+// Create cria uma nova subscription no banco de dados, gravando seus eventos no outbox na mesma transação
 func (r *MySQLSubscriptionRepository) Create(ctx context.Context, sub *subscription.Subscription) error {
 
 	query := `
-		INSERT INTO subscriptions (id, plan_id, customer_id, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO subscriptions (id, plan_id, customer_id, status, billing_interval, billing_interval_days,
+			current_period_start, current_period_end, trial_ends_at, trial_end_notified, payment_method_id,
+			requested_trial_days, pending_plan_id, pending_billing_interval, pending_billing_interval_days,
+			cancel_at_period_end, cancellation_reason, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		sub.ID().String(),
-		sub.PlanID().String(),
-		sub.CustomerID().String(),
-		string(sub.Status()),
-		sub.CreatedAt(),
-		sub.UpdatedAt(),
-	)
+	return r.withTransaction(ctx, sub, initialVersion, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query,
+			sub.ID().String(),
+			sub.PlanID().String(),
+			nullableString(sub.CustomerID().String()),
+			string(sub.Status()),
+			string(sub.BillingInterval().Unit()),
+			nullableInt(sub.BillingInterval().Days()),
+			nullableTime(sub.CurrentPeriodStart()),
+			nullableTime(sub.CurrentPeriodEnd()),
+			nullableTime(sub.TrialEndsAt()),
+			sub.TrialEndNotified(),
+			nullableString(sub.PaymentMethodID()),
+			sub.RequestedTrialDays(),
+			nullableString(sub.PendingPlanID().String()),
+			nullableString(string(sub.PendingBillingInterval().Unit())),
+			nullableInt(sub.PendingBillingInterval().Days()),
+			sub.CancelAtPeriodEnd(),
+			nullableString(sub.CancellationReason()),
+			initialVersion,
+			sub.CreatedAt(),
+			sub.UpdatedAt(),
+		)
 
-	if err != nil {
-		return fmt.Errorf("erro ao inserir subscription no banco: %w", err)
-	}
+		if err != nil {
+			return fmt.Errorf("erro ao inserir subscription no banco: %w", err)
+		}
 
-	return nil
+		return insertOutboxMessages(ctx, tx, sub.Events())
+	})
 }
 
 // GetByID busca uma subscription pelo ID no banco de dados
 func (r *MySQLSubscriptionRepository) GetByID(ctx context.Context, id subscription.SubscriptionID) (*subscription.Subscription, error) {
 	query := `
-		SELECT id, plan_id, customer_id, status, created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions
 		WHERE id = ?
 	`
 
 	row := r.db.QueryRowContext(ctx, query, id.String())
 
-	var subscriptionID, planID, customerID, status string
-	var createdAt, updatedAt time.Time
-
-	err := row.Scan(&subscriptionID, &planID, &customerID, &status, &createdAt, &updatedAt)
+	subscriptionEntity, err := scanSubscription(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, subscription.ErrSubscriptionNotFound
 		}
-		return nil, fmt.Errorf("erro ao buscar subscription no banco: %w", err)
-	}
-
-	subscriptionEntity, err := subscription.ReconstructSubscription(
-		subscriptionID,
-		planID,
-		customerID,
-		subscription.SubscriptionStatus(status),
-		createdAt,
-		updatedAt,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao reconstruir subscription: %w", err)
+		return nil, err
 	}
 
 	return subscriptionEntity, nil
 }
 
-// GetByCustomerID busca subscriptions pelo customer ID no banco de dados
-func (r *MySQLSubscriptionRepository) GetByCustomerID(ctx context.Context, customerID subscription.CustomerID) ([]*subscription.Subscription, error) {
+// GetByCustomerID busca uma página das subscriptions de um customer no banco de dados
+func (r *MySQLSubscriptionRepository) GetByCustomerID(ctx context.Context, customerID subscription.CustomerID, query subscription.ListSubscriptionsQuery) (*subscription.SubscriptionPage, error) {
+	query.CustomerID = customerID.String()
+	return r.List(ctx, query)
+}
+
+// Update atualiza uma subscription existente no banco de dados, gravando seus eventos no outbox na mesma transação.
+// A atualização só é aplicada se a versão no banco ainda for a versão carregada (compare-and-swap)
+func (r *MySQLSubscriptionRepository) Update(ctx context.Context, sub *subscription.Subscription) error {
 
 	query := `
-		SELECT id, plan_id, customer_id, status, created_at, updated_at
-		FROM subscriptions
-		WHERE customer_id = ?
-		ORDER BY created_at DESC
+		UPDATE subscriptions 
+		SET plan_id = ?, customer_id = ?, status = ?, billing_interval = ?, billing_interval_days = ?,
+			current_period_start = ?, current_period_end = ?, trial_ends_at = ?, trial_end_notified = ?,
+			payment_method_id = ?, requested_trial_days = ?, pending_plan_id = ?, pending_billing_interval = ?,
+			pending_billing_interval_days = ?, cancel_at_period_end = ?, cancellation_reason = ?, updated_at = ?,
+			version = version + 1
+		WHERE id = ? AND version = ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, customerID.String())
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar subscriptions no banco: %w", err)
-	}
-	defer rows.Close()
-
-	var subscriptions []*subscription.Subscription
-
-	for rows.Next() {
-		var subscriptionID, planID, custID, status string
-		var createdAt, updatedAt time.Time
+	return r.withTransaction(ctx, sub, sub.Version()+1, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query,
+			sub.PlanID().String(),
+			nullableString(sub.CustomerID().String()),
+			string(sub.Status()),
+			string(sub.BillingInterval().Unit()),
+			nullableInt(sub.BillingInterval().Days()),
+			nullableTime(sub.CurrentPeriodStart()),
+			nullableTime(sub.CurrentPeriodEnd()),
+			nullableTime(sub.TrialEndsAt()),
+			sub.TrialEndNotified(),
+			nullableString(sub.PaymentMethodID()),
+			sub.RequestedTrialDays(),
+			nullableString(sub.PendingPlanID().String()),
+			nullableString(string(sub.PendingBillingInterval().Unit())),
+			nullableInt(sub.PendingBillingInterval().Days()),
+			sub.CancelAtPeriodEnd(),
+			nullableString(sub.CancellationReason()),
+			sub.UpdatedAt(),
+			sub.ID().String(),
+			sub.Version(),
+		)
 
-		err := rows.Scan(&subscriptionID, &planID, &custID, &status, &createdAt, &updatedAt)
 		if err != nil {
-			return nil, fmt.Errorf("erro ao fazer scan da subscription: %w", err)
+			return fmt.Errorf("erro ao atualizar subscription no banco: %w", err)
 		}
 
-		subscriptionEntity, err := subscription.ReconstructSubscription(
-			subscriptionID,
-			planID,
-			custID,
-			subscription.SubscriptionStatus(status),
-			createdAt,
-			updatedAt,
-		)
+		rowsAffected, err := result.RowsAffected()
 		if err != nil {
-			return nil, fmt.Errorf("erro ao reconstruir subscription: %w", err)
+			return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
 		}
 
-		subscriptions = append(subscriptions, subscriptionEntity)
+		if rowsAffected == 0 {
+			return r.missingRowError(ctx, tx, sub.ID())
+		}
+
+		return insertOutboxMessages(ctx, tx, sub.Events())
+	})
+}
+
+// List busca uma página de subscriptions usando paginação por chave (created_at, id).
+// Os filtros são aplicados no SQL e uma linha extra é lida para saber se existe próxima página
+func (r *MySQLSubscriptionRepository) List(ctx context.Context, query subscription.ListSubscriptionsQuery) (*subscription.SubscriptionPage, error) {
+	var conditions []string
+	var args []interface{}
+
+	if query.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, string(query.Status))
+	}
+	if query.PlanID != "" {
+		conditions = append(conditions, "plan_id = ?")
+		args = append(args, query.PlanID)
+	}
+	if query.CustomerID != "" {
+		conditions = append(conditions, "customer_id = ?")
+		args = append(args, query.CustomerID)
+	}
+	if query.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *query.CreatedTo)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre as subscriptions: %w", err)
+	direction := "ASC"
+	if query.Sort.IsDescending() {
+		direction = "DESC"
 	}
 
-	return subscriptions, nil
-}
+	if query.After != nil {
+		condition, keysetArgs := keysetCondition(*query.After, query.Sort)
+		conditions = append(conditions, condition)
+		args = append(args, keysetArgs...)
+	}
 
-// Update atualiza uma subscription existente no banco de dados
-func (r *MySQLSubscriptionRepository) Update(ctx context.Context, sub *subscription.Subscription) error {
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
 
-	query := `
-		UPDATE subscriptions 
-		SET plan_id = ?, customer_id = ?, status = ?, updated_at = ?
-		WHERE id = ?
+	sqlQuery := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		` + where + `
+		ORDER BY created_at ` + direction + `, id ` + direction + `
+		LIMIT ?
 	`
+	args = append(args, query.Limit+1)
 
-	result, err := r.db.ExecContext(ctx, query,
-		sub.PlanID().String(),
-		sub.CustomerID().String(),
-		string(sub.Status()),
-		sub.UpdatedAt(),
-		sub.ID().String(),
-	)
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar subscriptions no banco: %w", err)
+	}
 
+	subscriptions, err := scanSubscriptions(rows)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar subscription no banco: %w", err)
+		return nil, err
+	}
+
+	page := &subscription.SubscriptionPage{Subscriptions: subscriptions}
+	if len(subscriptions) > query.Limit {
+		page.Subscriptions = subscriptions[:query.Limit]
+		last := page.Subscriptions[query.Limit-1]
+		page.NextCursor = &subscription.ListCursor{
+			CreatedAt: last.CreatedAt(),
+			ID:        last.ID().String(),
+			Sort:      query.Sort,
+		}
+	}
+
+	return page, nil
+}
+
+// keysetCondition monta o filtro que retoma a listagem após o cursor; subscriptions com o mesmo
+// created_at são desempatadas pelo id, na mesma direção da ordenação
+func keysetCondition(after subscription.ListCursor, sort subscription.ListSortOrder) (string, []interface{}) {
+	comparison := ">"
+	if sort.IsDescending() {
+		comparison = "<"
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	condition := "(created_at " + comparison + " ? OR (created_at = ? AND id " + comparison + " ?))"
+	return condition, []interface{}{after.CreatedAt, after.CreatedAt, after.ID}
+}
+
+// withTransaction executa fn dentro de uma transação e, após o commit, registra a nova versão no agregado
+func (r *MySQLSubscriptionRepository) withTransaction(ctx context.Context, sub *subscription.Subscription, version int, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return subscription.ErrSubscriptionNotFound
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
 	}
 
+	// Os eventos já estão no outbox e serão publicados pelo relay
+	sub.MarkPersisted(version)
 	return nil
 }
 
-// GetAll busca todas as subscriptions no banco de dados
-func (r *MySQLSubscriptionRepository) GetAll(ctx context.Context) ([]*subscription.Subscription, error) {
+// missingRowError distingue uma subscription inexistente de uma alterada concorrentemente
+func (r *MySQLSubscriptionRepository) missingRowError(ctx context.Context, tx *sql.Tx, id subscription.SubscriptionID) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM subscriptions WHERE id = ?)`, id.String()).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar existência da subscription: %w", err)
+	}
+
+	if !exists {
+		return subscription.ErrSubscriptionNotFound
+	}
 
-	query := `
-		SELECT id, plan_id, customer_id, status, created_at, updated_at
-		FROM subscriptions
-		ORDER BY created_at DESC
-	`
+	return subscription.ErrConcurrentModification
+}
 
-	rows, err := r.db.QueryContext(ctx, query)
+// scanSubscription lê uma linha com subscriptionColumns e reconstrói o agregado
+func scanSubscription(row rowScanner) (*subscription.Subscription, error) {
+	var snapshot subscription.SubscriptionSnapshot
+	var status string
+	var intervalDays, pendingIntervalDays sql.NullInt64
+	var periodStart, periodEnd, trialEndsAt sql.NullTime
+	var customerID, paymentMethodID, pendingPlanID, pendingInterval, cancellationReason sql.NullString
+
+	err := row.Scan(
+		&snapshot.ID,
+		&snapshot.PlanID,
+		&customerID,
+		&status,
+		&snapshot.BillingInterval,
+		&intervalDays,
+		&periodStart,
+		&periodEnd,
+		&trialEndsAt,
+		&snapshot.TrialEndNotified,
+		&paymentMethodID,
+		&snapshot.RequestedTrialDays,
+		&pendingPlanID,
+		&pendingInterval,
+		&pendingIntervalDays,
+		&snapshot.CancelAtPeriodEnd,
+		&cancellationReason,
+		&snapshot.Version,
+		&snapshot.CreatedAt,
+		&snapshot.UpdatedAt,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar subscriptions no banco: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao fazer scan da subscription: %w", err)
+	}
+
+	snapshot.CustomerID = customerID.String
+	snapshot.Status = subscription.SubscriptionStatus(status)
+	snapshot.BillingIntervalDays = int(intervalDays.Int64)
+	snapshot.CurrentPeriodStart = periodStart.Time
+	snapshot.CurrentPeriodEnd = periodEnd.Time
+	snapshot.TrialEndsAt = trialEndsAt.Time
+	snapshot.PaymentMethodID = paymentMethodID.String
+	snapshot.PendingPlanID = pendingPlanID.String
+	snapshot.PendingInterval = pendingInterval.String
+	snapshot.PendingIntervalDays = int(pendingIntervalDays.Int64)
+	snapshot.CancellationReason = cancellationReason.String
+
+	subscriptionEntity, err := subscription.ReconstructSubscription(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reconstruir subscription: %w", err)
 	}
+
+	return subscriptionEntity, nil
+}
+
+// scanSubscriptions lê todas as linhas e fecha o cursor
+func scanSubscriptions(rows *sql.Rows) ([]*subscription.Subscription, error) {
 	defer rows.Close()
 
 	var subscriptions []*subscription.Subscription
 
 	for rows.Next() {
-		var subscriptionID, planID, customerID, status string
-		var createdAt, updatedAt time.Time
-
-		err := rows.Scan(&subscriptionID, &planID, &customerID, &status, &createdAt, &updatedAt)
+		subscriptionEntity, err := scanSubscription(rows)
 		if err != nil {
-			return nil, fmt.Errorf("erro ao fazer scan da subscription: %w", err)
-		}
-
-		subscriptionEntity, err := subscription.ReconstructSubscription(
-			subscriptionID,
-			planID,
-			customerID,
-			subscription.SubscriptionStatus(status),
-			createdAt,
-			updatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao reconstruir subscription: %w", err)
+			return nil, err
 		}
 
 		subscriptions = append(subscriptions, subscriptionEntity)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("erro ao iterar sobre as subscriptions: %w", err)
 	}
 
 	return subscriptions, nil
 }
+
+// nullableTime converte o zero value em NULL
+func nullableTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// nullableString converte string vazia em NULL
+func nullableString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+// nullableInt converte zero em NULL
+func nullableInt(v int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: v != 0}
+}
+
+// ClaimDueSubscriptions reserva subscriptions com período (ou trial) encerrado, ou trial prestes a
+// terminar, gravando um lease para que várias réplicas do worker não processem a mesma linha. Só
+// retorna as linhas reservadas com o Owner desta chamada, que deve ser único por passada
+func (r *MySQLSubscriptionRepository) ClaimDueSubscriptions(ctx context.Context, query subscription.DueSubscriptionsQuery) ([]*subscription.Subscription, error) {
+	claimQuery := `
+		UPDATE subscriptions
+		SET lease_owner = ?, lease_expires_at = ?
+		WHERE (
+				(status IN ('active', 'suspended') AND current_period_end <= ?)
+				OR (status = 'trialing' AND trial_ends_at <= ?)
+				OR (status = 'trialing' AND trial_end_notified = FALSE AND trial_ends_at <= ?)
+			)
+		  AND (lease_expires_at IS NULL OR lease_expires_at < ?)
+		ORDER BY COALESCE(current_period_end, trial_ends_at)
+		LIMIT ?
+	`
+
+	_, err := r.db.ExecContext(ctx, claimQuery,
+		query.Owner,
+		query.Now.Add(query.LeaseDuration),
+		query.Now,
+		query.Now,
+		query.Now.Add(query.TrialNotice),
+		query.Now,
+		query.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reservar subscriptions vencidas: %w", err)
+	}
+
+	selectQuery := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE lease_owner = ? AND lease_expires_at >= ?
+		ORDER BY COALESCE(current_period_end, trial_ends_at)
+	`
+
+	rows, err := r.db.QueryContext(ctx, selectQuery, query.Owner, query.Now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar subscriptions reservadas: %w", err)
+	}
+
+	return scanSubscriptions(rows)
+}
+
+// ReleaseLease libera o lease de uma subscription reservada pelo owner
+func (r *MySQLSubscriptionRepository) ReleaseLease(ctx context.Context, id subscription.SubscriptionID, owner string) error {
+	query := `
+		UPDATE subscriptions
+		SET lease_owner = NULL, lease_expires_at = NULL
+		WHERE id = ? AND lease_owner = ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id.String(), owner); err != nil {
+		return fmt.Errorf("erro ao liberar lease da subscription: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"payments-subscription/internal/subscription"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	createdAt := time.Date(2026, time.March, 10, 14, 30, 0, 0, time.UTC)
+	cursor := subscription.ListCursor{CreatedAt: createdAt, ID: "sub-5"}
+
+	tests := []struct {
+		name          string
+		sort          subscription.ListSortOrder
+		wantCondition string
+	}{
+		{
+			name:          "decrescente retoma abaixo do cursor e desempata pelo id menor",
+			sort:          subscription.SortCreatedAtDesc,
+			wantCondition: "(created_at < ? OR (created_at = ? AND id < ?))",
+		},
+		{
+			name:          "crescente retoma acima do cursor e desempata pelo id maior",
+			sort:          subscription.SortCreatedAtAsc,
+			wantCondition: "(created_at > ? OR (created_at = ? AND id > ?))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args := keysetCondition(cursor, tt.sort)
+			if condition != tt.wantCondition {
+				t.Errorf("condition = %q, want %q", condition, tt.wantCondition)
+			}
+
+			// Os dois primeiros placeholders recebem o created_at do cursor e o último o id do desempate
+			if len(args) != 3 {
+				t.Fatalf("len(args) = %d, want 3", len(args))
+			}
+			for i := 0; i < 2; i++ {
+				if got, ok := args[i].(time.Time); !ok || !got.Equal(createdAt) {
+					t.Errorf("args[%d] = %v, want %v", i, args[i], createdAt)
+				}
+			}
+			if args[2] != cursor.ID {
+				t.Errorf("args[2] = %v, want %q", args[2], cursor.ID)
+			}
+		})
+	}
+}
+
+func TestHoldBlockedAggregates(t *testing.T) {
+	messages := []*subscription.OutboxMessage{
+		{ID: "a-1", AggregateID: "sub-a"},
+		{ID: "b-1", AggregateID: "sub-b"},
+		{ID: "a-2", AggregateID: "sub-a"},
+		{ID: "b-2", AggregateID: "sub-b"},
+		{ID: "b-3", AggregateID: "sub-b"},
+	}
+
+	tests := []struct {
+		name      string
+		blocked   []bool
+		wantReady []string
+		wantHeld  []string
+	}{
+		{
+			name:      "sem bloqueio publica o lote inteiro",
+			blocked:   []bool{false, false, false, false, false},
+			wantReady: []string{"a-1", "b-1", "a-2", "b-2", "b-3"},
+		},
+		{
+			name:      "mensagem bloqueada retém as seguintes do mesmo agregado",
+			blocked:   []bool{false, true, false, false, false},
+			wantReady: []string{"a-1", "a-2"},
+			wantHeld:  []string{"b-1", "b-2", "b-3"},
+		},
+		{
+			name:      "bloqueio no meio do agregado mantém as anteriores",
+			blocked:   []bool{false, false, true, false, false},
+			wantReady: []string{"a-1", "b-1", "b-2", "b-3"},
+			wantHeld:  []string{"a-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, held := holdBlockedAggregates(messages, tt.blocked)
+			if got := outboxMessageIDs(ready); !equalStrings(got, tt.wantReady) {
+				t.Errorf("ready = %v, want %v", got, tt.wantReady)
+			}
+			if got := outboxMessageIDs(held); !equalStrings(got, tt.wantHeld) {
+				t.Errorf("held = %v, want %v", got, tt.wantHeld)
+			}
+		})
+	}
+}
+
+func outboxMessageIDs(messages []*subscription.OutboxMessage) []string {
+	var ids []string
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
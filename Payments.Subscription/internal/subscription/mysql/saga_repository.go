@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"payments-subscription/internal/subscription"
+	"time"
+)
+
+// sagaColumns lista as colunas lidas por scanSaga, na mesma ordem
+const sagaColumns = `id, saga_type, status, COALESCE(subscription_id, ''), COALESCE(customer_id, ''), customer_created,
+		steps, COALESCE(last_error, ''), attempts, correlation_id, created_at, updated_at`
+
+// MySQLSagaRepository implementa o SagaRepository usando MySQL
+type MySQLSagaRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLSagaRepository cria uma nova instância do repositório de sagas
+func NewMySQLSagaRepository(db *sql.DB) *MySQLSagaRepository {
+	return &MySQLSagaRepository{
+		db: db,
+	}
+}
+
+// Create grava o estado inicial da saga
+func (r *MySQLSagaRepository) Create(ctx context.Context, saga *subscription.SagaState) error {
+	steps, err := marshalSagaSteps(saga.Steps)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO subscription_sagas (id, saga_type, status, subscription_id, customer_id, customer_created,
+			steps, last_error, attempts, correlation_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		saga.ID,
+		saga.Type,
+		string(saga.Status),
+		nullableString(saga.SubscriptionID),
+		nullableString(saga.CustomerID),
+		saga.CustomerCreated,
+		steps,
+		nullableString(saga.LastError),
+		saga.Attempts,
+		saga.CorrelationID,
+		saga.CreatedAt,
+		saga.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir saga no banco: %w", err)
+	}
+
+	return nil
+}
+
+// Update grava o estado atual da saga e libera o lease. Sagas finalizadas não são reabertas, e só o dono
+// de um lease ainda válido pode gravar a saga reservada
+func (r *MySQLSagaRepository) Update(ctx context.Context, saga *subscription.SagaState) error {
+	steps, err := marshalSagaSteps(saga.Steps)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	query := `
+		UPDATE subscription_sagas
+		SET status = ?, subscription_id = ?, customer_id = ?, customer_created = ?, steps = ?,
+			last_error = ?, attempts = ?, updated_at = ?, locked_by = NULL, locked_until = NULL
+		WHERE id = ?
+		  AND status NOT IN ('completed', 'compensated')
+		  AND (locked_until IS NULL OR locked_until < ? OR locked_by = ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		string(saga.Status),
+		nullableString(saga.SubscriptionID),
+		nullableString(saga.CustomerID),
+		saga.CustomerCreated,
+		steps,
+		nullableString(saga.LastError),
+		saga.Attempts,
+		now,
+		saga.ID,
+		now,
+		saga.LeaseOwner,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar saga no banco: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar atualização da saga: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("saga %s: %w", saga.ID, subscription.ErrSagaConflict)
+	}
+
+	saga.LeaseOwner = ""
+	return nil
+}
+
+// ClaimUnfinished reserva sagas em compensação ou paradas em execução, evitando que réplicas processem as mesmas linhas
+func (r *MySQLSagaRepository) ClaimUnfinished(ctx context.Context, owner string, staleBefore time.Time, limit int, lease time.Duration) ([]*subscription.SagaState, error) {
+	now := time.Now()
+
+	claimQuery := `
+		UPDATE subscription_sagas
+		SET locked_by = ?, locked_until = ?
+		WHERE (status = 'compensating' OR (status = 'running' AND updated_at < ?))
+		  AND (locked_until IS NULL OR locked_until < ?)
+		ORDER BY updated_at
+		LIMIT ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, claimQuery, owner, now.Add(lease), staleBefore, now, limit); err != nil {
+		return nil, fmt.Errorf("erro ao reservar sagas pendentes: %w", err)
+	}
+
+	query := `
+		SELECT ` + sagaColumns + `
+		FROM subscription_sagas
+		WHERE locked_by = ? AND locked_until >= ? AND status IN ('running', 'compensating')
+		ORDER BY updated_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, owner, now)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar sagas reservadas: %w", err)
+	}
+	defer rows.Close()
+
+	var sagas []*subscription.SagaState
+
+	for rows.Next() {
+		saga, err := scanSaga(rows)
+		if err != nil {
+			return nil, err
+		}
+		saga.LeaseOwner = owner
+		sagas = append(sagas, saga)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as sagas: %w", err)
+	}
+
+	return sagas, nil
+}
+
+// scanSaga lê uma linha de subscription_sagas
+func scanSaga(row rowScanner) (*subscription.SagaState, error) {
+	saga := &subscription.SagaState{}
+
+	var status string
+	var steps []byte
+
+	err := row.Scan(
+		&saga.ID,
+		&saga.Type,
+		&status,
+		&saga.SubscriptionID,
+		&saga.CustomerID,
+		&saga.CustomerCreated,
+		&steps,
+		&saga.LastError,
+		&saga.Attempts,
+		&saga.CorrelationID,
+		&saga.CreatedAt,
+		&saga.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao fazer scan da saga: %w", err)
+	}
+
+	saga.Status = subscription.SagaStatus(status)
+
+	if err := json.Unmarshal(steps, &saga.Steps); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar passos da saga: %w", err)
+	}
+
+	return saga, nil
+}
+
+// marshalSagaSteps serializa os passos da saga para a coluna JSON
+func marshalSagaSteps(steps []subscription.SagaStep) ([]byte, error) {
+	if steps == nil {
+		steps = []subscription.SagaStep{}
+	}
+
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar passos da saga: %w", err)
+	}
+
+	return data, nil
+}
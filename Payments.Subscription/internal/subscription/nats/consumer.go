@@ -0,0 +1,227 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/subscription"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EventConsumer consome eventos do JetStream com um consumer durável e ack manual, entregando cada
+// mensagem ao EventDispatcher, que limita a concorrência e aplica retry e tratamento de envenenadas.
+// Mensagens que não decodificam ou esgotam o MaxDeliver vão para o PoisonHandler antes de serem encerradas
+type EventConsumer struct {
+	js         jetstream.JetStream
+	cfg        Config
+	dispatcher *subscription.EventDispatcher
+	poison     subscription.PoisonHandler
+	tracer     trace.Tracer
+	logger     *logging.StructuredLogger
+}
+
+// NewEventConsumer cria uma nova instância do consumer JetStream
+func NewEventConsumer(js jetstream.JetStream, cfg Config, tracer trace.Tracer, dispatcher *subscription.EventDispatcher, poison subscription.PoisonHandler) *EventConsumer {
+	if poison == nil {
+		poison = subscription.NewLoggingPoisonHandler()
+	}
+
+	return &EventConsumer{
+		js:         js,
+		cfg:        cfg,
+		dispatcher: dispatcher,
+		poison:     poison,
+		tracer:     tracer,
+		logger:     logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// Run cria (ou atualiza) o consumer durável e processa mensagens até o contexto ser cancelado
+func (c *EventConsumer) Run(ctx context.Context) error {
+	consumer, err := c.js.CreateOrUpdateConsumer(ctx, c.cfg.Stream, jetstream.ConsumerConfig{
+		Durable:        c.cfg.Durable,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        c.cfg.AckWait,
+		MaxDeliver:     c.cfg.MaxDeliver,
+		FilterSubjects: c.cfg.ConsumerSubjects,
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao criar consumer %s: %w", c.cfg.Durable, err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		c.process(ctx, msg)
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar consumo do consumer %s: %w", c.cfg.Durable, err)
+	}
+
+	c.logger.Info(ctx, "EventConsumer", "Consumer JetStream iniciado", map[string]interface{}{
+		"stream":   c.cfg.Stream,
+		"durable":  c.cfg.Durable,
+		"subjects": c.cfg.ConsumerSubjects,
+	})
+
+	<-ctx.Done()
+
+	// Para de receber mensagens e aguarda as que já estão com o dispatcher
+	consumeCtx.Stop()
+	c.dispatcher.Wait()
+	return nil
+}
+
+// process decodifica a mensagem e a entrega ao dispatcher; a confirmação acontece quando o
+// dispatcher terminar, fora da goroutine de consumo
+func (c *EventConsumer) process(ctx context.Context, msg jetstream.Msg) {
+	// Continua o trace iniciado pelo publisher a partir dos cabeçalhos
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(msg.Headers()))
+	if correlationID := msg.Headers().Get(CorrelationIDHeader); correlationID != "" {
+		ctx = logging.WithCorrelationID(ctx, correlationID)
+	} else {
+		ctx = logging.EnsureCorrelationID(ctx, "subscription")
+	}
+
+	ctx, span := c.tracer.Start(ctx, "NATS.Process "+msg.Subject(), trace.WithSpanKind(trace.SpanKindConsumer))
+
+	var deliveries uint64 = 1
+	if metadata, err := msg.Metadata(); err == nil {
+		deliveries = metadata.NumDelivered
+	}
+
+	span.SetAttributes(
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.source.name", msg.Subject()),
+		attribute.String("messaging.consumer.group.name", c.cfg.Durable),
+		attribute.Int64("messaging.nats.deliveries", int64(deliveries)),
+	)
+
+	event, err := c.decode(ctx, msg)
+	if err != nil {
+		c.settle(ctx, span, msg, undecodedEvent(msg), deliveries, err)
+		return
+	}
+
+	err = c.dispatcher.Submit(ctx, event, func(err error) {
+		c.settle(ctx, span, msg, event, deliveries, err)
+	})
+	if err != nil {
+		c.settle(ctx, span, msg, event, deliveries, err)
+	}
+}
+
+// settle confirma a mensagem processada ou envenenada, encerra as que nunca serão tratadas depois de
+// entregá-las ao PoisonHandler e devolve com atraso as demais, até o limite de entregas do consumer
+func (c *EventConsumer) settle(ctx context.Context, span trace.Span, msg jetstream.Msg, event subscription.DomainEvent, deliveries uint64, err error) {
+	defer span.End()
+
+	if err == nil || errors.Is(err, subscription.ErrEventPoisoned) {
+		if ackErr := msg.Ack(); ackErr != nil {
+			c.logger.Error(ctx, "EventConsumer", "Erro ao confirmar mensagem", ackErr, map[string]interface{}{
+				"subject": msg.Subject(),
+			})
+		}
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	logData := map[string]interface{}{
+		"subject":     msg.Subject(),
+		"deliveries":  deliveries,
+		"max_deliver": c.cfg.MaxDeliver,
+		"consumer":    c.cfg.Durable,
+		"unsupported": errors.Is(err, subscription.ErrUnsupportedEvent),
+	}
+
+	// Payloads que não decodificam ou fora do limite de entregas não voltam para a fila
+	exhausted := c.cfg.MaxDeliver > 0 && deliveries >= uint64(c.cfg.MaxDeliver)
+	if errors.Is(err, subscription.ErrUnsupportedEvent) || exhausted {
+		poisonErr := c.poison.HandlePoison(ctx, event, "", int(deliveries), err)
+		if poisonErr == nil || exhausted {
+			if poisonErr != nil {
+				c.logger.Error(ctx, "EventConsumer", "Erro ao tratar mensagem envenenada", poisonErr, logData)
+			}
+			c.logger.Error(ctx, "EventConsumer", "Mensagem encerrada sem nova entrega", err, logData)
+			if termErr := msg.Term(); termErr != nil {
+				c.logger.Error(ctx, "EventConsumer", "Erro ao encerrar mensagem", termErr, logData)
+			}
+			return
+		}
+
+		// A mensagem só é encerrada depois de chegar à dead-letter queue; até lá volta para a fila
+		err = fmt.Errorf("erro ao tratar mensagem envenenada: %w", poisonErr)
+	}
+
+	logData["redeliver_in"] = c.cfg.RetryDelay.String()
+	c.logger.Error(ctx, "EventConsumer", "Falha ao processar mensagem, nova entrega agendada", err, logData)
+	if nakErr := msg.NakWithDelay(c.cfg.RetryDelay); nakErr != nil {
+		c.logger.Error(ctx, "EventConsumer", "Erro ao devolver mensagem", nakErr, logData)
+	}
+}
+
+// decode converte a mensagem no evento de domínio correspondente ao tipo informado. Mensagens com
+// envelope CloudEvents (structured ou binary) usam o registro de tipos; as demais, o formato anterior
+func (c *EventConsumer) decode(ctx context.Context, msg jetstream.Msg) (subscription.DomainEvent, error) {
+	cloudEvent, err := subscription.NATSBinding.Decode(headerCarrier(msg.Headers()), msg.Data())
+	switch {
+	case err == nil:
+		return subscription.DecodeCloudEvent(cloudEvent)
+	case !errors.Is(err, subscription.ErrNotCloudEvent):
+		return nil, fmt.Errorf("%w: %w", subscription.ErrUnsupportedEvent, err)
+	}
+
+	eventType := msg.Headers().Get(EventTypeHeader)
+	if eventType == "" {
+		eventType = eventTypeFromSubject(msg.Subject())
+	}
+
+	var req subscription.InboundEventRequest
+	if err := json.Unmarshal(msg.Data(), &req); err != nil {
+		return nil, fmt.Errorf("%w: payload inválido para %s: %v", subscription.ErrUnsupportedEvent, eventType, err)
+	}
+	req.Type = eventType
+
+	return req.ToDomainEvent(logging.GetCorrelationID(ctx))
+}
+
+// undecodedEvent representa a mensagem que não pôde ser decodificada, preservando o corpo original
+// para inspeção na dead-letter queue
+func undecodedEvent(msg jetstream.Msg) subscription.DomainEvent {
+	headers := msg.Headers()
+
+	eventType := headers.Get(EventTypeHeader)
+	if eventType == "" {
+		eventType = eventTypeFromSubject(msg.Subject())
+	}
+
+	// O payload precisa ser JSON válido; corpos em outro formato são guardados como string
+	payload := msg.Data()
+	if !json.Valid(payload) {
+		payload, _ = json.Marshal(string(payload))
+	}
+
+	message := &subscription.OutboxMessage{
+		ID:            uuid.New().String(),
+		EventType:     eventType,
+		CorrelationID: headers.Get(CorrelationIDHeader),
+		Payload:       payload,
+		OccurredAt:    time.Now(),
+		CreatedAt:     time.Now(),
+	}
+	if metadata, err := msg.Metadata(); err == nil {
+		message.OccurredAt = metadata.Timestamp
+	}
+
+	return subscription.NewOutboxEvent(message)
+}
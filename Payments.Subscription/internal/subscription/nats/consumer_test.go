@@ -0,0 +1,295 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"payments-subscription/internal/subscription"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+var (
+	errTransient   = errors.New("falha transitória")
+	errDLQOffline  = errors.New("dead-letter queue indisponível")
+	alwaysFail     = 1 << 30
+	settleInterval = 100 * time.Millisecond
+)
+
+// fakeHandler trata CustomerCreated falhando nas primeiras chamadas
+type fakeHandler struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (h *fakeHandler) CanHandle(eventType string) bool {
+	return eventType == subscription.CustomerCreatedEventType
+}
+
+func (h *fakeHandler) Handle(context.Context, subscription.DomainEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= h.failures {
+		return errTransient
+	}
+	return nil
+}
+
+func (h *fakeHandler) callCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls
+}
+
+type poisonedEvent struct {
+	eventType string
+	attempts  int
+	cause     error
+}
+
+// recordingPoison grava os eventos envenenados falhando nas primeiras chamadas
+type recordingPoison struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	events   []poisonedEvent
+}
+
+func (p *recordingPoison) HandlePoison(_ context.Context, event subscription.DomainEvent, _ string, attempts int, cause error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls <= p.failures {
+		return errDLQOffline
+	}
+	p.events = append(p.events, poisonedEvent{eventType: event.EventType(), attempts: attempts, cause: cause})
+	return nil
+}
+
+func (p *recordingPoison) recorded() []poisonedEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]poisonedEvent(nil), p.events...)
+}
+
+// publishCustomerCreated publica o evento como o serviço de Customer faria
+func publishCustomerCreated(t *testing.T, js jetstream.JetStream, cfg Config) {
+	t.Helper()
+	cfg.SubjectPrefix = "customer.events"
+	publisher := NewEventPublisher(js, cfg, newTestTracer())
+
+	event := subscription.NewCustomerEvent(subscription.CustomerCreatedEventType, "sub-1", "customer-1", "ana@example.com", "corr-1", time.Now())
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+}
+
+// publishUnknownEvent publica um envelope válido de um tipo que nenhum decoder conhece
+func publishUnknownEvent(t *testing.T, js jetstream.JetStream, _ Config) {
+	t.Helper()
+	msg := natsgo.NewMsg("customer.events.CustomerDeleted")
+	msg.Header.Set("Content-Type", subscription.CloudEventsContentType)
+	msg.Data = []byte(`{"specversion":"1.0","id":"evt-1","source":"/payments-customer","type":"CustomerDeleted"}`)
+	if _, err := js.PublishMsg(context.Background(), msg); err != nil {
+		t.Fatalf("PublishMsg() error = %v", err)
+	}
+}
+
+// runConsumer executa o consumer em background até o fim do teste
+func runConsumer(t *testing.T, consumer *EventConsumer) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+}
+
+// subscribeTerminated conta as mensagens encerradas com Term pelo consumer
+func subscribeTerminated(t *testing.T, cfg Config) func() int {
+	t.Helper()
+	conn, err := natsgo.Connect(cfg.URL)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	var mu sync.Mutex
+	terminated := 0
+
+	subject := "$JS.EVENT.ADVISORY.CONSUMER.MSG_TERMINATED." + cfg.Stream + "." + cfg.Durable
+	_, err = conn.Subscribe(subject, func(*natsgo.Msg) {
+		mu.Lock()
+		defer mu.Unlock()
+		terminated++
+	})
+	if err == nil {
+		err = conn.Flush()
+	}
+	if err != nil {
+		t.Fatalf("Subscribe(%q) error = %v", subject, err)
+	}
+
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return terminated
+	}
+}
+
+// waitSettled aguarda o consumer entregar wantDeliveries mensagens sem nenhuma pendente de ack
+func waitSettled(t *testing.T, js jetstream.JetStream, cfg Config, wantDeliveries uint64) *jetstream.ConsumerInfo {
+	t.Helper()
+	ctx := context.Background()
+
+	var info *jetstream.ConsumerInfo
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if consumer, err := js.Consumer(ctx, cfg.Stream, cfg.Durable); err == nil {
+			if info, err = consumer.Info(ctx); err == nil &&
+				info.Delivered.Consumer >= wantDeliveries && info.NumAckPending == 0 && info.NumPending == 0 {
+				// Dá tempo para uma entrega além da esperada aparecer
+				time.Sleep(settleInterval)
+				info, _ = consumer.Info(ctx)
+				return info
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("consumer não concluiu %d entregas: %+v", wantDeliveries, info)
+	return nil
+}
+
+func TestEventConsumer(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxDeliver int
+		publish    func(t *testing.T, js jetstream.JetStream, cfg Config)
+		// Falhas iniciais do handler, do PoisonHandler do dispatcher e do PoisonHandler do consumer
+		handlerFailures        int
+		dispatcherPoisonErrors int
+		consumerPoisonErrors   int
+
+		wantHandlerCalls int
+		wantDeliveries   uint64
+		wantTerminated   int
+		// wantPoisoned é o tipo do evento entregue ao PoisonHandler do consumer, vazio se nenhum
+		wantPoisoned      string
+		wantPoisonedCause error
+	}{
+		{
+			name:             "processa e confirma a mensagem",
+			maxDeliver:       5,
+			publish:          publishCustomerCreated,
+			wantHandlerCalls: 1,
+			wantDeliveries:   1,
+		},
+		{
+			name:       "falha não envenenada devolve a mensagem para nova entrega",
+			maxDeliver: 5,
+			publish:    publishCustomerCreated,
+			// O dispatcher não consegue gravar o evento envenenado, então o consumer faz nak
+			handlerFailures:        1,
+			dispatcherPoisonErrors: alwaysFail,
+			wantHandlerCalls:       2,
+			wantDeliveries:         2,
+		},
+		{
+			name:              "evento não suportado vai para a dead-letter queue e é encerrado",
+			maxDeliver:        5,
+			publish:           publishUnknownEvent,
+			wantDeliveries:    1,
+			wantTerminated:    1,
+			wantPoisoned:      "CustomerDeleted",
+			wantPoisonedCause: subscription.ErrUnsupportedEvent,
+		},
+		{
+			name:                 "evento não suportado volta para a fila enquanto a dead-letter queue falha",
+			maxDeliver:           5,
+			publish:              publishUnknownEvent,
+			consumerPoisonErrors: 1,
+			wantDeliveries:       2,
+			wantTerminated:       1,
+			wantPoisoned:         "CustomerDeleted",
+			wantPoisonedCause:    subscription.ErrUnsupportedEvent,
+		},
+		{
+			name:                   "esgotar o MaxDeliver envia para a dead-letter queue e encerra",
+			maxDeliver:             2,
+			publish:                publishCustomerCreated,
+			handlerFailures:        alwaysFail,
+			dispatcherPoisonErrors: alwaysFail,
+			wantHandlerCalls:       2,
+			wantDeliveries:         2,
+			wantTerminated:         1,
+			wantPoisoned:           subscription.CustomerCreatedEventType,
+			wantPoisonedCause:      errDLQOffline,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js, cfg := runTestServer(t)
+			cfg.MaxDeliver = tt.maxDeliver
+			terminated := subscribeTerminated(t, cfg)
+
+			handler := &fakeHandler{failures: tt.handlerFailures}
+			dispatcher := subscription.NewEventDispatcher(
+				newTestTracer(),
+				1,
+				subscription.ExponentialRetryPolicy{MaxAttempts: 1},
+				&recordingPoison{failures: tt.dispatcherPoisonErrors},
+			)
+			dispatcher.Register(handler)
+			consumerPoison := &recordingPoison{failures: tt.consumerPoisonErrors}
+
+			tt.publish(t, js, cfg)
+			runConsumer(t, NewEventConsumer(js, cfg, newTestTracer(), dispatcher, consumerPoison))
+
+			info := waitSettled(t, js, cfg, tt.wantDeliveries)
+			if info.Delivered.Consumer != tt.wantDeliveries {
+				t.Errorf("deliveries = %d, want %d", info.Delivered.Consumer, tt.wantDeliveries)
+			}
+			if info.AckFloor.Stream != 1 {
+				t.Errorf("ack floor = %d, want 1", info.AckFloor.Stream)
+			}
+			if got := handler.callCount(); got != tt.wantHandlerCalls {
+				t.Errorf("handler calls = %d, want %d", got, tt.wantHandlerCalls)
+			}
+			if got := terminated(); got != tt.wantTerminated {
+				t.Errorf("terminated = %d, want %d", got, tt.wantTerminated)
+			}
+
+			poisoned := consumerPoison.recorded()
+			if tt.wantPoisoned == "" {
+				if len(poisoned) != 0 {
+					t.Errorf("poisoned = %+v, want none", poisoned)
+				}
+				return
+			}
+			if len(poisoned) != 1 {
+				t.Fatalf("poisoned = %+v, want 1 event", poisoned)
+			}
+			if poisoned[0].eventType != tt.wantPoisoned {
+				t.Errorf("poisoned event type = %q, want %q", poisoned[0].eventType, tt.wantPoisoned)
+			}
+			if poisoned[0].attempts != int(tt.wantDeliveries) {
+				t.Errorf("poisoned attempts = %d, want %d", poisoned[0].attempts, tt.wantDeliveries)
+			}
+			if !errors.Is(poisoned[0].cause, tt.wantPoisonedCause) {
+				t.Errorf("poisoned cause = %v, want %v", poisoned[0].cause, tt.wantPoisonedCause)
+			}
+		})
+	}
+}
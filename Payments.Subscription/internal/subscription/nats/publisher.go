@@ -0,0 +1,115 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/subscription"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EventPublisher publica eventos de domínio no JetStream, um subject por EventType
+type EventPublisher struct {
+	js             jetstream.JetStream
+	subjectPrefix  string
+	publishTimeout time.Duration
+	source         string
+	mode           subscription.CloudEventMode
+	tracer         trace.Tracer
+	logger         *logging.StructuredLogger
+}
+
+// NewEventPublisher cria uma nova instância do publisher JetStream
+func NewEventPublisher(js jetstream.JetStream, cfg Config, tracer trace.Tracer) *EventPublisher {
+	return &EventPublisher{
+		js:             js,
+		subjectPrefix:  cfg.SubjectPrefix,
+		publishTimeout: cfg.PublishTimeout,
+		source:         cfg.CloudEventSource,
+		mode:           cfg.CloudEventMode,
+		tracer:         tracer,
+		logger:         logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// Publish envia o evento e aguarda o ack do stream dentro do tempo configurado
+func (p *EventPublisher) Publish(ctx context.Context, event subscription.DomainEvent) (err error) {
+	subject := SubjectFor(p.subjectPrefix, event.EventType())
+
+	ctx, span := p.tracer.Start(ctx, "NATS.Publish "+subject, trace.WithSpanKind(trace.SpanKindProducer))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	span.SetAttributes(
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.destination.name", subject),
+		attribute.String("aggregate_id", event.AggregateID()),
+		attribute.String("event_type", event.EventType()),
+	)
+
+	cloudEvent, err := subscription.NewCloudEvent(ctx, p.source, event)
+	if err != nil {
+		return err
+	}
+
+	msg := natsgo.NewMsg(subject)
+	msg.Header.Set(EventTypeHeader, event.EventType())
+
+	msg.Data, err = subscription.NATSBinding.Encode(cloudEvent, p.mode, headerCarrier(msg.Header))
+	if err != nil {
+		return err
+	}
+
+	correlationID := event.CorrelationID()
+	if correlationID == "" {
+		correlationID = logging.GetCorrelationID(ctx)
+	}
+	if correlationID != "" {
+		msg.Header.Set(CorrelationIDHeader, correlationID)
+	}
+
+	// Propaga o span do publish para que o consumer continue o mesmo trace
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Header))
+
+	if p.publishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.publishTimeout)
+		defer cancel()
+	}
+
+	ack, err := p.js.PublishMsg(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("erro ao publicar evento %s no JetStream: %w", event.EventType(), err)
+	}
+
+	span.SetAttributes(
+		attribute.String("messaging.nats.stream", ack.Stream),
+		attribute.Int64("messaging.nats.sequence", int64(ack.Sequence)),
+	)
+
+	p.logger.Info(ctx, "EventPublished",
+		fmt.Sprintf("Event published: %s", event.EventType()),
+		map[string]interface{}{
+			"event_id":     event.EventID(),
+			"event_type":   event.EventType(),
+			"aggregate_id": event.AggregateID(),
+			"subject":      subject,
+			"stream":       ack.Stream,
+			"sequence":     ack.Sequence,
+		})
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"payments-subscription/internal/subscription"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTracer devolve um tracer que gera spans válidos, para que o traceparent seja propagado
+func newTestTracer() trace.Tracer {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return sdktrace.NewTracerProvider().Tracer("nats-test")
+}
+
+func TestEventPublisherPublish(t *testing.T) {
+	tests := []struct {
+		name string
+		mode subscription.CloudEventMode
+		// wantHeaders são os cabeçalhos do envelope exigidos pelo modo, além dos comuns
+		wantHeaders []string
+	}{
+		{"modo structured", subscription.CloudEventModeStructured, []string{"Content-Type"}},
+		{"modo binary", subscription.CloudEventModeBinary, []string{"ce-id", "ce-type", "ce-specversion", "Content-Type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js, cfg := runTestServer(t)
+			ctx := context.Background()
+
+			cfg.CloudEventMode = tt.mode
+			publisher := NewEventPublisher(js, cfg, newTestTracer())
+
+			event := subscription.NewCustomerEvent(subscription.CustomerCreatedEventType, "sub-1", "customer-1", "ana@example.com", "corr-1", time.Now())
+			if err := publisher.Publish(ctx, event); err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+
+			stream, err := js.Stream(ctx, cfg.Stream)
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			subject := SubjectFor(cfg.SubjectPrefix, event.EventType())
+			msg, err := stream.GetLastMsgForSubject(ctx, subject)
+			if err != nil {
+				t.Fatalf("GetLastMsgForSubject(%q) error = %v", subject, err)
+			}
+
+			headers := headerCarrier(msg.Header)
+			if got := headers.Get(EventTypeHeader); got != event.EventType() {
+				t.Errorf("header %s = %q, want %q", EventTypeHeader, got, event.EventType())
+			}
+			if got := headers.Get(CorrelationIDHeader); got != "corr-1" {
+				t.Errorf("header %s = %q, want %q", CorrelationIDHeader, got, "corr-1")
+			}
+			if got := headers.Get("traceparent"); got == "" {
+				t.Error("header traceparent ausente")
+			}
+			for _, key := range tt.wantHeaders {
+				if headers.Get(key) == "" {
+					t.Errorf("header %s ausente", key)
+				}
+			}
+
+			cloudEvent, err := subscription.NATSBinding.Decode(headers, msg.Data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			decoded, err := subscription.DecodeCloudEvent(cloudEvent)
+			if err != nil {
+				t.Fatalf("DecodeCloudEvent() error = %v", err)
+			}
+			if decoded.EventID() != event.EventID() {
+				t.Errorf("EventID = %q, want %q", decoded.EventID(), event.EventID())
+			}
+			if decoded.AggregateID() != "sub-1" {
+				t.Errorf("AggregateID = %q, want %q", decoded.AggregateID(), "sub-1")
+			}
+		})
+	}
+}
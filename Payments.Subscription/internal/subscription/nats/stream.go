@@ -0,0 +1,102 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"payments-subscription/internal/subscription"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Cabeçalhos gravados em cada mensagem além do contexto de trace (traceparent/tracestate)
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	EventTypeHeader     = "event-type"
+)
+
+// Config reúne as configurações de conexão, stream, publisher e consumer do JetStream
+type Config struct {
+	URL              string
+	Stream           string
+	StreamSubjects   []string
+	SubjectPrefix    string
+	ConsumerSubjects []string
+	Durable          string
+	MaxDeliver       int
+	AckWait          time.Duration
+	RetryDelay       time.Duration
+	PublishTimeout   time.Duration
+	// CloudEventSource é o atributo source dos envelopes CloudEvents publicados
+	CloudEventSource string
+	// CloudEventMode define se o envelope vai inteiro no corpo (structured) ou nos cabeçalhos ce- (binary)
+	CloudEventMode subscription.CloudEventMode
+}
+
+// Connect abre a conexão com o servidor NATS e cria o contexto JetStream
+func Connect(cfg Config) (*natsgo.Conn, jetstream.JetStream, error) {
+	conn, err := natsgo.Connect(cfg.URL, natsgo.Name("subscription-service"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao conectar no NATS: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("erro ao criar contexto JetStream: %w", err)
+	}
+
+	return conn, js, nil
+}
+
+// EnsureStream cria ou atualiza o stream que armazena os subjects publicados e consumidos
+func EnsureStream(ctx context.Context, js jetstream.JetStream, cfg Config) error {
+	_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.Stream,
+		Subjects: cfg.StreamSubjects,
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao configurar stream %s: %w", cfg.Stream, err)
+	}
+	return nil
+}
+
+// SubjectFor deriva o subject do evento a partir do prefixo e do EventType (ex: subscription.events.SubscriptionActivated)
+func SubjectFor(prefix, eventType string) string {
+	return prefix + "." + eventType
+}
+
+// eventTypeFromSubject recupera o EventType do último token do subject
+func eventTypeFromSubject(subject string) string {
+	return subject[strings.LastIndex(subject, ".")+1:]
+}
+
+// headerCarrier adapta natsgo.Header ao propagation.TextMapCarrier do OpenTelemetry preservando
+// as chaves como foram escritas (traceparent em minúsculas, como outros clientes esperam)
+type headerCarrier natsgo.Header
+
+// Get retorna o primeiro valor do cabeçalho informado
+func (c headerCarrier) Get(key string) string {
+	if values := c[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set substitui o valor do cabeçalho informado
+func (c headerCarrier) Set(key, value string) {
+	c[key] = []string{value}
+}
+
+// Keys lista as chaves dos cabeçalhos
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,105 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"payments-subscription/internal/subscription"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natstest "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// newTestConfig usa um stream que guarda tanto os eventos publicados quanto os consumidos
+func newTestConfig(url string) Config {
+	return Config{
+		URL:              url,
+		Stream:           "SUBSCRIPTIONS",
+		StreamSubjects:   []string{"subscription.events.>", "customer.events.>"},
+		SubjectPrefix:    "subscription.events",
+		ConsumerSubjects: []string{"customer.events.>"},
+		Durable:          "subscription-test",
+		MaxDeliver:       5,
+		AckWait:          5 * time.Second,
+		RetryDelay:       10 * time.Millisecond,
+		PublishTimeout:   5 * time.Second,
+		CloudEventSource: "/payments-subscription",
+		CloudEventMode:   subscription.CloudEventModeStructured,
+	}
+}
+
+// runTestServer sobe um servidor NATS embutido com JetStream, conecta e cria o stream
+func runTestServer(t *testing.T) (jetstream.JetStream, Config) {
+	t.Helper()
+
+	opts := natstest.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natstest.RunServer(&opts)
+	t.Cleanup(srv.Shutdown)
+
+	cfg := newTestConfig(srv.ClientURL())
+	conn, js, err := Connect(cfg)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(conn.Close)
+
+	if err := EnsureStream(context.Background(), js, cfg); err != nil {
+		t.Fatalf("EnsureStream() error = %v", err)
+	}
+	return js, cfg
+}
+
+func TestEnsureStream(t *testing.T) {
+	js, cfg := runTestServer(t)
+	ctx := context.Background()
+
+	// A segunda chamada atualiza o stream existente em vez de falhar
+	cfg.StreamSubjects = append(cfg.StreamSubjects, "plan.events.>")
+	if err := EnsureStream(ctx, js, cfg); err != nil {
+		t.Fatalf("EnsureStream() update error = %v", err)
+	}
+
+	stream, err := js.Stream(ctx, cfg.Stream)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info() error = %v", err)
+	}
+	if got := len(info.Config.Subjects); got != 3 {
+		t.Errorf("subjects = %v, want %v", info.Config.Subjects, cfg.StreamSubjects)
+	}
+	if info.Config.Storage != jetstream.FileStorage {
+		t.Errorf("storage = %s, want %s", info.Config.Storage, jetstream.FileStorage)
+	}
+}
+
+func TestSubjectFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefix    string
+		eventType string
+		want      string
+	}{
+		{"evento de subscription", "subscription.events", "SubscriptionActivated", "subscription.events.SubscriptionActivated"},
+		{"evento de customer", "customer.events", subscription.CustomerCreatedEventType, "customer.events.CustomerCreated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject := SubjectFor(tt.prefix, tt.eventType)
+			if subject != tt.want {
+				t.Errorf("SubjectFor() = %q, want %q", subject, tt.want)
+			}
+			if got := eventTypeFromSubject(subject); got != tt.eventType {
+				t.Errorf("eventTypeFromSubject(%q) = %q, want %q", subject, got, tt.eventType)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package subscription
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OnboardingMode define como o customer da subscription é obtido na criação
+type OnboardingMode string
+
+const (
+	// OnboardingModeSync resolve o customer com chamadas HTTP ao serviço de Customer durante a requisição
+	OnboardingModeSync OnboardingMode = "sync"
+	// OnboardingModeAsync cria a subscription pendente e aguarda o evento do serviço de Customer
+	OnboardingModeAsync OnboardingMode = "async"
+)
+
+// Erros do onboarding
+var (
+	ErrInvalidOnboardingMode = errors.New("modo de onboarding inválido")
+	ErrCustomerAlreadyLinked = errors.New("subscription já possui customer vinculado")
+	ErrNoCustomerEventSource = errors.New("onboarding assíncrono sem origem para os eventos do serviço de Customer")
+)
+
+// ParseOnboardingMode converte o valor da configuração em OnboardingMode
+func ParseOnboardingMode(value string) (OnboardingMode, error) {
+	switch mode := OnboardingMode(value); mode {
+	case OnboardingModeSync, OnboardingModeAsync:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidOnboardingMode, value)
+	}
+}
+
+// RequireCustomerEventSource garante que o modo async tenha por onde receber CustomerCreated e
+// CustomerVerified: o consumer do broker ou o POST /internal/events com o segredo configurado. Quem
+// consome SubscriptionRequested e responde com esses eventos é o serviço de Customer; sem a resposta,
+// as subscriptions ficam pendentes indefinidamente
+func (m OnboardingMode) RequireCustomerEventSource(consumerEnabled, internalEventsEnabled bool) error {
+	if m == OnboardingModeAsync && !consumerEnabled && !internalEventsEnabled {
+		return ErrNoCustomerEventSource
+	}
+	return nil
+}
+
+// RequestSubscription cria uma subscription pendente que aguarda o serviço de Customer. O evento
+// SubscriptionRequested leva os dados do customer e o trial solicitado só começa quando ele for vinculado
+func RequestSubscription(
+	planID string,
+	billingInterval BillingInterval,
+	customerID, email, name string,
+	trialDays int,
+	correlationID string,
+) (*Subscription, error) {
+	if err := validateSubscriptionData(planID); err != nil {
+		return nil, err
+	}
+
+	if trialDays < 0 {
+		return nil, ErrInvalidTrialDays
+	}
+
+	pID, _ := NewPlanID(planID)
+	now := time.Now()
+
+	subscription := &Subscription{
+		id:                 NewSubscriptionID(),
+		planID:             pID,
+		status:             SubscriptionStatusPending,
+		billingInterval:    billingInterval,
+		requestedTrialDays: trialDays,
+		createdAt:          now,
+		updatedAt:          now,
+		events:             make([]DomainEvent, 0),
+	}
+
+	subscription.addEvent(SubscriptionRequestedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionRequested",
+			aggregateID:   subscription.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PlanID:     planID,
+		CustomerID: customerID,
+		Email:      email,
+		Name:       name,
+	})
+
+	return subscription, nil
+}
+
+// AwaitingCustomer indica se a subscription ainda aguarda o customer do onboarding assíncrono
+func (s *Subscription) AwaitingCustomer() bool {
+	return s.customerID.value == ""
+}
+
+// RequestedTrialDays retorna o trial solicitado que será iniciado quando o customer for vinculado
+func (s *Subscription) RequestedTrialDays() int {
+	return s.requestedTrialDays
+}
+
+// LinkCustomer vincula o customer informado pelo serviço de Customer, marca a subscription como pronta
+// para ativação e inicia o trial solicitado na criação, se houver
+func (s *Subscription) LinkCustomer(customerID string, now time.Time, correlationID string) error {
+	if customerID == "" {
+		return ErrInvalidCustomerID
+	}
+
+	if !s.AwaitingCustomer() {
+		return ErrCustomerAlreadyLinked
+	}
+
+	if s.status != SubscriptionStatusPending {
+		return ErrInvalidStatusTransition
+	}
+
+	s.customerID = CustomerID{value: customerID}
+	s.updatedAt = now
+
+	if err := s.MarkAsReadyForActivation(correlationID); err != nil {
+		return err
+	}
+
+	if s.requestedTrialDays > 0 {
+		trialDays := s.requestedTrialDays
+		s.requestedTrialDays = 0
+		return s.StartTrial(trialDays, now, correlationID)
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memorySubscriptionRepository guarda as subscriptions em memória e conta as atualizações
+type memorySubscriptionRepository struct {
+	subscriptions map[string]*Subscription
+	updates       int
+}
+
+func (r *memorySubscriptionRepository) Create(_ context.Context, subscription *Subscription) error {
+	r.subscriptions[subscription.ID().String()] = subscription
+	return nil
+}
+
+func (r *memorySubscriptionRepository) GetByID(_ context.Context, id SubscriptionID) (*Subscription, error) {
+	subscription, ok := r.subscriptions[id.String()]
+	if !ok {
+		return nil, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (r *memorySubscriptionRepository) GetByCustomerID(context.Context, CustomerID, ListSubscriptionsQuery) (*SubscriptionPage, error) {
+	return &SubscriptionPage{}, nil
+}
+
+func (r *memorySubscriptionRepository) Update(_ context.Context, subscription *Subscription) error {
+	r.updates++
+	r.subscriptions[subscription.ID().String()] = subscription
+	return nil
+}
+
+func (r *memorySubscriptionRepository) List(context.Context, ListSubscriptionsQuery) (*SubscriptionPage, error) {
+	return &SubscriptionPage{}, nil
+}
+
+func (r *memorySubscriptionRepository) ClaimDueSubscriptions(context.Context, DueSubscriptionsQuery) ([]*Subscription, error) {
+	return nil, nil
+}
+
+func (r *memorySubscriptionRepository) ReleaseLease(context.Context, SubscriptionID, string) error {
+	return nil
+}
+
+// newOnboardingSubscription reconstrói uma subscription do onboarding assíncrono
+func newOnboardingSubscription(t *testing.T, status SubscriptionStatus, customerID string, requestedTrialDays int) *Subscription {
+	t.Helper()
+	subscription, err := ReconstructSubscription(SubscriptionSnapshot{
+		ID:                 "sub-1",
+		PlanID:             "basic",
+		CustomerID:         customerID,
+		Status:             status,
+		BillingInterval:    string(BillingIntervalMonthly),
+		RequestedTrialDays: requestedTrialDays,
+	})
+	if err != nil {
+		t.Fatalf("ReconstructSubscription() error = %v", err)
+	}
+	return subscription
+}
+
+func eventTypes(events []DomainEvent) []string {
+	var types []string
+	for _, event := range events {
+		types = append(types, event.EventType())
+	}
+	return types
+}
+
+func TestSubscriptionLinkCustomer(t *testing.T) {
+	now := time.Date(2026, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		status             SubscriptionStatus
+		currentCustomerID  string
+		requestedTrialDays int
+		customerID         string
+		wantErr            error
+		wantStatus         SubscriptionStatus
+		wantEvents         []string
+	}{
+		{
+			name:       "vincula o customer e marca como pronta para ativação",
+			status:     SubscriptionStatusPending,
+			customerID: "customer-1",
+			wantStatus: SubscriptionStatusPending,
+			wantEvents: []string{"SubscriptionReadyForActivation"},
+		},
+		{
+			name:               "inicia o trial solicitado na criação",
+			status:             SubscriptionStatusPending,
+			requestedTrialDays: 7,
+			customerID:         "customer-1",
+			wantStatus:         SubscriptionStatusTrialing,
+			wantEvents:         []string{"SubscriptionReadyForActivation", "SubscriptionTrialStarted"},
+		},
+		{
+			name:       "rejeita customer vazio",
+			status:     SubscriptionStatusPending,
+			wantErr:    ErrInvalidCustomerID,
+			wantStatus: SubscriptionStatusPending,
+		},
+		{
+			name:              "rejeita subscription já vinculada",
+			status:            SubscriptionStatusPending,
+			currentCustomerID: "customer-1",
+			customerID:        "customer-2",
+			wantErr:           ErrCustomerAlreadyLinked,
+			wantStatus:        SubscriptionStatusPending,
+		},
+		{
+			name:       "rejeita subscription que não está pendente",
+			status:     SubscriptionStatusCancelled,
+			customerID: "customer-1",
+			wantErr:    ErrInvalidStatusTransition,
+			wantStatus: SubscriptionStatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := newOnboardingSubscription(t, tt.status, tt.currentCustomerID, tt.requestedTrialDays)
+
+			err := subscription.LinkCustomer(tt.customerID, now, "corr-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LinkCustomer() error = %v, want %v", err, tt.wantErr)
+			}
+			if subscription.Status() != tt.wantStatus {
+				t.Errorf("status = %s, want %s", subscription.Status(), tt.wantStatus)
+			}
+			if got := eventTypes(subscription.Events()); !equalEventTypes(got, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", got, tt.wantEvents)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if subscription.CustomerID().String() != tt.customerID {
+				t.Errorf("customer = %q, want %q", subscription.CustomerID().String(), tt.customerID)
+			}
+			if subscription.RequestedTrialDays() != 0 {
+				t.Errorf("RequestedTrialDays() = %d, want 0 after linking", subscription.RequestedTrialDays())
+			}
+		})
+	}
+}
+
+func TestCustomerOnboardingHandlerHandle(t *testing.T) {
+	tests := []struct {
+		name              string
+		currentCustomerID string
+		// missing indica que a subscription do evento não existe
+		missing     bool
+		eventType   string
+		customerID  string
+		wantErr     error
+		wantUpdates int
+		wantLinked  string
+	}{
+		{
+			name:        "vincula o customer da subscription pendente",
+			eventType:   CustomerCreatedEventType,
+			customerID:  "customer-1",
+			wantUpdates: 1,
+			wantLinked:  "customer-1",
+		},
+		{
+			name:              "evento repetido para o mesmo customer é ignorado",
+			currentCustomerID: "customer-1",
+			eventType:         CustomerVerifiedEventType,
+			customerID:        "customer-1",
+			wantLinked:        "customer-1",
+		},
+		{
+			name:              "evento de outro customer é rejeitado",
+			currentCustomerID: "customer-1",
+			eventType:         CustomerCreatedEventType,
+			customerID:        "customer-2",
+			wantErr:           ErrCustomerAlreadyLinked,
+			wantLinked:        "customer-1",
+		},
+		{
+			name:       "subscription inexistente devolve erro",
+			missing:    true,
+			eventType:  CustomerCreatedEventType,
+			customerID: "customer-1",
+			wantErr:    ErrSubscriptionNotFound,
+		},
+		{
+			name:       "tipo de evento não suportado",
+			eventType:  "CustomerDeleted",
+			customerID: "customer-1",
+			wantErr:    ErrUnsupportedEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := newOnboardingSubscription(t, SubscriptionStatusPending, tt.currentCustomerID, 0)
+			repository := &memorySubscriptionRepository{subscriptions: map[string]*Subscription{}}
+			if !tt.missing {
+				repository.subscriptions[subscription.ID().String()] = subscription
+			}
+			handler := NewCustomerOnboardingHandler(repository)
+
+			event := NewCustomerEvent(tt.eventType, "sub-1", tt.customerID, "ana@example.com", "corr-1", time.Now())
+			err := handler.Handle(context.Background(), event)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Handle() error = %v, want %v", err, tt.wantErr)
+			}
+			if repository.updates != tt.wantUpdates {
+				t.Errorf("updates = %d, want %d", repository.updates, tt.wantUpdates)
+			}
+			if got := subscription.CustomerID().String(); got != tt.wantLinked {
+				t.Errorf("customer = %q, want %q", got, tt.wantLinked)
+			}
+		})
+	}
+}
+
+func TestOnboardingModeRequireCustomerEventSource(t *testing.T) {
+	tests := []struct {
+		name                  string
+		mode                  OnboardingMode
+		consumerEnabled       bool
+		internalEventsEnabled bool
+		wantErr               error
+	}{
+		{"sync não depende de eventos", OnboardingModeSync, false, false, nil},
+		{"async com consumer", OnboardingModeAsync, true, false, nil},
+		{"async com POST /internal/events", OnboardingModeAsync, false, true, nil},
+		{"async sem origem de eventos", OnboardingModeAsync, false, false, ErrNoCustomerEventSource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.mode.RequireCustomerEventSource(tt.consumerEnabled, tt.internalEventsEnabled)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequireCustomerEventSource() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func equalEventTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
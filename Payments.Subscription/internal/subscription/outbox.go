@@ -0,0 +1,226 @@
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// OutboxMessage representa um evento de domínio gravado no outbox aguardando publicação
+type OutboxMessage struct {
+	ID            string
+	EventID       string
+	AggregateID   string
+	EventType     string
+	CorrelationID string
+	TraceParent   string
+	Payload       []byte
+	OccurredAt    time.Time
+	Attempts      int
+	LastError     string
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
+}
+
+// NewOutboxMessage cria uma mensagem de outbox a partir de um evento de domínio, guardando o
+// contexto de trace ativo para que a publicação continue o trace da requisição
+func NewOutboxMessage(ctx context.Context, event DomainEvent) (*OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento %s: %w", event.EventType(), err)
+	}
+
+	trace := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, trace)
+
+	return &OutboxMessage{
+		ID:            uuid.New().String(),
+		EventID:       event.EventID(),
+		AggregateID:   event.AggregateID(),
+		EventType:     event.EventType(),
+		CorrelationID: event.CorrelationID(),
+		TraceParent:   trace.Get("traceparent"),
+		Payload:       payload,
+		OccurredAt:    event.OccurredAt(),
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// OutboxRepository define o contrato para leitura e marcação das mensagens do outbox
+type OutboxRepository interface {
+	// ClaimPending reserva mensagens pendentes para o relay informado durante o período de lease, em
+	// ordem de criação. Uma mensagem não é reservada enquanto outra mais antiga do mesmo agregado já
+	// falhou ou está reservada por outro relay, preservando a ordem dos eventos de cada agregado
+	ClaimPending(ctx context.Context, owner string, limit, maxAttempts int, lease time.Duration) ([]*OutboxMessage, error)
+
+	// Release libera o lease sem contar tentativa, usado quando uma mensagem anterior do agregado falhou
+	Release(ctx context.Context, id string) error
+
+	// MarkPublished marca a mensagem como publicada
+	MarkPublished(ctx context.Context, id string, publishedAt time.Time) error
+
+	// MarkFailed incrementa as tentativas e registra o erro da última publicação
+	MarkFailed(ctx context.Context, id string, publishErr error) error
+}
+
+// OutboxEvent adapta uma OutboxMessage para o contrato de DomainEvent usado pelos publishers
+type OutboxEvent struct {
+	message *OutboxMessage
+}
+
+// NewOutboxEvent cria um DomainEvent a partir de uma mensagem do outbox
+func NewOutboxEvent(message *OutboxMessage) OutboxEvent {
+	return OutboxEvent{message: message}
+}
+
+func (e OutboxEvent) EventType() string     { return e.message.EventType }
+func (e OutboxEvent) AggregateID() string   { return e.message.AggregateID }
+func (e OutboxEvent) OccurredAt() time.Time { return e.message.OccurredAt }
+func (e OutboxEvent) CorrelationID() string { return e.message.CorrelationID }
+
+// EventID retorna o ID do evento de domínio; mensagens gravadas antes da coluna event_id usam o ID da mensagem
+func (e OutboxEvent) EventID() string {
+	if e.message.EventID != "" {
+		return e.message.EventID
+	}
+	return e.message.ID
+}
+
+// MarshalJSON retorna o payload original gravado no outbox
+func (e OutboxEvent) MarshalJSON() ([]byte, error) {
+	return e.message.Payload, nil
+}
+
+// OutboxRelay drena o outbox publicando as mensagens pendentes (entrega at-least-once)
+type OutboxRelay struct {
+	repository   OutboxRepository
+	publisher    EventPublisher
+	deadLetters  DeadLetterRepository
+	owner        string
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	lease        time.Duration
+	logger       *logging.StructuredLogger
+}
+
+// NewOutboxRelay cria uma nova instância do relay do outbox. Mensagens que esgotam maxAttempts são
+// copiadas para a dead-letter queue
+func NewOutboxRelay(repository OutboxRepository, publisher EventPublisher, deadLetters DeadLetterRepository, pollInterval time.Duration, batchSize, maxAttempts int) *OutboxRelay {
+	return &OutboxRelay{
+		repository:   repository,
+		publisher:    publisher,
+		deadLetters:  deadLetters,
+		owner:        uuid.New().String(),
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		maxAttempts:  maxAttempts,
+		lease:        pollInterval * 10,
+		logger:       logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// Run executa o relay até o contexto ser cancelado
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessPending(ctx); err != nil {
+			r.logger.Error(ctx, "OutboxRelay", "Erro ao processar outbox", err, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending publica um lote de mensagens pendentes e retorna quantas foram publicadas
+func (r *OutboxRelay) ProcessPending(ctx context.Context) (int, error) {
+	messages, err := r.repository.ClaimPending(ctx, r.owner, r.batchSize, r.maxAttempts, r.lease)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar mensagens pendentes do outbox: %w", err)
+	}
+
+	published := 0
+	// Agregados com uma mensagem que falhou neste lote: as seguintes aguardam a próxima tentativa dela
+	blocked := make(map[string]bool)
+
+	for _, message := range messages {
+		if blocked[message.AggregateID] {
+			if err := r.repository.Release(ctx, message.ID); err != nil {
+				return published, fmt.Errorf("erro ao liberar mensagem %s: %w", message.ID, err)
+			}
+			continue
+		}
+
+		msgCtx := logging.WithCorrelationID(ctx, message.CorrelationID)
+		// Continua o trace da requisição que gravou o evento
+		msgCtx = propagation.TraceContext{}.Extract(msgCtx, propagation.MapCarrier{"traceparent": message.TraceParent})
+
+		if err := r.publisher.Publish(msgCtx, NewOutboxEvent(message)); err != nil {
+			blocked[message.AggregateID] = true
+
+			r.logger.Error(msgCtx, "OutboxRelay",
+				fmt.Sprintf("Falha ao publicar evento %s (tentativa %d)", message.EventType, message.Attempts+1),
+				err, map[string]interface{}{
+					"outbox_id":    message.ID,
+					"aggregate_id": message.AggregateID,
+					"attempts":     message.Attempts + 1,
+				})
+
+			if markErr := r.repository.MarkFailed(ctx, message.ID, err); markErr != nil {
+				return published, fmt.Errorf("erro ao registrar falha da mensagem %s: %w", message.ID, markErr)
+			}
+
+			if message.Attempts+1 >= r.maxAttempts {
+				r.deadLetter(msgCtx, message, err)
+			}
+			continue
+		}
+
+		if err := r.repository.MarkPublished(ctx, message.ID, time.Now()); err != nil {
+			return published, fmt.Errorf("erro ao marcar mensagem %s como publicada: %w", message.ID, err)
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// deadLetter copia para a dead-letter queue a mensagem que esgotou as tentativas de publicação. A
+// mensagem continua no outbox, fora da fila de publicação, então a falha aqui apenas é registrada
+func (r *OutboxRelay) deadLetter(ctx context.Context, message *OutboxMessage, publishErr error) {
+	logData := map[string]interface{}{
+		"outbox_id":    message.ID,
+		"aggregate_id": message.AggregateID,
+		"event_type":   message.EventType,
+		"attempts":     message.Attempts + 1,
+	}
+
+	if r.deadLetters == nil {
+		r.logger.Error(ctx, "OutboxRelay", "Mensagem esgotou as tentativas de publicação", publishErr, logData)
+		return
+	}
+
+	deadLetter, err := NewDeadLetterEvent(ctx, DeadLetterSourcePublish, NewOutboxEvent(message), "", message.Attempts+1, publishErr)
+	if err == nil {
+		err = r.deadLetters.Create(ctx, deadLetter)
+	}
+	if err != nil {
+		r.logger.Error(ctx, "OutboxRelay", "Erro ao enviar mensagem para a dead-letter queue", err, logData)
+		return
+	}
+
+	logData["dead_letter_id"] = deadLetter.ID
+	r.logger.Error(ctx, "OutboxRelay", "Mensagem enviada para a dead-letter queue", publishErr, logData)
+}
@@ -0,0 +1,210 @@
+package subscription
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// PlanChangeTiming define quando a troca de plano passa a valer
+type PlanChangeTiming string
+
+const (
+	PlanChangeImmediate   PlanChangeTiming = "immediate"
+	PlanChangeEndOfPeriod PlanChangeTiming = "end_of_period"
+)
+
+// Erros da troca de plano
+var (
+	ErrSamePlan                = errors.New("subscription já está neste plano")
+	ErrInvalidPlanChangeTiming = errors.New("momento da troca de plano inválido")
+	ErrCurrencyMismatch        = errors.New("planos com moedas diferentes não podem ser prorrateados")
+	// ErrBillingIntervalChange indica troca imediata para um plano com outro intervalo de cobrança, que
+	// não pode ser prorrateada sobre o período corrente e precisa ser agendada para o fim do período
+	ErrBillingIntervalChange = errors.New("troca imediata entre intervalos de cobrança diferentes não é permitida")
+)
+
+// PlanPricing é o preço por período de um plano, na menor unidade da moeda
+type PlanPricing struct {
+	Amount   int64
+	Currency string
+}
+
+// PlanChangeTarget descreve o plano de destino de uma troca
+type PlanChangeTarget struct {
+	PlanID          PlanID
+	BillingInterval BillingInterval
+	Pricing         PlanPricing
+}
+
+type SubscriptionPlanChangeScheduledEvent struct {
+	BaseEvent
+	PreviousPlanID string    `json:"previous_plan_id"`
+	NewPlanID      string    `json:"new_plan_id"`
+	EffectiveAt    time.Time `json:"effective_at"`
+}
+
+type SubscriptionPlanChangedEvent struct {
+	BaseEvent
+	PreviousPlanID  string           `json:"previous_plan_id"`
+	NewPlanID       string           `json:"new_plan_id"`
+	Timing          PlanChangeTiming `json:"timing"`
+	ProrationAmount int64            `json:"proration_amount"`
+	Currency        string           `json:"currency"`
+}
+
+// PendingPlanID retorna o plano agendado para o próximo período (vazio se não houver)
+func (s *Subscription) PendingPlanID() PlanID {
+	return s.pendingPlanID
+}
+
+// PendingBillingInterval retorna o intervalo do plano agendado
+func (s *Subscription) PendingBillingInterval() BillingInterval {
+	return s.pendingBillingInterval
+}
+
+// HasPendingPlanChange indica se há troca de plano agendada para o fim do período
+func (s *Subscription) HasPendingPlanChange() bool {
+	return s.pendingPlanID.value != ""
+}
+
+// ChangePlan troca o plano da subscription. Na troca imediata retorna o valor prorateado do
+// restante do período (positivo = cobrança, negativo = crédito); no fim do período retorna zero
+// e a troca é aplicada na próxima renovação
+func (s *Subscription) ChangePlan(target PlanChangeTarget, current PlanPricing, timing PlanChangeTiming, now time.Time, correlationID string) (int64, error) {
+	if target.PlanID == s.planID {
+		return 0, ErrSamePlan
+	}
+
+	switch s.status {
+	case SubscriptionStatusActive, SubscriptionStatusTrialing, SubscriptionStatusPending:
+	default:
+		return 0, ErrInvalidStatusTransition
+	}
+
+	switch timing {
+	case PlanChangeImmediate:
+		return s.changePlanImmediately(target, current, now, correlationID)
+	case PlanChangeEndOfPeriod:
+		return 0, s.schedulePlanChange(target, now, correlationID)
+	default:
+		return 0, ErrInvalidPlanChangeTiming
+	}
+}
+
+// changePlanImmediately aplica o novo plano agora, prorateando o restante do período corrente. Com a
+// cobrança iniciada, só planos do mesmo intervalo são aceitos: os preços são por intervalo e o período
+// corrente foi aberto pelo intervalo atual
+func (s *Subscription) changePlanImmediately(target PlanChangeTarget, current PlanPricing, now time.Time, correlationID string) (int64, error) {
+	var proration int64
+	if s.status == SubscriptionStatusActive && s.HasStartedBilling() {
+		if target.BillingInterval != s.billingInterval {
+			return 0, ErrBillingIntervalChange
+		}
+		if current.Currency != target.Pricing.Currency {
+			return 0, ErrCurrencyMismatch
+		}
+		proration = CalculateProration(current.Amount, target.Pricing.Amount, s.currentPeriodStart, s.currentPeriodEnd, now)
+	}
+
+	previousPlanID := s.planID
+	s.planID = target.PlanID
+	// Sem cobrança iniciada não há período aberto, então o novo intervalo já vale para o primeiro período
+	s.billingInterval = target.BillingInterval
+	s.clearPendingPlanChange()
+	s.updatedAt = now
+
+	s.addEvent(SubscriptionPlanChangedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionPlanChanged",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PreviousPlanID:  previousPlanID.String(),
+		NewPlanID:       target.PlanID.String(),
+		Timing:          PlanChangeImmediate,
+		ProrationAmount: proration,
+		Currency:        target.Pricing.Currency,
+	})
+
+	return proration, nil
+}
+
+// schedulePlanChange agenda o novo plano para o fim do período corrente
+func (s *Subscription) schedulePlanChange(target PlanChangeTarget, now time.Time, correlationID string) error {
+	if s.status != SubscriptionStatusActive || !s.HasStartedBilling() {
+		return ErrInvalidStatusTransition
+	}
+
+	s.pendingPlanID = target.PlanID
+	s.pendingBillingInterval = target.BillingInterval
+	s.updatedAt = now
+
+	s.addEvent(SubscriptionPlanChangeScheduledEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionPlanChangeScheduled",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PreviousPlanID: s.planID.String(),
+		NewPlanID:      target.PlanID.String(),
+		EffectiveAt:    s.currentPeriodEnd,
+	})
+
+	return nil
+}
+
+// applyPendingPlanChange efetiva a troca agendada; chamado na renovação antes de abrir o novo período
+func (s *Subscription) applyPendingPlanChange(now time.Time, correlationID string) {
+	if !s.HasPendingPlanChange() {
+		return
+	}
+
+	previousPlanID := s.planID
+	s.planID = s.pendingPlanID
+	s.billingInterval = s.pendingBillingInterval
+	s.clearPendingPlanChange()
+
+	s.addEvent(SubscriptionPlanChangedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionPlanChanged",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PreviousPlanID: previousPlanID.String(),
+		NewPlanID:      s.planID.String(),
+		Timing:         PlanChangeEndOfPeriod,
+	})
+}
+
+// clearPendingPlanChange remove a troca agendada
+func (s *Subscription) clearPendingPlanChange() {
+	s.pendingPlanID = PlanID{}
+	s.pendingBillingInterval = BillingInterval{}
+}
+
+// CalculateProration calcula a diferença prorateada entre o preço novo e o atual para o restante
+// do período [periodStart, periodEnd) a partir de at. Positivo = cobrança, negativo = crédito
+func CalculateProration(currentAmount, newAmount int64, periodStart, periodEnd, at time.Time) int64 {
+	total := periodEnd.Sub(periodStart)
+	if total <= 0 || !at.Before(periodEnd) {
+		return 0
+	}
+
+	remaining := periodEnd.Sub(at)
+	if remaining > total {
+		remaining = total
+	}
+
+	fraction := float64(remaining) / float64(total)
+	credit := int64(math.Round(float64(currentAmount) * fraction))
+	charge := int64(math.Round(float64(newAmount) * fraction))
+
+	return charge - credit
+}
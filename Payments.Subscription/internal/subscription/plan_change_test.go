@@ -0,0 +1,212 @@
+package subscription
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCalculateProration(t *testing.T) {
+	periodStart := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := periodEnd.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name          string
+		currentAmount int64
+		newAmount     int64
+		start, end    time.Time
+		at            time.Time
+		want          int64
+	}{
+		{"upgrade no dia 0 cobra a diferença cheia", 3000, 6000, periodStart, periodEnd, periodStart, 3000},
+		{"downgrade no dia 0 credita a diferença cheia", 6000, 3000, periodStart, periodEnd, periodStart, -3000},
+		{"upgrade no meio do período cobra metade", 3000, 6000, periodStart, periodEnd, periodStart.AddDate(0, 0, 15), 1500},
+		{"upgrade no último dia cobra um dia", 3000, 6000, periodStart, periodEnd, lastDay, 100},
+		{"downgrade no último dia credita um dia", 6000, 3000, periodStart, periodEnd, lastDay, -100},
+		{"troca no fim exato do período não prorrateia", 3000, 6000, periodStart, periodEnd, periodEnd, 0},
+		{"troca após o fim do período não prorrateia", 3000, 6000, periodStart, periodEnd, periodEnd.Add(time.Hour), 0},
+		{"troca antes do início limita ao período inteiro", 3000, 6000, periodStart, periodEnd, periodStart.Add(-time.Hour), 3000},
+		{"mesmo preço não gera cobrança", 3000, 3000, periodStart, periodEnd, periodStart.AddDate(0, 0, 10), 0},
+		{"período vazio não prorrateia", 3000, 6000, periodStart, periodStart, periodStart, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateProration(tt.currentAmount, tt.newAmount, tt.start, tt.end, tt.at)
+			if got != tt.want {
+				t.Errorf("CalculateProration() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionChangePlan(t *testing.T) {
+	periodStart := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)
+	monthly, _ := NewBillingInterval(string(BillingIntervalMonthly), 0)
+	yearly, _ := NewBillingInterval(string(BillingIntervalYearly), 0)
+	current := PlanPricing{Amount: 3000, Currency: "BRL"}
+
+	newTarget := func(planID string, amount int64, currency string) PlanChangeTarget {
+		return PlanChangeTarget{
+			PlanID:          PlanID{value: planID},
+			BillingInterval: monthly,
+			Pricing:         PlanPricing{Amount: amount, Currency: currency},
+		}
+	}
+	yearlyTarget := PlanChangeTarget{
+		PlanID:          PlanID{value: "pro-yearly"},
+		BillingInterval: yearly,
+		Pricing:         PlanPricing{Amount: 30000, Currency: "BRL"},
+	}
+
+	tests := []struct {
+		name          string
+		status        SubscriptionStatus
+		target        PlanChangeTarget
+		timing        PlanChangeTiming
+		at            time.Time
+		wantProration int64
+		wantErr       error
+		wantPlanID    string
+		wantPending   string
+	}{
+		{
+			name:          "upgrade imediato no dia 0",
+			status:        SubscriptionStatusActive,
+			target:        newTarget("pro", 6000, "BRL"),
+			timing:        PlanChangeImmediate,
+			at:            periodStart,
+			wantProration: 3000,
+			wantPlanID:    "pro",
+		},
+		{
+			name:          "downgrade imediato no último dia",
+			status:        SubscriptionStatusActive,
+			target:        newTarget("starter", 1500, "BRL"),
+			timing:        PlanChangeImmediate,
+			at:            periodEnd.AddDate(0, 0, -1),
+			wantProration: -50,
+			wantPlanID:    "starter",
+		},
+		{
+			name:          "troca imediata em trial não prorrateia",
+			status:        SubscriptionStatusTrialing,
+			target:        newTarget("pro", 6000, "BRL"),
+			timing:        PlanChangeImmediate,
+			at:            periodStart,
+			wantProration: 0,
+			wantPlanID:    "pro",
+		},
+		{
+			name:        "troca no fim do período fica agendada",
+			status:      SubscriptionStatusActive,
+			target:      newTarget("pro", 6000, "BRL"),
+			timing:      PlanChangeEndOfPeriod,
+			at:          periodStart.AddDate(0, 0, 10),
+			wantPlanID:  "basic",
+			wantPending: "pro",
+		},
+		{
+			name:       "troca imediata para outro intervalo é rejeitada",
+			status:     SubscriptionStatusActive,
+			target:     yearlyTarget,
+			timing:     PlanChangeImmediate,
+			at:         periodStart.AddDate(0, 0, 15),
+			wantErr:    ErrBillingIntervalChange,
+			wantPlanID: "basic",
+		},
+		{
+			name:        "troca para outro intervalo no fim do período fica agendada",
+			status:      SubscriptionStatusActive,
+			target:      yearlyTarget,
+			timing:      PlanChangeEndOfPeriod,
+			at:          periodStart.AddDate(0, 0, 15),
+			wantPlanID:  "basic",
+			wantPending: "pro-yearly",
+		},
+		{
+			name:          "troca imediata para outro intervalo em trial não prorrateia",
+			status:        SubscriptionStatusTrialing,
+			target:        yearlyTarget,
+			timing:        PlanChangeImmediate,
+			at:            periodStart,
+			wantProration: 0,
+			wantPlanID:    "pro-yearly",
+		},
+		{
+			name:       "moedas diferentes são rejeitadas",
+			status:     SubscriptionStatusActive,
+			target:     newTarget("pro", 6000, "USD"),
+			timing:     PlanChangeImmediate,
+			at:         periodStart,
+			wantErr:    ErrCurrencyMismatch,
+			wantPlanID: "basic",
+		},
+		{
+			name:       "mesmo plano é rejeitado",
+			status:     SubscriptionStatusActive,
+			target:     newTarget("basic", 3000, "BRL"),
+			timing:     PlanChangeImmediate,
+			at:         periodStart,
+			wantErr:    ErrSamePlan,
+			wantPlanID: "basic",
+		},
+		{
+			name:       "subscription cancelada não troca de plano",
+			status:     SubscriptionStatusCancelled,
+			target:     newTarget("pro", 6000, "BRL"),
+			timing:     PlanChangeImmediate,
+			at:         periodStart,
+			wantErr:    ErrInvalidStatusTransition,
+			wantPlanID: "basic",
+		},
+		{
+			name:       "momento desconhecido é rejeitado",
+			status:     SubscriptionStatusActive,
+			target:     newTarget("pro", 6000, "BRL"),
+			timing:     PlanChangeTiming("tomorrow"),
+			at:         periodStart,
+			wantErr:    ErrInvalidPlanChangeTiming,
+			wantPlanID: "basic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := SubscriptionSnapshot{
+				ID:              "sub-1",
+				PlanID:          "basic",
+				CustomerID:      "customer-1",
+				Status:          tt.status,
+				BillingInterval: string(BillingIntervalMonthly),
+			}
+			if tt.status != SubscriptionStatusTrialing {
+				snapshot.CurrentPeriodStart = periodStart
+				snapshot.CurrentPeriodEnd = periodEnd
+			}
+			subscription, err := ReconstructSubscription(snapshot)
+			if err != nil {
+				t.Fatalf("ReconstructSubscription() error = %v", err)
+			}
+
+			proration, err := subscription.ChangePlan(tt.target, current, tt.timing, tt.at, "corr-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePlan() error = %v, want %v", err, tt.wantErr)
+			}
+			if proration != tt.wantProration {
+				t.Errorf("ChangePlan() proration = %d, want %d", proration, tt.wantProration)
+			}
+			if got := subscription.PlanID().String(); got != tt.wantPlanID {
+				t.Errorf("PlanID() = %q, want %q", got, tt.wantPlanID)
+			}
+			if got := subscription.PendingPlanID().String(); got != tt.wantPending {
+				t.Errorf("PendingPlanID() = %q, want %q", got, tt.wantPending)
+			}
+			if tt.wantErr != nil && len(subscription.Events()) != 0 {
+				t.Errorf("ChangePlan() emitted %d events on error", len(subscription.Events()))
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+
+	"github.com/google/uuid"
+)
+
+// RenewalScheduler avança periodicamente as subscriptions cujo período ou trial terminou
+type RenewalScheduler struct {
+	repository    SubscriptionRepository
+	interval      time.Duration
+	batchSize     int
+	leaseDuration time.Duration
+	trialNotice   time.Duration
+	logger        *logging.StructuredLogger
+}
+
+// NewRenewalScheduler cria uma nova instância do scheduler de renovação
+func NewRenewalScheduler(repository SubscriptionRepository, interval time.Duration, batchSize int, leaseDuration, trialNotice time.Duration) *RenewalScheduler {
+	return &RenewalScheduler{
+		repository:    repository,
+		interval:      interval,
+		batchSize:     batchSize,
+		leaseDuration: leaseDuration,
+		trialNotice:   trialNotice,
+		logger:        logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// Run executa o scheduler até o contexto ser cancelado
+func (s *RenewalScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ProcessDue(ctx); err != nil {
+			s.logger.Error(ctx, "RenewalScheduler", "Erro ao processar renovações", err, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue reserva um lote de subscriptions vencidas e retorna quantas foram processadas
+func (s *RenewalScheduler) ProcessDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	// Cada passada reserva com um token próprio, então só as linhas reservadas agora são lidas de volta
+	claim := uuid.New().String()
+
+	subscriptions, err := s.repository.ClaimDueSubscriptions(ctx, DueSubscriptionsQuery{
+		Owner:         claim,
+		Now:           now,
+		LeaseDuration: s.leaseDuration,
+		TrialNotice:   s.trialNotice,
+		Limit:         s.batchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("erro ao reservar subscriptions vencidas: %w", err)
+	}
+
+	processed := 0
+	for _, subscription := range subscriptions {
+		subCtx := logging.WithCorrelationID(ctx, logging.GenerateCorrelationID("subscription"))
+
+		if err := s.process(subCtx, subscription, now); err != nil {
+			// O lease é mantido até expirar e funciona como backoff: nenhuma passada seguinte seleciona o token desta
+			s.logger.Error(subCtx, "RenewalScheduler", "Erro ao processar subscription vencida", err, map[string]interface{}{
+				"subscription_id": subscription.ID().String(),
+				"status":          string(subscription.Status()),
+			})
+			continue
+		}
+
+		if err := s.repository.ReleaseLease(subCtx, subscription.ID(), claim); err != nil {
+			s.logger.Error(subCtx, "RenewalScheduler", "Erro ao liberar lease da subscription", err, map[string]interface{}{
+				"subscription_id": subscription.ID().String(),
+			})
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// process aplica a transição de fim de período adequada ao status da subscription
+func (s *RenewalScheduler) process(ctx context.Context, subscription *Subscription, now time.Time) error {
+	correlationID := logging.GetCorrelationID(ctx)
+
+	var err error
+	switch subscription.Status() {
+	case SubscriptionStatusActive:
+		if subscription.CancelAtPeriodEnd() {
+			err = subscription.CompleteScheduledCancellation(now, correlationID)
+		} else {
+			err = subscription.Renew(now, correlationID)
+		}
+	case SubscriptionStatusSuspended:
+		err = subscription.Expire(now, correlationID)
+	case SubscriptionStatusTrialing:
+		if subscription.IsTrialEnded(now) {
+			err = subscription.EndTrial(now, correlationID)
+		} else {
+			err = subscription.NotifyTrialWillEnd(now, s.trialNotice, correlationID)
+		}
+	default:
+		err = ErrInvalidStatusTransition
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Update(ctx, subscription)
+}
@@ -37,11 +37,11 @@ func (d *SubscriptionRepositoryTracingDecorator) GetByID(ctx context.Context, id
 }
 
 // GetByCustomerID adiciona tracing à operação de busca por customer ID
-func (d *SubscriptionRepositoryTracingDecorator) GetByCustomerID(ctx context.Context, customerID CustomerID) ([]*Subscription, error) {
+func (d *SubscriptionRepositoryTracingDecorator) GetByCustomerID(ctx context.Context, customerID CustomerID, query ListSubscriptionsQuery) (*SubscriptionPage, error) {
 	ctx, span := d.tracer.Start(ctx, "Repository.GetByCustomerID")
 	defer span.End()
 
-	return d.repository.GetByCustomerID(ctx, customerID)
+	return d.repository.GetByCustomerID(ctx, customerID, query)
 }
 
 // Update adiciona tracing à operação de atualização
@@ -52,10 +52,26 @@ func (d *SubscriptionRepositoryTracingDecorator) Update(ctx context.Context, sub
 	return d.repository.Update(ctx, subscription)
 }
 
-// GetAll adiciona tracing à operação de busca de todas as subscriptions
-func (d *SubscriptionRepositoryTracingDecorator) GetAll(ctx context.Context) ([]*Subscription, error) {
-	ctx, span := d.tracer.Start(ctx, "Repository.GetAll")
+// List adiciona tracing à listagem paginada de subscriptions
+func (d *SubscriptionRepositoryTracingDecorator) List(ctx context.Context, query ListSubscriptionsQuery) (*SubscriptionPage, error) {
+	ctx, span := d.tracer.Start(ctx, "Repository.List")
 	defer span.End()
 
-	return d.repository.GetAll(ctx)
+	return d.repository.List(ctx, query)
+}
+
+// ClaimDueSubscriptions adiciona tracing à reserva de subscriptions vencidas
+func (d *SubscriptionRepositoryTracingDecorator) ClaimDueSubscriptions(ctx context.Context, query DueSubscriptionsQuery) ([]*Subscription, error) {
+	ctx, span := d.tracer.Start(ctx, "Repository.ClaimDueSubscriptions")
+	defer span.End()
+
+	return d.repository.ClaimDueSubscriptions(ctx, query)
+}
+
+// ReleaseLease adiciona tracing à liberação do lease
+func (d *SubscriptionRepositoryTracingDecorator) ReleaseLease(ctx context.Context, id SubscriptionID, owner string) error {
+	ctx, span := d.tracer.Start(ctx, "Repository.ReleaseLease")
+	defer span.End()
+
+	return d.repository.ReleaseLease(ctx, id, owner)
 }
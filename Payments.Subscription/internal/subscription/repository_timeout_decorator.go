@@ -0,0 +1,230 @@
+package subscription
+
+import (
+	"context"
+	"time"
+)
+
+// SubscriptionRepositoryTimeoutDecorator é um decorator que limita o tempo das operações no banco,
+// com orçamentos separados para leitura e escrita
+type SubscriptionRepositoryTimeoutDecorator struct {
+	repository   SubscriptionRepository
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// NewSubscriptionRepositoryTimeoutDecorator cria uma nova instância do decorator de timeout
+func NewSubscriptionRepositoryTimeoutDecorator(repository SubscriptionRepository, readTimeout, writeTimeout time.Duration) SubscriptionRepository {
+	return &SubscriptionRepositoryTimeoutDecorator{
+		repository:   repository,
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Create aplica o timeout de escrita à criação
+func (d *SubscriptionRepositoryTimeoutDecorator) Create(ctx context.Context, subscription *Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Create(ctx, subscription)
+}
+
+// GetByID aplica o timeout de leitura à busca por ID
+func (d *SubscriptionRepositoryTimeoutDecorator) GetByID(ctx context.Context, id SubscriptionID) (*Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
+	defer cancel()
+
+	return d.repository.GetByID(ctx, id)
+}
+
+// GetByCustomerID aplica o timeout de leitura à listagem por customer
+func (d *SubscriptionRepositoryTimeoutDecorator) GetByCustomerID(ctx context.Context, customerID CustomerID, query ListSubscriptionsQuery) (*SubscriptionPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
+	defer cancel()
+
+	return d.repository.GetByCustomerID(ctx, customerID, query)
+}
+
+// Update aplica o timeout de escrita à atualização
+func (d *SubscriptionRepositoryTimeoutDecorator) Update(ctx context.Context, subscription *Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Update(ctx, subscription)
+}
+
+// List aplica o timeout de leitura à listagem paginada
+func (d *SubscriptionRepositoryTimeoutDecorator) List(ctx context.Context, query ListSubscriptionsQuery) (*SubscriptionPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
+	defer cancel()
+
+	return d.repository.List(ctx, query)
+}
+
+// ClaimDueSubscriptions aplica o timeout de escrita, já que a reserva atualiza o lease
+func (d *SubscriptionRepositoryTimeoutDecorator) ClaimDueSubscriptions(ctx context.Context, query DueSubscriptionsQuery) ([]*Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.ClaimDueSubscriptions(ctx, query)
+}
+
+// ReleaseLease aplica o timeout de escrita à liberação do lease
+func (d *SubscriptionRepositoryTimeoutDecorator) ReleaseLease(ctx context.Context, id SubscriptionID, owner string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.ReleaseLease(ctx, id, owner)
+}
+
+// OutboxRepositoryTimeoutDecorator limita o tempo das operações do outbox no banco
+type OutboxRepositoryTimeoutDecorator struct {
+	repository   OutboxRepository
+	writeTimeout time.Duration
+}
+
+// NewOutboxRepositoryTimeoutDecorator cria uma nova instância do decorator de timeout do outbox.
+// Todas as operações do relay alteram linhas, então usam o timeout de escrita
+func NewOutboxRepositoryTimeoutDecorator(repository OutboxRepository, writeTimeout time.Duration) OutboxRepository {
+	return &OutboxRepositoryTimeoutDecorator{
+		repository:   repository,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// ClaimPending aplica o timeout de escrita, já que a reserva atualiza o lease
+func (d *OutboxRepositoryTimeoutDecorator) ClaimPending(ctx context.Context, owner string, limit, maxAttempts int, lease time.Duration) ([]*OutboxMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.ClaimPending(ctx, owner, limit, maxAttempts, lease)
+}
+
+// Release aplica o timeout de escrita à liberação do lease
+func (d *OutboxRepositoryTimeoutDecorator) Release(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Release(ctx, id)
+}
+
+// MarkPublished aplica o timeout de escrita à confirmação da publicação
+func (d *OutboxRepositoryTimeoutDecorator) MarkPublished(ctx context.Context, id string, publishedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.MarkPublished(ctx, id, publishedAt)
+}
+
+// MarkFailed aplica o timeout de escrita ao registro da falha
+func (d *OutboxRepositoryTimeoutDecorator) MarkFailed(ctx context.Context, id string, publishErr error) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.MarkFailed(ctx, id, publishErr)
+}
+
+// SagaRepositoryTimeoutDecorator limita o tempo das operações da saga no banco
+type SagaRepositoryTimeoutDecorator struct {
+	repository   SagaRepository
+	writeTimeout time.Duration
+}
+
+// NewSagaRepositoryTimeoutDecorator cria uma nova instância do decorator de timeout da saga.
+// Todas as operações alteram linhas, então usam o timeout de escrita
+func NewSagaRepositoryTimeoutDecorator(repository SagaRepository, writeTimeout time.Duration) SagaRepository {
+	return &SagaRepositoryTimeoutDecorator{
+		repository:   repository,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Create aplica o timeout de escrita à criação
+func (d *SagaRepositoryTimeoutDecorator) Create(ctx context.Context, saga *SagaState) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Create(ctx, saga)
+}
+
+// Update aplica o timeout de escrita à atualização
+func (d *SagaRepositoryTimeoutDecorator) Update(ctx context.Context, saga *SagaState) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Update(ctx, saga)
+}
+
+// ClaimUnfinished aplica o timeout de escrita, já que a reserva atualiza o lease
+func (d *SagaRepositoryTimeoutDecorator) ClaimUnfinished(ctx context.Context, owner string, staleBefore time.Time, limit int, lease time.Duration) ([]*SagaState, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.ClaimUnfinished(ctx, owner, staleBefore, limit, lease)
+}
+
+// DeadLetterRepositoryTimeoutDecorator limita o tempo das operações da dead-letter queue no banco,
+// com orçamentos separados para leitura e escrita
+type DeadLetterRepositoryTimeoutDecorator struct {
+	repository   DeadLetterRepository
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// NewDeadLetterRepositoryTimeoutDecorator cria uma nova instância do decorator de timeout da dead-letter queue
+func NewDeadLetterRepositoryTimeoutDecorator(repository DeadLetterRepository, readTimeout, writeTimeout time.Duration) DeadLetterRepository {
+	return &DeadLetterRepositoryTimeoutDecorator{
+		repository:   repository,
+		readTimeout:  readTimeout,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Create aplica o timeout de escrita à criação
+func (d *DeadLetterRepositoryTimeoutDecorator) Create(ctx context.Context, event *DeadLetterEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Create(ctx, event)
+}
+
+// GetByID aplica o timeout de leitura à busca por ID
+func (d *DeadLetterRepositoryTimeoutDecorator) GetByID(ctx context.Context, id string) (*DeadLetterEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
+	defer cancel()
+
+	return d.repository.GetByID(ctx, id)
+}
+
+// List aplica o timeout de leitura à listagem
+func (d *DeadLetterRepositoryTimeoutDecorator) List(ctx context.Context, filter DeadLetterFilter) ([]*DeadLetterEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.readTimeout)
+	defer cancel()
+
+	return d.repository.List(ctx, filter)
+}
+
+// ClaimReplay aplica o timeout de escrita à reserva do reprocessamento
+func (d *DeadLetterRepositoryTimeoutDecorator) ClaimReplay(ctx context.Context, id string, staleBefore time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.ClaimReplay(ctx, id, staleBefore)
+}
+
+// Resolve aplica o timeout de escrita à mudança de status
+func (d *DeadLetterRepositoryTimeoutDecorator) Resolve(ctx context.Context, id string, from, to DeadLetterStatus, resolvedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.Resolve(ctx, id, from, to, resolvedAt)
+}
+
+// RecordReplayFailure aplica o timeout de escrita ao registro da falha do reprocessamento
+func (d *DeadLetterRepositoryTimeoutDecorator) RecordReplayFailure(ctx context.Context, id string, replayErr error) error {
+	ctx, cancel := context.WithTimeout(ctx, d.writeTimeout)
+	defer cancel()
+
+	return d.repository.RecordReplayFailure(ctx, id, replayErr)
+}
@@ -0,0 +1,305 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"payments-subscription/internal/common/logging"
+	"payments-subscription/internal/customer"
+
+	"github.com/google/uuid"
+)
+
+// CreateSubscriptionSagaType identifica a saga de criação de subscription
+const CreateSubscriptionSagaType = "create_subscription"
+
+// SagaStatus representa o estado de execução de uma saga
+type SagaStatus string
+
+const (
+	SagaStatusRunning      SagaStatus = "running"
+	SagaStatusCompleted    SagaStatus = "completed"
+	SagaStatusCompensating SagaStatus = "compensating"
+	SagaStatusCompensated  SagaStatus = "compensated"
+)
+
+// ErrSagaConflict indica que a saga já foi finalizada ou está reservada pelo lease de outro processo
+var ErrSagaConflict = errors.New("saga finalizada ou reservada por outro processo")
+
+// SagaStep representa um passo concluído da saga
+type SagaStep string
+
+const (
+	SagaStepCustomerCreated       SagaStep = "customer_created"
+	SagaStepCustomerReused        SagaStep = "customer_reused"
+	SagaStepSubscriptionPersisted SagaStep = "subscription_persisted"
+	SagaStepEventsEnqueued        SagaStep = "events_enqueued"
+	SagaStepCustomerDeleted       SagaStep = "customer_deleted"
+)
+
+// SagaState é o estado persistido de uma saga, usado para compensar ou concluir a saga após uma falha
+type SagaState struct {
+	ID              string
+	Type            string
+	Status          SagaStatus
+	SubscriptionID  string
+	CustomerID      string
+	CustomerCreated bool
+	Steps           []SagaStep
+	LastError       string
+	Attempts        int
+	CorrelationID   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	// LeaseOwner é o dono do lease quando a saga foi reservada pelo SagaRecovery; vazio na requisição
+	LeaseOwner string
+}
+
+// NewCreateSubscriptionSaga inicia a saga de criação de subscription
+func NewCreateSubscriptionSaga(correlationID string) *SagaState {
+	now := time.Now()
+	return &SagaState{
+		ID:            uuid.New().String(),
+		Type:          CreateSubscriptionSagaType,
+		Status:        SagaStatusRunning,
+		CorrelationID: correlationID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// RecordCustomer registra o customer usado pela saga; só customers criados por ela são compensados
+func (s *SagaState) RecordCustomer(customerID string, created bool) {
+	s.CustomerID = customerID
+	s.CustomerCreated = created
+	if created {
+		s.recordStep(SagaStepCustomerCreated)
+	} else {
+		s.recordStep(SagaStepCustomerReused)
+	}
+}
+
+// Complete registra a persistência da subscription e a gravação dos eventos no outbox, encerrando a saga
+func (s *SagaState) Complete() {
+	s.recordStep(SagaStepSubscriptionPersisted)
+	s.recordStep(SagaStepEventsEnqueued)
+	s.Status = SagaStatusCompleted
+}
+
+// StartCompensation marca a saga para compensação, registrando a causa
+func (s *SagaState) StartCompensation(cause error) {
+	s.Status = SagaStatusCompensating
+	if cause != nil {
+		s.LastError = cause.Error()
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// HasStep indica se o passo já foi concluído
+func (s *SagaState) HasStep(step SagaStep) bool {
+	for _, recorded := range s.Steps {
+		if recorded == step {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished indica se a saga não exige mais nenhuma ação
+func (s *SagaState) IsFinished() bool {
+	return s.Status == SagaStatusCompleted || s.Status == SagaStatusCompensated
+}
+
+func (s *SagaState) recordStep(step SagaStep) {
+	if !s.HasStep(step) {
+		s.Steps = append(s.Steps, step)
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// SagaRepository define o contrato de persistência do estado das sagas
+type SagaRepository interface {
+	// Create grava o estado inicial da saga
+	Create(ctx context.Context, saga *SagaState) error
+
+	// Update grava o estado atual da saga e libera o lease, se houver. Retorna ErrSagaConflict se a saga
+	// já foi concluída ou compensada, ou se outro processo detém um lease válido sobre ela
+	Update(ctx context.Context, saga *SagaState) error
+
+	// ClaimUnfinished reserva, via lease, sagas em compensação ou paradas em execução desde antes de staleBefore
+	ClaimUnfinished(ctx context.Context, owner string, staleBefore time.Time, limit int, lease time.Duration) ([]*SagaState, error)
+}
+
+// SagaCoordinator executa os passos de compensação e retomada da saga de criação de subscription
+type SagaCoordinator struct {
+	sagas          SagaRepository
+	subscriptions  SubscriptionRepository
+	customerClient customer.Client
+	logger         *logging.StructuredLogger
+}
+
+// NewSagaCoordinator cria uma nova instância do SagaCoordinator
+func NewSagaCoordinator(sagas SagaRepository, subscriptions SubscriptionRepository, customerClient customer.Client) *SagaCoordinator {
+	return &SagaCoordinator{
+		sagas:          sagas,
+		subscriptions:  subscriptions,
+		customerClient: customerClient,
+		logger:         logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// Begin grava o início da saga antes de qualquer efeito remoto
+func (c *SagaCoordinator) Begin(ctx context.Context) (*SagaState, error) {
+	saga := NewCreateSubscriptionSaga(logging.GetCorrelationID(ctx))
+	if err := c.sagas.Create(ctx, saga); err != nil {
+		return nil, fmt.Errorf("erro ao iniciar saga: %w", err)
+	}
+	return saga, nil
+}
+
+// Save grava o progresso da saga
+func (c *SagaCoordinator) Save(ctx context.Context, saga *SagaState) error {
+	if err := c.sagas.Update(ctx, saga); err != nil {
+		return fmt.Errorf("erro ao gravar saga %s: %w", saga.ID, err)
+	}
+	return nil
+}
+
+// Compensate desfaz os efeitos remotos da saga. Se a compensação falhar, a saga permanece em
+// compensação e é retomada pelo SagaRecovery
+func (c *SagaCoordinator) Compensate(ctx context.Context, saga *SagaState, cause error) {
+	// A compensação precisa terminar mesmo que a requisição original tenha sido cancelada
+	ctx = context.WithoutCancel(ctx)
+
+	saga.StartCompensation(cause)
+
+	if saga.CustomerCreated && !saga.HasStep(SagaStepCustomerDeleted) {
+		if err := c.customerClient.DeleteCustomer(ctx, saga.CustomerID); err != nil {
+			saga.Attempts++
+			saga.LastError = err.Error()
+			c.logger.Error(ctx, "SagaCompensation", "Erro ao remover customer criado pela saga", err, map[string]interface{}{
+				"saga_id":     saga.ID,
+				"customer_id": saga.CustomerID,
+				"attempts":    saga.Attempts,
+			})
+			c.saveQuietly(ctx, saga)
+			return
+		}
+		saga.recordStep(SagaStepCustomerDeleted)
+	}
+
+	saga.Status = SagaStatusCompensated
+	c.logger.Info(ctx, "SagaCompensation", "Saga compensada", map[string]interface{}{
+		"saga_id":         saga.ID,
+		"customer_id":     saga.CustomerID,
+		"subscription_id": saga.SubscriptionID,
+	})
+	c.saveQuietly(ctx, saga)
+}
+
+// Resume retoma uma saga interrompida: conclui a saga se a subscription foi persistida e a compensa caso contrário
+func (c *SagaCoordinator) Resume(ctx context.Context, saga *SagaState) error {
+	if saga.Status == SagaStatusCompensating {
+		c.Compensate(ctx, saga, nil)
+		return nil
+	}
+
+	if saga.SubscriptionID != "" {
+		subscriptionID, err := NewSubscriptionIDFromString(saga.SubscriptionID)
+		if err != nil {
+			return fmt.Errorf("saga %s com subscription ID inválido: %w", saga.ID, err)
+		}
+
+		_, err = c.subscriptions.GetByID(ctx, subscriptionID)
+		if err == nil {
+			saga.Complete()
+			return c.Save(ctx, saga)
+		}
+		if !errors.Is(err, ErrSubscriptionNotFound) {
+			return fmt.Errorf("erro ao verificar subscription da saga %s: %w", saga.ID, err)
+		}
+	}
+
+	c.Compensate(ctx, saga, errors.New("saga interrompida antes da persistência da subscription"))
+	return nil
+}
+
+// saveQuietly grava a saga registrando em log eventuais falhas; o lease expira e a saga é retomada depois
+func (c *SagaCoordinator) saveQuietly(ctx context.Context, saga *SagaState) {
+	if err := c.Save(ctx, saga); err != nil {
+		c.logger.Error(ctx, "SagaCompensation", "Erro ao gravar estado da saga", err, map[string]interface{}{
+			"saga_id": saga.ID,
+		})
+	}
+}
+
+// SagaRecovery retoma periodicamente as sagas interrompidas, inclusive as deixadas por uma queda do processo
+type SagaRecovery struct {
+	coordinator *SagaCoordinator
+	sagas       SagaRepository
+	owner       string
+	interval    time.Duration
+	staleAfter  time.Duration
+	batchSize   int
+	lease       time.Duration
+	logger      *logging.StructuredLogger
+}
+
+// NewSagaRecovery cria uma nova instância do SagaRecovery. staleAfter deve superar o timeout da requisição,
+// para que sagas ainda em andamento não sejam retomadas
+func NewSagaRecovery(coordinator *SagaCoordinator, sagas SagaRepository, interval, staleAfter time.Duration, batchSize int) *SagaRecovery {
+	return &SagaRecovery{
+		coordinator: coordinator,
+		sagas:       sagas,
+		owner:       uuid.New().String(),
+		interval:    interval,
+		staleAfter:  staleAfter,
+		batchSize:   batchSize,
+		lease:       interval * 10,
+		logger:      logging.NewStructuredLogger("subscription-service"),
+	}
+}
+
+// Run processa as sagas pendentes na inicialização e depois a cada intervalo, até o contexto ser cancelado
+func (r *SagaRecovery) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessUnfinished(ctx); err != nil {
+			r.logger.Error(ctx, "SagaRecovery", "Erro ao retomar sagas", err, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessUnfinished retoma um lote de sagas interrompidas e retorna quantas foram processadas
+func (r *SagaRecovery) ProcessUnfinished(ctx context.Context) (int, error) {
+	sagas, err := r.sagas.ClaimUnfinished(ctx, r.owner, time.Now().Add(-r.staleAfter), r.batchSize, r.lease)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar sagas pendentes: %w", err)
+	}
+
+	processed := 0
+	for _, saga := range sagas {
+		sagaCtx := logging.WithCorrelationID(ctx, saga.CorrelationID)
+
+		if err := r.coordinator.Resume(sagaCtx, saga); err != nil {
+			r.logger.Error(sagaCtx, "SagaRecovery", "Erro ao retomar saga", err, map[string]interface{}{
+				"saga_id": saga.ID,
+				"status":  string(saga.Status),
+			})
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
@@ -2,39 +2,54 @@ package subscription
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"payments-subscription/internal/common/logging"
 	"payments-subscription/internal/customer"
+	"payments-subscription/internal/plan"
 )
 
 // SubscriptionService representa o serviço de aplicação para Subscription
 type SubscriptionService struct {
 	repository     SubscriptionRepository
-	eventService   *SubscriptionEventService
-	customerClient *customer.CustomerClient
+	customerClient customer.Client
+	plans          PlanCatalog
+	sagas          *SagaCoordinator
+	onboardingMode OnboardingMode
 	logger         *logging.StructuredLogger
 }
 
+// PlanCatalog define o contrato de consulta ao catálogo de planos usado na criação de subscriptions
+type PlanCatalog interface {
+	GetByID(ctx context.Context, id string) (*plan.Plan, error)
+}
+
 // NewSubscriptionService cria uma nova instância do SubscriptionService
 func NewSubscriptionService(
 	repository SubscriptionRepository,
-	eventService *SubscriptionEventService,
-	customerClient *customer.CustomerClient,
+	customerClient customer.Client,
+	plans PlanCatalog,
+	sagas *SagaCoordinator,
+	onboardingMode OnboardingMode,
 ) *SubscriptionService {
 	return &SubscriptionService{
 		repository:     repository,
-		eventService:   eventService,
 		customerClient: customerClient,
+		plans:          plans,
+		sagas:          sagas,
+		onboardingMode: onboardingMode,
 		logger:         logging.NewStructuredLogger("subscription-service"),
 	}
 }
 
 // CreateSubscriptionRequest representa a requisição para criar uma subscription
 type CreateSubscriptionRequest struct {
-	PlanID   string                `json:"plan_id"`
-	Customer CreateCustomerRequest `json:"customer"`
+	PlanID          string                `json:"plan_id"`
+	TrialDays       int                   `json:"trial_days,omitempty"`
+	PaymentMethodID string                `json:"payment_method_id,omitempty"`
+	Customer        CreateCustomerRequest `json:"customer"`
 }
 
 // CreateCustomerRequest representa a requisição para criar um customer
@@ -44,22 +59,74 @@ type CreateCustomerRequest struct {
 	Email      string `json:"email"`
 }
 
+// ChangeStatusRequest representa a requisição para cancelar, suspender ou reativar uma subscription
+type ChangeStatusRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AttachPaymentMethodRequest representa a requisição para associar um meio de pagamento à subscription
+type AttachPaymentMethodRequest struct {
+	PaymentMethodID string `json:"payment_method_id"`
+}
+
+// ChangePlanRequest representa a requisição de troca de plano
+type ChangePlanRequest struct {
+	PlanID string `json:"plan_id"`
+	Timing string `json:"timing"`
+}
+
+// PlanChangeResponse representa o resultado de uma troca de plano
+type PlanChangeResponse struct {
+	Subscription    *SubscriptionResponse `json:"subscription"`
+	PreviousPlanID  string                `json:"previous_plan_id"`
+	NewPlanID       string                `json:"new_plan_id"`
+	Timing          string                `json:"timing"`
+	ProrationAmount int64                 `json:"proration_amount"`
+	Currency        string                `json:"currency"`
+	EffectiveAt     string                `json:"effective_at"`
+}
+
 // SubscriptionResponse representa a resposta com dados da subscription
 type SubscriptionResponse struct {
-	ID         string `json:"id"`
-	PlanID     string `json:"plan_id"`
-	CustomerID string `json:"customer_id"`
-	Status     string `json:"status"`
-	CreatedAt  string `json:"created_at"`
-	UpdatedAt  string `json:"updated_at"`
+	ID                  string `json:"id"`
+	PlanID              string `json:"plan_id"`
+	CustomerID          string `json:"customer_id"`
+	Status              string `json:"status"`
+	BillingInterval     string `json:"billing_interval"`
+	BillingIntervalDays int    `json:"billing_interval_days,omitempty"`
+	CurrentPeriodStart  string `json:"current_period_start,omitempty"`
+	CurrentPeriodEnd    string `json:"current_period_end,omitempty"`
+	NextRenewalAt       string `json:"next_renewal_at,omitempty"`
+	TrialEndsAt         string `json:"trial_ends_at,omitempty"`
+	HasPaymentMethod    bool   `json:"has_payment_method"`
+	AwaitingCustomer    bool   `json:"awaiting_customer,omitempty"`
+	PendingPlanID       string `json:"pending_plan_id,omitempty"`
+	CancelAtPeriodEnd   bool   `json:"cancel_at_period_end"`
+	Version             int    `json:"version"`
+	CreatedAt           string `json:"created_at"`
+	UpdatedAt           string `json:"updated_at"`
+}
+
+// SubscriptionListResponse representa uma página da listagem de subscriptions
+type SubscriptionListResponse struct {
+	Subscriptions []*SubscriptionResponse `json:"subscriptions"`
+	NextCursor    string                  `json:"next_cursor,omitempty"`
 }
 
 // SubscriptionServiceInterface é uma interface para o SubscriptionService
 type SubscriptionServiceInterface interface {
 	CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*SubscriptionResponse, error)
 	GetSubscriptionByID(ctx context.Context, id string) (*SubscriptionResponse, error)
-	GetAllSubscriptions(ctx context.Context) ([]*SubscriptionResponse, error)
+	ListSubscriptions(ctx context.Context, query ListSubscriptionsQuery) (*SubscriptionListResponse, error)
+	ListCustomerSubscriptions(ctx context.Context, customerID string, query ListSubscriptionsQuery) (*SubscriptionListResponse, error)
 	ActivateSubscription(ctx context.Context, id, correlationID string) error
+	CancelSubscription(ctx context.Context, id, reason, correlationID string) error
+	SuspendSubscription(ctx context.Context, id, reason, correlationID string) error
+	ResumeSubscription(ctx context.Context, id, reason, correlationID string) error
+	AttachPaymentMethod(ctx context.Context, id, paymentMethodID, correlationID string) error
+	ChangePlan(ctx context.Context, id string, req ChangePlanRequest, correlationID string) (*PlanChangeResponse, error)
+	ScheduleCancellation(ctx context.Context, id, reason, correlationID string) error
+	UndoScheduledCancellation(ctx context.Context, id, reason, correlationID string) error
 }
 
 // CreateSubscription cria uma nova subscription
@@ -77,39 +144,91 @@ func (s *SubscriptionService) CreateSubscription(ctx context.Context, req Create
 		"customer_name":  req.Customer.Name,
 	})
 
-	// Criar o customer primeiro
-	customerReq := customer.CustomerRequest{
-		Name:  req.Customer.Name,
-		Email: req.Customer.Email,
+	if req.PlanID == "" {
+		s.logger.Error(ctx, operation, "Plan ID não informado", ErrInvalidPlanID, nil)
+		return nil, fmt.Errorf("erro ao criar subscription: %w", ErrInvalidPlanID)
 	}
 
-	s.logger.Info(ctx, operation, "Iniciando criação de customer", map[string]interface{}{
-		"customer_email": req.Customer.Email,
-		"customer_name":  req.Customer.Name,
-	})
+	if req.TrialDays < 0 {
+		s.logger.Error(ctx, operation, "Dias de trial inválidos", ErrInvalidTrialDays, map[string]interface{}{
+			"trial_days": req.TrialDays,
+		})
+		return nil, fmt.Errorf("erro ao criar subscription: %w", ErrInvalidTrialDays)
+	}
 
-	customerResp, err := s.customerClient.CreateCustomer(ctx, customerReq)
+	// Valida o plano no catálogo antes de criar o customer
+	selectedPlan, err := s.plans.GetByID(ctx, req.PlanID)
 	if err != nil {
-		s.logger.Error(ctx, operation, "Erro ao criar customer", err, map[string]interface{}{
-			"customer_email": req.Customer.Email,
-			"plan_id":        req.PlanID,
+		s.logger.Error(ctx, operation, "Erro ao buscar plano no catálogo", err, map[string]interface{}{
+			"plan_id": req.PlanID,
 		})
-		return nil, fmt.Errorf("erro ao criar customer: %w", err)
+		return nil, fmt.Errorf("erro ao buscar plano: %w", err)
 	}
 
-	s.logger.Info(ctx, operation, "Customer criado com sucesso", map[string]interface{}{
-		"customer_id":    customerResp.ID,
-		"customer_email": customerResp.Email,
-	})
+	if selectedPlan.IsArchived() {
+		s.logger.Error(ctx, operation, "Plano arquivado não aceita novas subscriptions", plan.ErrPlanArchived, map[string]interface{}{
+			"plan_id": req.PlanID,
+		})
+		return nil, fmt.Errorf("erro ao criar subscription: %w", plan.ErrPlanArchived)
+	}
 
-	// Criar a subscription usando o ID do customer retornado
-	correlationID := logging.GetCorrelationID(ctx)
-	subscription, err := NewSubscription(req.PlanID, customerResp.ID, correlationID)
+	billingInterval, err := NewBillingInterval(string(selectedPlan.Interval()), selectedPlan.IntervalDays())
 	if err != nil {
-		s.logger.Error(ctx, operation, "Erro ao criar entidade subscription", err, map[string]interface{}{
-			"plan_id":     req.PlanID,
+		s.logger.Error(ctx, operation, "Intervalo de cobrança do plano inválido", err, map[string]interface{}{
+			"plan_id": req.PlanID,
+		})
+		return nil, fmt.Errorf("erro ao criar subscription: %w", err)
+	}
+
+	// Sem trial explícito na requisição, vale o trial padrão do plano
+	trialDays := req.TrialDays
+	if trialDays == 0 {
+		trialDays = selectedPlan.TrialDays()
+	}
+
+	// No modo assíncrono o customer é resolvido pelo serviço de Customer a partir do SubscriptionRequested
+	if s.onboardingMode == OnboardingModeAsync {
+		return s.requestSubscription(ctx, operation, startTime, req, billingInterval, trialDays)
+	}
+
+	// A saga registra cada passo para compensar o customer criado caso a subscription não seja persistida
+	saga, err := s.sagas.Begin(ctx)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao iniciar saga de criação", err, nil)
+		return nil, fmt.Errorf("erro ao criar subscription: %w", err)
+	}
+
+	// Reutiliza o customer informado ou já cadastrado com o email antes de criar um novo
+	customerResp, customerCreated, err := s.resolveCustomer(ctx, operation, req)
+	if err != nil {
+		s.sagas.Compensate(ctx, saga, err)
+		return nil, err
+	}
+	saga.RecordCustomer(customerResp.ID, customerCreated)
+
+	// O customer precisa constar na saga gravada antes de qualquer outro passo: se o processo cair
+	// daqui em diante, o SagaRecovery encontra o customer e o compensa
+	if err := s.sagas.Save(ctx, saga); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao gravar customer da saga", err, map[string]interface{}{
+			"saga_id":     saga.ID,
 			"customer_id": customerResp.ID,
 		})
+		s.sagas.Compensate(ctx, saga, err)
+		return nil, fmt.Errorf("erro ao criar subscription: %w", err)
+	}
+
+	subscription, err := s.newSubscription(ctx, operation, req, customerResp.ID, billingInterval, trialDays)
+	if err != nil {
+		s.sagas.Compensate(ctx, saga, err)
+		return nil, err
+	}
+
+	saga.SubscriptionID = subscription.ID().String()
+	if err := s.sagas.Save(ctx, saga); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao gravar progresso da saga", err, map[string]interface{}{
+			"saga_id": saga.ID,
+		})
+		s.sagas.Compensate(ctx, saga, err)
 		return nil, fmt.Errorf("erro ao criar subscription: %w", err)
 	}
 
@@ -125,16 +244,29 @@ func (s *SubscriptionService) CreateSubscription(ctx context.Context, req Create
 			"subscription_id": subscription.ID().String(),
 			"customer_id":     customerResp.ID,
 			"plan_id":         req.PlanID,
+			"saga_id":         saga.ID,
 		})
+
+		// A falha pode ter ocorrido após o commit (ex: timeout); Resume confere se a subscription
+		// foi gravada antes de compensar, e o SagaRecovery retoma a saga se a conferência falhar
+		if resumeErr := s.sagas.Resume(context.WithoutCancel(ctx), saga); resumeErr != nil {
+			s.logger.Error(ctx, operation, "Erro ao compensar saga de criação", resumeErr, map[string]interface{}{
+				"saga_id": saga.ID,
+			})
+		}
 		return nil, fmt.Errorf("erro ao salvar subscription: %w", err)
 	}
 
-	/*if err := s.eventService.PublishSubscriptionEvents(ctx, subscription); err != nil {
-		s.logger.Warn(ctx, operation, "Erro ao publicar eventos", map[string]interface{}{
-			"subscription_id": subscription.ID().String(),
-			"error":          err.Error(),
+	saga.Complete()
+	if err := s.sagas.Save(ctx, saga); err != nil {
+		// A subscription já foi gravada; o SagaRecovery conclui a saga ao encontrá-la
+		s.logger.Error(ctx, operation, "Erro ao concluir saga de criação", err, map[string]interface{}{
+			"saga_id": saga.ID,
 		})
-	}*/
+	}
+
+	// Os eventos de domínio foram gravados no outbox junto com a subscription
+	// e serão publicados de forma assíncrona pelo OutboxRelay
 
 	response := s.toSubscriptionResponse(subscription)
 
@@ -149,6 +281,161 @@ func (s *SubscriptionService) CreateSubscription(ctx context.Context, req Create
 	return response, nil
 }
 
+// requestSubscription grava a subscription pendente sem chamar o serviço de Customer. O customer é
+// vinculado depois pelo CustomerOnboardingHandler ao receber CustomerCreated ou CustomerVerified
+func (s *SubscriptionService) requestSubscription(
+	ctx context.Context,
+	operation string,
+	startTime time.Time,
+	req CreateSubscriptionRequest,
+	billingInterval BillingInterval,
+	trialDays int,
+) (*SubscriptionResponse, error) {
+	subscription, err := RequestSubscription(
+		req.PlanID,
+		billingInterval,
+		req.Customer.CustomerID,
+		req.Customer.Email,
+		req.Customer.Name,
+		trialDays,
+		logging.GetCorrelationID(ctx),
+	)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao criar entidade subscription", err, map[string]interface{}{
+			"plan_id": req.PlanID,
+		})
+		return nil, fmt.Errorf("erro ao criar subscription: %w", err)
+	}
+
+	if req.PaymentMethodID != "" {
+		subscription.AttachPaymentMethod(req.PaymentMethodID)
+	}
+
+	s.logger.Info(ctx, operation, "Salvando subscription aguardando customer", map[string]interface{}{
+		"subscription_id": subscription.ID().String(),
+		"customer_email":  req.Customer.Email,
+		"plan_id":         req.PlanID,
+	})
+
+	if err := s.repository.Create(ctx, subscription); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao salvar subscription no banco", err, map[string]interface{}{
+			"subscription_id": subscription.ID().String(),
+			"plan_id":         req.PlanID,
+		})
+		return nil, fmt.Errorf("erro ao salvar subscription: %w", err)
+	}
+
+	response := s.toSubscriptionResponse(subscription)
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"subscription_id": response.ID,
+		"plan_id":         response.PlanID,
+		"status":          response.Status,
+		"onboarding_mode": string(OnboardingModeAsync),
+	})
+
+	return response, nil
+}
+
+// newSubscription monta o agregado da subscription com método de pagamento e trial
+func (s *SubscriptionService) newSubscription(
+	ctx context.Context,
+	operation string,
+	req CreateSubscriptionRequest,
+	customerID string,
+	billingInterval BillingInterval,
+	trialDays int,
+) (*Subscription, error) {
+	correlationID := logging.GetCorrelationID(ctx)
+	subscription, err := NewSubscription(req.PlanID, customerID, billingInterval, correlationID)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao criar entidade subscription", err, map[string]interface{}{
+			"plan_id":     req.PlanID,
+			"customer_id": customerID,
+		})
+		return nil, fmt.Errorf("erro ao criar subscription: %w", err)
+	}
+
+	if req.PaymentMethodID != "" {
+		subscription.AttachPaymentMethod(req.PaymentMethodID)
+	}
+
+	if trialDays > 0 {
+		if err := subscription.StartTrial(trialDays, time.Now(), correlationID); err != nil {
+			s.logger.Error(ctx, operation, "Erro ao iniciar trial da subscription", err, map[string]interface{}{
+				"subscription_id": subscription.ID().String(),
+				"trial_days":      trialDays,
+			})
+			return nil, fmt.Errorf("erro ao criar subscription: %w", err)
+		}
+	}
+
+	return subscription, nil
+}
+
+// resolveCustomer obtém o customer da subscription: primeiro pelo customer_id informado, depois
+// pelo email e, só se nenhum existir, criando um novo no serviço de Customer. O retorno indica se o
+// customer foi criado nesta chamada, o que define se ele deve ser removido na compensação
+func (s *SubscriptionService) resolveCustomer(ctx context.Context, operation string, req CreateSubscriptionRequest) (*customer.CustomerResponse, bool, error) {
+	if req.Customer.CustomerID != "" {
+		customerResp, err := s.customerClient.GetCustomerByID(ctx, req.Customer.CustomerID)
+		if err != nil {
+			s.logger.Error(ctx, operation, "Erro ao buscar customer informado", err, map[string]interface{}{
+				"customer_id": req.Customer.CustomerID,
+				"plan_id":     req.PlanID,
+			})
+			return nil, false, fmt.Errorf("erro ao buscar customer: %w", err)
+		}
+
+		s.logger.Info(ctx, operation, "Reutilizando customer informado", map[string]interface{}{
+			"customer_id": customerResp.ID,
+		})
+		return customerResp, false, nil
+	}
+
+	customerResp, err := s.customerClient.FindCustomerByEmail(ctx, req.Customer.Email)
+	if err == nil {
+		s.logger.Info(ctx, operation, "Reutilizando customer existente com o mesmo email", map[string]interface{}{
+			"customer_id":    customerResp.ID,
+			"customer_email": req.Customer.Email,
+		})
+		return customerResp, false, nil
+	}
+	if !errors.Is(err, customer.ErrCustomerNotFound) {
+		s.logger.Error(ctx, operation, "Erro ao buscar customer por email", err, map[string]interface{}{
+			"customer_email": req.Customer.Email,
+			"plan_id":        req.PlanID,
+		})
+		return nil, false, fmt.Errorf("erro ao buscar customer: %w", err)
+	}
+
+	customerReq := customer.CustomerRequest{
+		Name:  req.Customer.Name,
+		Email: req.Customer.Email,
+	}
+
+	s.logger.Info(ctx, operation, "Iniciando criação de customer", map[string]interface{}{
+		"customer_email": req.Customer.Email,
+		"customer_name":  req.Customer.Name,
+	})
+
+	customerResp, err = s.customerClient.CreateCustomer(ctx, customerReq)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao criar customer", err, map[string]interface{}{
+			"customer_email": req.Customer.Email,
+			"plan_id":        req.PlanID,
+		})
+		return nil, false, fmt.Errorf("erro ao criar customer: %w", err)
+	}
+
+	s.logger.Info(ctx, operation, "Customer criado com sucesso", map[string]interface{}{
+		"customer_id":    customerResp.ID,
+		"customer_email": customerResp.Email,
+	})
+
+	return customerResp, true, nil
+}
+
 // GetSubscriptionByID busca uma subscription pelo ID
 func (s *SubscriptionService) GetSubscriptionByID(ctx context.Context, id string) (*SubscriptionResponse, error) {
 	startTime := time.Now()
@@ -187,39 +474,169 @@ func (s *SubscriptionService) GetSubscriptionByID(ctx context.Context, id string
 	return response, nil
 }
 
-// GetAllSubscriptions busca todas as subscriptions
-func (s *SubscriptionService) GetAllSubscriptions(ctx context.Context) ([]*SubscriptionResponse, error) {
+// ListSubscriptions busca uma página de subscriptions com filtros e ordenação
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, query ListSubscriptionsQuery) (*SubscriptionListResponse, error) {
 	startTime := time.Now()
-	operation := "GetAllSubscriptions"
+	operation := "ListSubscriptions"
 
 	ctx = logging.EnsureCorrelationID(ctx, "subscription")
 
-	s.logger.OperationStart(ctx, operation, nil)
+	s.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"status":      string(query.Status),
+		"plan_id":     query.PlanID,
+		"customer_id": query.CustomerID,
+		"sort":        string(query.Sort),
+		"limit":       query.Limit,
+	})
+
+	if err := query.Validate(); err != nil {
+		s.logger.Error(ctx, operation, "Parâmetros de listagem inválidos", err, nil)
+		return nil, fmt.Errorf("erro ao listar subscriptions: %w", err)
+	}
 
-	subscriptions, err := s.repository.GetAll(ctx)
+	page, err := s.repository.List(ctx, query)
 	if err != nil {
-		s.logger.Error(ctx, operation, "Erro ao buscar todas as subscriptions", err, nil)
+		s.logger.Error(ctx, operation, "Erro ao listar subscriptions", err, nil)
 		return nil, fmt.Errorf("erro ao buscar subscriptions: %w", err)
 	}
 
-	responses := make([]*SubscriptionResponse, len(subscriptions))
-	for i, subscription := range subscriptions {
-		responses[i] = s.toSubscriptionResponse(subscription)
+	response := s.toSubscriptionListResponse(page)
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"total_found":   len(response.Subscriptions),
+		"has_next_page": response.NextCursor != "",
+	})
+
+	return response, nil
+}
+
+// ListCustomerSubscriptions busca uma página das subscriptions de um customer
+func (s *SubscriptionService) ListCustomerSubscriptions(ctx context.Context, customerID string, query ListSubscriptionsQuery) (*SubscriptionListResponse, error) {
+	startTime := time.Now()
+	operation := "ListCustomerSubscriptions"
+
+	ctx = logging.EnsureCorrelationID(ctx, "subscription")
+
+	s.logger.OperationStart(ctx, operation, map[string]interface{}{
+		"customer_id": customerID,
+		"status":      string(query.Status),
+		"sort":        string(query.Sort),
+		"limit":       query.Limit,
+	})
+
+	customerIDValue, err := NewCustomerID(customerID)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Customer ID inválido", err, map[string]interface{}{
+			"provided_id": customerID,
+		})
+		return nil, fmt.Errorf("customer ID inválido: %w", err)
+	}
+
+	if err := query.Validate(); err != nil {
+		s.logger.Error(ctx, operation, "Parâmetros de listagem inválidos", err, nil)
+		return nil, fmt.Errorf("erro ao listar subscriptions do customer: %w", err)
+	}
+
+	page, err := s.repository.GetByCustomerID(ctx, customerIDValue, query)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao listar subscriptions do customer", err, map[string]interface{}{
+			"customer_id": customerID,
+		})
+		return nil, fmt.Errorf("erro ao buscar subscriptions do customer: %w", err)
 	}
 
+	response := s.toSubscriptionListResponse(page)
+
 	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
-		"total_found": len(responses),
+		"customer_id":   customerID,
+		"total_found":   len(response.Subscriptions),
+		"has_next_page": response.NextCursor != "",
 	})
 
-	return responses, nil
+	return response, nil
+}
+
+// toSubscriptionListResponse converte uma página de subscriptions na resposta da listagem
+func (s *SubscriptionService) toSubscriptionListResponse(page *SubscriptionPage) *SubscriptionListResponse {
+	response := &SubscriptionListResponse{
+		Subscriptions: make([]*SubscriptionResponse, len(page.Subscriptions)),
+	}
+	for i, subscription := range page.Subscriptions {
+		response.Subscriptions[i] = s.toSubscriptionResponse(subscription)
+	}
+	if page.NextCursor != nil {
+		response.NextCursor = page.NextCursor.Encode()
+	}
+	return response
 }
 
 // ActivateSubscription ativa uma subscription
 func (s *SubscriptionService) ActivateSubscription(ctx context.Context, id, correlationID string) error {
+	return s.changeSubscriptionStatus(ctx, "ActivateSubscription", id, correlationID, nil,
+		func(subscription *Subscription, correlationID string) error {
+			return subscription.Activate(correlationID)
+		})
+}
+
+// CancelSubscription cancela uma subscription
+func (s *SubscriptionService) CancelSubscription(ctx context.Context, id, reason, correlationID string) error {
+	return s.changeSubscriptionStatus(ctx, "CancelSubscription", id, correlationID,
+		map[string]interface{}{"reason": reason},
+		func(subscription *Subscription, correlationID string) error {
+			return subscription.Cancel(reason, correlationID)
+		})
+}
+
+// SuspendSubscription suspende uma subscription ativa
+func (s *SubscriptionService) SuspendSubscription(ctx context.Context, id, reason, correlationID string) error {
+	return s.changeSubscriptionStatus(ctx, "SuspendSubscription", id, correlationID,
+		map[string]interface{}{"reason": reason},
+		func(subscription *Subscription, correlationID string) error {
+			return subscription.Suspend(reason, correlationID)
+		})
+}
+
+// ResumeSubscription reativa uma subscription suspensa
+func (s *SubscriptionService) ResumeSubscription(ctx context.Context, id, reason, correlationID string) error {
+	return s.changeSubscriptionStatus(ctx, "ResumeSubscription", id, correlationID,
+		map[string]interface{}{"reason": reason},
+		func(subscription *Subscription, correlationID string) error {
+			return subscription.Resume(reason, correlationID)
+		})
+}
+
+// AttachPaymentMethod associa um meio de pagamento à subscription, ex: para converter um trial ao fim
+func (s *SubscriptionService) AttachPaymentMethod(ctx context.Context, id, paymentMethodID, correlationID string) error {
+	return s.changeSubscriptionStatus(ctx, "AttachPaymentMethod", id, correlationID,
+		map[string]interface{}{"payment_method_id": paymentMethodID},
+		func(subscription *Subscription, correlationID string) error {
+			return subscription.ChangePaymentMethod(paymentMethodID, correlationID)
+		})
+}
+
+// ScheduleCancellation agenda o cancelamento da subscription para o fim do período corrente
+func (s *SubscriptionService) ScheduleCancellation(ctx context.Context, id, reason, correlationID string) error {
+	return s.changeSubscriptionStatus(ctx, "ScheduleCancellation", id, correlationID,
+		map[string]interface{}{"reason": reason},
+		func(subscription *Subscription, correlationID string) error {
+			return subscription.ScheduleCancellation(reason, time.Now(), correlationID)
+		})
+}
+
+// UndoScheduledCancellation desfaz o cancelamento agendado da subscription
+func (s *SubscriptionService) UndoScheduledCancellation(ctx context.Context, id, reason, correlationID string) error {
+	return s.changeSubscriptionStatus(ctx, "UndoScheduledCancellation", id, correlationID,
+		map[string]interface{}{"reason": reason},
+		func(subscription *Subscription, correlationID string) error {
+			return subscription.UndoScheduledCancellation(time.Now(), correlationID)
+		})
+}
+
+// ChangePlan troca o plano de uma subscription, imediatamente (com proration) ou no fim do período
+func (s *SubscriptionService) ChangePlan(ctx context.Context, id string, req ChangePlanRequest, correlationID string) (*PlanChangeResponse, error) {
 	startTime := time.Now()
-	operation := "ActivateSubscription"
+	operation := "ChangePlan"
 
-	// Usar o correlation ID fornecido ou gerar um novo
 	if correlationID != "" {
 		ctx = logging.WithCorrelationID(ctx, correlationID)
 	} else {
@@ -228,9 +645,149 @@ func (s *SubscriptionService) ActivateSubscription(ctx context.Context, id, corr
 
 	s.logger.OperationStart(ctx, operation, map[string]interface{}{
 		"subscription_id": id,
-		"correlation_id":  correlationID,
+		"new_plan_id":     req.PlanID,
+		"timing":          req.Timing,
+	})
+
+	timing := PlanChangeTiming(req.Timing)
+	if timing == "" {
+		timing = PlanChangeImmediate
+	}
+
+	subscriptionID, err := NewSubscriptionIDFromString(id)
+	if err != nil {
+		s.logger.Error(ctx, operation, "ID de subscription inválido", err, map[string]interface{}{
+			"provided_id": id,
+		})
+		return nil, fmt.Errorf("ID inválido: %w", err)
+	}
+
+	if req.PlanID == "" {
+		return nil, fmt.Errorf("erro ao trocar plano: %w", ErrInvalidPlanID)
+	}
+
+	subscription, err := s.repository.GetByID(ctx, subscriptionID)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao buscar subscription", err, map[string]interface{}{
+			"subscription_id": id,
+		})
+		return nil, fmt.Errorf("erro ao buscar subscription: %w", err)
+	}
+
+	currentPlan, err := s.plans.GetByID(ctx, subscription.PlanID().String())
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao buscar plano atual", err, map[string]interface{}{
+			"plan_id": subscription.PlanID().String(),
+		})
+		return nil, fmt.Errorf("erro ao buscar plano atual: %w", err)
+	}
+
+	targetPlan, err := s.plans.GetByID(ctx, req.PlanID)
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro ao buscar novo plano", err, map[string]interface{}{
+			"plan_id": req.PlanID,
+		})
+		return nil, fmt.Errorf("erro ao buscar novo plano: %w", err)
+	}
+
+	if targetPlan.IsArchived() {
+		return nil, fmt.Errorf("erro ao trocar plano: %w", plan.ErrPlanArchived)
+	}
+
+	target, err := toPlanChangeTarget(targetPlan)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao trocar plano: %w", err)
+	}
+
+	previousPlanID := subscription.PlanID().String()
+	now := time.Now()
+	currentPricing := PlanPricing{Amount: currentPlan.PriceAmount(), Currency: currentPlan.Currency()}
+
+	proration, err := subscription.ChangePlan(target, currentPricing, timing, now, logging.GetCorrelationID(ctx))
+	if err != nil {
+		s.logger.Error(ctx, operation, "Erro na troca de plano da subscription", err, map[string]interface{}{
+			"subscription_id": id,
+			"current_status":  string(subscription.Status()),
+			"new_plan_id":     req.PlanID,
+		})
+		return nil, fmt.Errorf("erro ao trocar plano: %w", err)
+	}
+
+	if err := s.repository.Update(ctx, subscription); err != nil {
+		s.logger.Error(ctx, operation, "Erro ao atualizar subscription no banco", err, map[string]interface{}{
+			"subscription_id": id,
+		})
+		return nil, fmt.Errorf("erro ao atualizar subscription: %w", err)
+	}
+
+	effectiveAt := now
+	if timing == PlanChangeEndOfPeriod {
+		effectiveAt = subscription.CurrentPeriodEnd()
+	}
+
+	response := &PlanChangeResponse{
+		Subscription:    s.toSubscriptionResponse(subscription),
+		PreviousPlanID:  previousPlanID,
+		NewPlanID:       targetPlan.ID(),
+		Timing:          string(timing),
+		ProrationAmount: proration,
+		Currency:        targetPlan.Currency(),
+		EffectiveAt:     effectiveAt.Format(time.RFC3339Nano),
+	}
+
+	s.logger.OperationEnd(ctx, operation, startTime, map[string]interface{}{
+		"subscription_id":  id,
+		"new_plan_id":      response.NewPlanID,
+		"proration_amount": proration,
 	})
 
+	return response, nil
+}
+
+// toPlanChangeTarget converte um plano do catálogo no destino de uma troca de plano
+func toPlanChangeTarget(p *plan.Plan) (PlanChangeTarget, error) {
+	planID, err := NewPlanID(p.ID())
+	if err != nil {
+		return PlanChangeTarget{}, err
+	}
+
+	billingInterval, err := NewBillingInterval(string(p.Interval()), p.IntervalDays())
+	if err != nil {
+		return PlanChangeTarget{}, err
+	}
+
+	return PlanChangeTarget{
+		PlanID:          planID,
+		BillingInterval: billingInterval,
+		Pricing:         PlanPricing{Amount: p.PriceAmount(), Currency: p.Currency()},
+	}, nil
+}
+
+// changeSubscriptionStatus carrega a subscription, aplica a transição de status e persiste o resultado
+func (s *SubscriptionService) changeSubscriptionStatus(
+	ctx context.Context,
+	operation, id, correlationID string,
+	contextData map[string]interface{},
+	transition func(subscription *Subscription, correlationID string) error,
+) error {
+	startTime := time.Now()
+
+	// Usar o correlation ID fornecido ou gerar um novo
+	if correlationID != "" {
+		ctx = logging.WithCorrelationID(ctx, correlationID)
+	} else {
+		ctx = logging.EnsureCorrelationID(ctx, "subscription")
+	}
+
+	startData := map[string]interface{}{
+		"subscription_id": id,
+		"correlation_id":  correlationID,
+	}
+	for key, value := range contextData {
+		startData[key] = value
+	}
+	s.logger.OperationStart(ctx, operation, startData)
+
 	subscriptionID, err := NewSubscriptionIDFromString(id)
 	if err != nil {
 		s.logger.Error(ctx, operation, "ID de subscription inválido", err, map[string]interface{}{
@@ -248,12 +805,12 @@ func (s *SubscriptionService) ActivateSubscription(ctx context.Context, id, corr
 	}
 
 	currentCorrelationID := logging.GetCorrelationID(ctx)
-	if err := subscription.Activate(currentCorrelationID); err != nil {
-		s.logger.Error(ctx, operation, "Erro na ativação da subscription", err, map[string]interface{}{
+	if err := transition(subscription, currentCorrelationID); err != nil {
+		s.logger.Error(ctx, operation, "Erro na transição de status da subscription", err, map[string]interface{}{
 			"subscription_id": id,
 			"current_status":  string(subscription.Status()),
 		})
-		return fmt.Errorf("erro ao ativar subscription: %w", err)
+		return fmt.Errorf("erro ao alterar status da subscription: %w", err)
 	}
 
 	if err := s.repository.Update(ctx, subscription); err != nil {
@@ -274,12 +831,31 @@ func (s *SubscriptionService) ActivateSubscription(ctx context.Context, id, corr
 
 // toSubscriptionResponse converte uma Subscription para SubscriptionResponse
 func (s *SubscriptionService) toSubscriptionResponse(subscription *Subscription) *SubscriptionResponse {
-	return &SubscriptionResponse{
-		ID:         subscription.ID().String(),
-		PlanID:     subscription.PlanID().String(),
-		CustomerID: subscription.CustomerID().String(),
-		Status:     string(subscription.Status()),
-		CreatedAt:  subscription.CreatedAt().Format(time.RFC3339Nano),
-		UpdatedAt:  subscription.UpdatedAt().Format(time.RFC3339Nano),
+	response := &SubscriptionResponse{
+		ID:                  subscription.ID().String(),
+		PlanID:              subscription.PlanID().String(),
+		CustomerID:          subscription.CustomerID().String(),
+		Status:              string(subscription.Status()),
+		BillingInterval:     string(subscription.BillingInterval().Unit()),
+		BillingIntervalDays: subscription.BillingInterval().Days(),
+		HasPaymentMethod:    subscription.HasPaymentMethod(),
+		AwaitingCustomer:    subscription.AwaitingCustomer(),
+		PendingPlanID:       subscription.PendingPlanID().String(),
+		CancelAtPeriodEnd:   subscription.CancelAtPeriodEnd(),
+		Version:             subscription.Version(),
+		CreatedAt:           subscription.CreatedAt().Format(time.RFC3339Nano),
+		UpdatedAt:           subscription.UpdatedAt().Format(time.RFC3339Nano),
+	}
+
+	if !subscription.TrialEndsAt().IsZero() {
+		response.TrialEndsAt = subscription.TrialEndsAt().Format(time.RFC3339Nano)
+	}
+
+	if subscription.HasStartedBilling() {
+		response.CurrentPeriodStart = subscription.CurrentPeriodStart().Format(time.RFC3339Nano)
+		response.CurrentPeriodEnd = subscription.CurrentPeriodEnd().Format(time.RFC3339Nano)
+		response.NextRenewalAt = response.CurrentPeriodEnd
 	}
+
+	return response
 }
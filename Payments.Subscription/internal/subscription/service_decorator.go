@@ -3,6 +3,7 @@ package subscription
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -40,10 +41,20 @@ func (d *SubscriptionServiceTracingDecorator) addRequestToSpan(span trace.Span,
 	}
 }
 
+// addErrorToSpan adiciona o erro ao span, indicando se foi causado por timeout
+func (d *SubscriptionServiceTracingDecorator) addErrorToSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetAttributes(
+			attribute.String("error", err.Error()),
+			attribute.Bool("timeout", errors.Is(err, context.DeadlineExceeded)),
+		)
+	}
+}
+
 // addResponseToSpan adiciona dados da response ao span
 func (d *SubscriptionServiceTracingDecorator) addResponseToSpan(span trace.Span, resp interface{}, err error) {
 	if err != nil {
-		span.SetAttributes(attribute.String("error", err.Error()))
+		d.addErrorToSpan(span, err)
 		return
 	}
 
@@ -126,23 +137,68 @@ func (d *SubscriptionServiceTracingDecorator) GetSubscriptionByID(ctx context.Co
 	return response, err
 }
 
-// GetAllSubscriptions adiciona tracing e logging à operação de busca de todas as subscriptions
-func (d *SubscriptionServiceTracingDecorator) GetAllSubscriptions(ctx context.Context) ([]*SubscriptionResponse, error) {
+// ListSubscriptions adiciona tracing e logging à listagem paginada de subscriptions
+func (d *SubscriptionServiceTracingDecorator) ListSubscriptions(ctx context.Context, query ListSubscriptionsQuery) (*SubscriptionListResponse, error) {
 	start := time.Now()
-	ctx, span := d.tracer.Start(ctx, "Service.GetAllSubscriptions")
+	ctx, span := d.tracer.Start(ctx, "Service.ListSubscriptions")
 	defer span.End()
 
-	response, err := d.service.GetAllSubscriptions(ctx)
+	// Adiciona os filtros da consulta ao span
+	span.SetAttributes(
+		attribute.String("query.status", string(query.Status)),
+		attribute.String("query.plan_id", query.PlanID),
+		attribute.String("query.customer_id", query.CustomerID),
+		attribute.String("query.sort", string(query.Sort)),
+		attribute.Int("query.limit", query.Limit),
+		attribute.Bool("query.has_cursor", query.After != nil),
+	)
+
+	response, err := d.service.ListSubscriptions(ctx, query)
 
 	// Adiciona response ou erro ao span
 	d.addResponseToSpan(span, response, err)
 
 	// Adiciona contagem de resultados se não houver erro
 	if err == nil {
-		span.SetAttributes(attribute.Int("response.count", len(response)))
+		span.SetAttributes(
+			attribute.Int("response.count", len(response.Subscriptions)),
+			attribute.Bool("response.has_next_page", response.NextCursor != ""),
+		)
 	}
 
-	d.logExecutionTime(ctx, "GetAllSubscriptions", start, err)
+	d.logExecutionTime(ctx, "ListSubscriptions", start, err)
+	return response, err
+}
+
+// ListCustomerSubscriptions adiciona tracing e logging à listagem das subscriptions de um customer
+func (d *SubscriptionServiceTracingDecorator) ListCustomerSubscriptions(ctx context.Context, customerID string, query ListSubscriptionsQuery) (*SubscriptionListResponse, error) {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.ListCustomerSubscriptions")
+	defer span.End()
+
+	// Adiciona os filtros da consulta ao span
+	span.SetAttributes(
+		attribute.String("customer_id", customerID),
+		attribute.String("query.status", string(query.Status)),
+		attribute.String("query.sort", string(query.Sort)),
+		attribute.Int("query.limit", query.Limit),
+		attribute.Bool("query.has_cursor", query.After != nil),
+	)
+
+	response, err := d.service.ListCustomerSubscriptions(ctx, customerID, query)
+
+	// Adiciona response ou erro ao span
+	d.addResponseToSpan(span, response, err)
+
+	// Adiciona contagem de resultados se não houver erro
+	if err == nil {
+		span.SetAttributes(
+			attribute.Int("response.count", len(response.Subscriptions)),
+			attribute.Bool("response.has_next_page", response.NextCursor != ""),
+		)
+	}
+
+	d.logExecutionTime(ctx, "ListCustomerSubscriptions", start, err)
 	return response, err
 }
 
@@ -161,10 +217,112 @@ func (d *SubscriptionServiceTracingDecorator) ActivateSubscription(ctx context.C
 	err := d.service.ActivateSubscription(ctx, id, correlationID)
 
 	// Adiciona erro ao span se houver
-	if err != nil {
-		span.SetAttributes(attribute.String("error", err.Error()))
-	}
+	d.addErrorToSpan(span, err)
 
 	d.logExecutionTime(ctx, "ActivateSubscription", start, err)
 	return err
 }
+
+// CancelSubscription adiciona tracing e logging à operação de cancelamento
+func (d *SubscriptionServiceTracingDecorator) CancelSubscription(ctx context.Context, id, reason, correlationID string) error {
+	return d.traceStatusChange(ctx, "CancelSubscription", id, reason, correlationID, func(ctx context.Context) error {
+		return d.service.CancelSubscription(ctx, id, reason, correlationID)
+	})
+}
+
+// SuspendSubscription adiciona tracing e logging à operação de suspensão
+func (d *SubscriptionServiceTracingDecorator) SuspendSubscription(ctx context.Context, id, reason, correlationID string) error {
+	return d.traceStatusChange(ctx, "SuspendSubscription", id, reason, correlationID, func(ctx context.Context) error {
+		return d.service.SuspendSubscription(ctx, id, reason, correlationID)
+	})
+}
+
+// ResumeSubscription adiciona tracing e logging à operação de reativação
+func (d *SubscriptionServiceTracingDecorator) ResumeSubscription(ctx context.Context, id, reason, correlationID string) error {
+	return d.traceStatusChange(ctx, "ResumeSubscription", id, reason, correlationID, func(ctx context.Context) error {
+		return d.service.ResumeSubscription(ctx, id, reason, correlationID)
+	})
+}
+
+// ScheduleCancellation adiciona tracing e logging ao agendamento de cancelamento
+func (d *SubscriptionServiceTracingDecorator) ScheduleCancellation(ctx context.Context, id, reason, correlationID string) error {
+	return d.traceStatusChange(ctx, "ScheduleCancellation", id, reason, correlationID, func(ctx context.Context) error {
+		return d.service.ScheduleCancellation(ctx, id, reason, correlationID)
+	})
+}
+
+// UndoScheduledCancellation adiciona tracing e logging à remoção do cancelamento agendado
+func (d *SubscriptionServiceTracingDecorator) UndoScheduledCancellation(ctx context.Context, id, reason, correlationID string) error {
+	return d.traceStatusChange(ctx, "UndoScheduledCancellation", id, reason, correlationID, func(ctx context.Context) error {
+		return d.service.UndoScheduledCancellation(ctx, id, reason, correlationID)
+	})
+}
+
+// AttachPaymentMethod adiciona tracing e logging à associação do meio de pagamento
+func (d *SubscriptionServiceTracingDecorator) AttachPaymentMethod(ctx context.Context, id, paymentMethodID, correlationID string) error {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.AttachPaymentMethod")
+	defer span.End()
+
+	// Adiciona dados da request ao span
+	span.SetAttributes(
+		attribute.String("subscription_id", id),
+		attribute.String("payment_method_id", paymentMethodID),
+		attribute.String("correlation_id", correlationID),
+	)
+
+	err := d.service.AttachPaymentMethod(ctx, id, paymentMethodID, correlationID)
+
+	// Adiciona erro ao span se houver
+	d.addErrorToSpan(span, err)
+
+	d.logExecutionTime(ctx, "AttachPaymentMethod", start, err)
+	return err
+}
+
+// ChangePlan adiciona tracing e logging à operação de troca de plano
+func (d *SubscriptionServiceTracingDecorator) ChangePlan(ctx context.Context, id string, req ChangePlanRequest, correlationID string) (*PlanChangeResponse, error) {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service.ChangePlan")
+	defer span.End()
+
+	// Adiciona dados da request ao span
+	span.SetAttributes(
+		attribute.String("subscription_id", id),
+		attribute.String("correlation_id", correlationID),
+	)
+	d.addRequestToSpan(span, req)
+
+	response, err := d.service.ChangePlan(ctx, id, req, correlationID)
+
+	// Adiciona response ou erro ao span
+	d.addResponseToSpan(span, response, err)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("proration_amount", response.ProrationAmount))
+	}
+
+	d.logExecutionTime(ctx, "ChangePlan", start, err)
+	return response, err
+}
+
+// traceStatusChange cria o span comum às operações de mudança de status
+func (d *SubscriptionServiceTracingDecorator) traceStatusChange(ctx context.Context, methodName, id, reason, correlationID string, call func(ctx context.Context) error) error {
+	start := time.Now()
+	ctx, span := d.tracer.Start(ctx, "Service."+methodName)
+	defer span.End()
+
+	// Adiciona dados da request ao span
+	span.SetAttributes(
+		attribute.String("subscription_id", id),
+		attribute.String("reason", reason),
+		attribute.String("correlation_id", correlationID),
+	)
+
+	err := call(ctx)
+
+	// Adiciona erro ao span se houver
+	d.addErrorToSpan(span, err)
+
+	d.logExecutionTime(ctx, methodName, start, err)
+	return err
+}
@@ -10,13 +10,49 @@ import (
 
 // Subscription representa o agregado principal do domínio de Subscription
 type Subscription struct {
-	id         SubscriptionID
-	planID     PlanID
-	customerID CustomerID
-	status     SubscriptionStatus
-	createdAt  time.Time
-	updatedAt  time.Time
-	events     []DomainEvent
+	id                     SubscriptionID
+	planID                 PlanID
+	customerID             CustomerID
+	status                 SubscriptionStatus
+	billingInterval        BillingInterval
+	currentPeriodStart     time.Time
+	currentPeriodEnd       time.Time
+	trialEndsAt            time.Time
+	trialEndNotified       bool
+	paymentMethodID        string
+	requestedTrialDays     int
+	pendingPlanID          PlanID
+	pendingBillingInterval BillingInterval
+	cancelAtPeriodEnd      bool
+	cancellationReason     string
+	version                int
+	createdAt              time.Time
+	updatedAt              time.Time
+	events                 []DomainEvent
+}
+
+// SubscriptionSnapshot contém o estado persistido usado para reconstruir o agregado
+type SubscriptionSnapshot struct {
+	ID                  string
+	PlanID              string
+	CustomerID          string
+	Status              SubscriptionStatus
+	BillingInterval     string
+	BillingIntervalDays int
+	CurrentPeriodStart  time.Time
+	CurrentPeriodEnd    time.Time
+	TrialEndsAt         time.Time
+	TrialEndNotified    bool
+	PaymentMethodID     string
+	RequestedTrialDays  int
+	PendingPlanID       string
+	PendingInterval     string
+	PendingIntervalDays int
+	CancelAtPeriodEnd   bool
+	CancellationReason  string
+	Version             int
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 }
 
 // SubscriptionID é um value object para o ID da subscription
@@ -43,18 +79,34 @@ const (
 	SubscriptionStatusInactive  SubscriptionStatus = "inactive"
 	SubscriptionStatusCancelled SubscriptionStatus = "cancelled"
 	SubscriptionStatusSuspended SubscriptionStatus = "suspended"
+	SubscriptionStatusTrialing  SubscriptionStatus = "trialing"
 )
 
+// IsValid indica se o status é um dos status conhecidos
+func (s SubscriptionStatus) IsValid() bool {
+	switch s {
+	case SubscriptionStatusPending, SubscriptionStatusActive, SubscriptionStatusInactive,
+		SubscriptionStatusCancelled, SubscriptionStatusSuspended, SubscriptionStatusTrialing:
+		return true
+	default:
+		return false
+	}
+}
+
 // Erros do domínio
 var (
 	ErrInvalidPlanID           = errors.New("plan ID é obrigatório")
+	ErrInvalidSubscriptionID   = errors.New("ID não pode ser vazio")
 	ErrInvalidCustomerID       = errors.New("customer ID é obrigatório")
 	ErrSubscriptionNotFound    = errors.New("subscription não encontrada")
 	ErrInvalidStatusTransition = errors.New("transição de status inválida")
+	ErrPeriodNotEnded          = errors.New("período corrente ainda não terminou")
+	ErrConcurrentModification  = errors.New("subscription foi alterada por outra operação")
 )
 
 // DomainEvent representa um evento de domínio
 type DomainEvent interface {
+	EventID() string
 	EventType() string
 	AggregateID() string
 	OccurredAt() time.Time
@@ -63,23 +115,34 @@ type DomainEvent interface {
 
 // BaseEvent implementa campos comuns dos eventos
 type BaseEvent struct {
+	eventID       string
 	eventType     string
 	aggregateID   string
 	occurredAt    time.Time
 	correlationID string
 }
 
+func (e BaseEvent) EventID() string       { return e.eventID }
 func (e BaseEvent) EventType() string     { return e.eventType }
 func (e BaseEvent) AggregateID() string   { return e.aggregateID }
 func (e BaseEvent) OccurredAt() time.Time { return e.occurredAt }
 func (e BaseEvent) CorrelationID() string { return e.correlationID }
 
+// setBase substitui os campos comuns, usado ao reconstruir o evento a partir de um envelope CloudEvents
+func (e *BaseEvent) setBase(base BaseEvent) { *e = base }
+
+// newEventID gera o identificador único do evento, usado como id do envelope CloudEvents
+func newEventID() string {
+	return uuid.New().String()
+}
+
 // Eventos de domínio
 type SubscriptionRequestedEvent struct {
 	BaseEvent
 	PlanID     string `json:"plan_id"`
 	CustomerID string `json:"customer_id"`
 	Email      string `json:"email"`
+	Name       string `json:"name,omitempty"`
 }
 
 type SubscriptionReadyForActivationEvent struct {
@@ -104,6 +167,26 @@ type SubscriptionSuspendedEvent struct {
 	Reason string `json:"reason"`
 }
 
+type SubscriptionResumedEvent struct {
+	BaseEvent
+	PlanID     string `json:"plan_id"`
+	CustomerID string `json:"customer_id"`
+	Reason     string `json:"reason"`
+}
+
+type SubscriptionExpiredEvent struct {
+	BaseEvent
+	PeriodEnd time.Time `json:"period_end"`
+}
+
+type SubscriptionRenewedEvent struct {
+	BaseEvent
+	PlanID      string    `json:"plan_id"`
+	CustomerID  string    `json:"customer_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
 // NewSubscriptionID cria um novo SubscriptionID
 func NewSubscriptionID() SubscriptionID {
 	return SubscriptionID{value: uuid.New().String()}
@@ -112,7 +195,7 @@ func NewSubscriptionID() SubscriptionID {
 // NewSubscriptionIDFromString cria um SubscriptionID a partir de uma string
 func NewSubscriptionIDFromString(id string) (SubscriptionID, error) {
 	if id == "" {
-		return SubscriptionID{}, errors.New("ID não pode ser vazio")
+		return SubscriptionID{}, ErrInvalidSubscriptionID
 	}
 	return SubscriptionID{value: id}, nil
 }
@@ -149,7 +232,7 @@ func (c CustomerID) String() string {
 }
 
 // NewSubscription cria uma nova subscription
-func NewSubscription(planID, customerID string, correlationID string) (*Subscription, error) {
+func NewSubscription(planID, customerID string, billingInterval BillingInterval, correlationID string) (*Subscription, error) {
 	if err := validateSubscriptionData(planID); err != nil {
 		return nil, err
 	}
@@ -158,18 +241,20 @@ func NewSubscription(planID, customerID string, correlationID string) (*Subscrip
 	cID, _ := NewCustomerID(customerID)
 
 	subscription := &Subscription{
-		id:         NewSubscriptionID(),
-		planID:     pID,
-		customerID: cID,
-		status:     SubscriptionStatusPending,
-		createdAt:  time.Now(),
-		updatedAt:  time.Now(),
-		events:     make([]DomainEvent, 0),
+		id:              NewSubscriptionID(),
+		planID:          pID,
+		customerID:      cID,
+		status:          SubscriptionStatusPending,
+		billingInterval: billingInterval,
+		createdAt:       time.Now(),
+		updatedAt:       time.Now(),
+		events:          make([]DomainEvent, 0),
 	}
 
 	// Adiciona evento de subscription solicitada
 	event := SubscriptionRequestedEvent{
 		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
 			eventType:     "SubscriptionRequested",
 			aggregateID:   subscription.id.String(),
 			occurredAt:    time.Now(),
@@ -184,30 +269,55 @@ func NewSubscription(planID, customerID string, correlationID string) (*Subscrip
 }
 
 // ReconstructSubscription reconstrói uma subscription a partir de dados persistidos
-func ReconstructSubscription(id, planID, customerID string, status SubscriptionStatus, createdAt, updatedAt time.Time) (*Subscription, error) {
-	subscriptionID, err := NewSubscriptionIDFromString(id)
+func ReconstructSubscription(snapshot SubscriptionSnapshot) (*Subscription, error) {
+	subscriptionID, err := NewSubscriptionIDFromString(snapshot.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	pID, err := NewPlanID(planID)
+	pID, err := NewPlanID(snapshot.PlanID)
 	if err != nil {
 		return nil, err
 	}
 
-	cID, err := NewCustomerID(customerID)
+	// Subscriptions do onboarding assíncrono ficam sem customer até o evento do serviço de Customer
+	cID := CustomerID{value: snapshot.CustomerID}
+
+	billingInterval, err := NewBillingInterval(snapshot.BillingInterval, snapshot.BillingIntervalDays)
 	if err != nil {
 		return nil, err
 	}
 
+	var pendingPlanID PlanID
+	var pendingInterval BillingInterval
+	if snapshot.PendingPlanID != "" {
+		pendingPlanID = PlanID{value: snapshot.PendingPlanID}
+		pendingInterval, err = NewBillingInterval(snapshot.PendingInterval, snapshot.PendingIntervalDays)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Subscription{
-		id:         subscriptionID,
-		planID:     pID,
-		customerID: cID,
-		status:     status,
-		createdAt:  createdAt,
-		updatedAt:  updatedAt,
-		events:     make([]DomainEvent, 0),
+		id:                     subscriptionID,
+		planID:                 pID,
+		customerID:             cID,
+		status:                 snapshot.Status,
+		billingInterval:        billingInterval,
+		currentPeriodStart:     snapshot.CurrentPeriodStart,
+		currentPeriodEnd:       snapshot.CurrentPeriodEnd,
+		trialEndsAt:            snapshot.TrialEndsAt,
+		trialEndNotified:       snapshot.TrialEndNotified,
+		paymentMethodID:        snapshot.PaymentMethodID,
+		requestedTrialDays:     snapshot.RequestedTrialDays,
+		pendingPlanID:          pendingPlanID,
+		pendingBillingInterval: pendingInterval,
+		cancelAtPeriodEnd:      snapshot.CancelAtPeriodEnd,
+		cancellationReason:     snapshot.CancellationReason,
+		version:                snapshot.Version,
+		createdAt:              snapshot.CreatedAt,
+		updatedAt:              snapshot.UpdatedAt,
+		events:                 make([]DomainEvent, 0),
 	}, nil
 }
 
@@ -228,6 +338,25 @@ func (s *Subscription) Status() SubscriptionStatus {
 	return s.status
 }
 
+func (s *Subscription) BillingInterval() BillingInterval {
+	return s.billingInterval
+}
+
+// CurrentPeriodStart retorna o início do período corrente (zero enquanto não ativada)
+func (s *Subscription) CurrentPeriodStart() time.Time {
+	return s.currentPeriodStart
+}
+
+// CurrentPeriodEnd retorna o fim do período corrente, que também é a próxima data de renovação
+func (s *Subscription) CurrentPeriodEnd() time.Time {
+	return s.currentPeriodEnd
+}
+
+// HasStartedBilling indica se a subscription já possui um período de cobrança
+func (s *Subscription) HasStartedBilling() bool {
+	return !s.currentPeriodStart.IsZero()
+}
+
 func (s *Subscription) CreatedAt() time.Time {
 	return s.createdAt
 }
@@ -236,6 +365,11 @@ func (s *Subscription) UpdatedAt() time.Time {
 	return s.updatedAt
 }
 
+// Version retorna a versão persistida usada no controle de concorrência otimista (zero se nunca persistida)
+func (s *Subscription) Version() int {
+	return s.version
+}
+
 func (s *Subscription) Events() []DomainEvent {
 	return s.events
 }
@@ -248,6 +382,7 @@ func (s *Subscription) MarkAsReadyForActivation(correlationID string) error {
 
 	event := SubscriptionReadyForActivationEvent{
 		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
 			eventType:     "SubscriptionReadyForActivation",
 			aggregateID:   s.id.String(),
 			occurredAt:    time.Now(),
@@ -264,15 +399,27 @@ func (s *Subscription) MarkAsReadyForActivation(correlationID string) error {
 
 // Activate ativa a subscription
 func (s *Subscription) Activate(correlationID string) error {
-	if s.status != SubscriptionStatusPending && s.status != SubscriptionStatusSuspended {
+	if s.status != SubscriptionStatusPending && s.status != SubscriptionStatusSuspended && s.status != SubscriptionStatusTrialing {
+		return ErrInvalidStatusTransition
+	}
+
+	// Sem customer vinculado não há quem cobrar
+	if s.AwaitingCustomer() {
 		return ErrInvalidStatusTransition
 	}
 
+	now := time.Now()
 	s.status = SubscriptionStatusActive
-	s.updatedAt = time.Now()
+	s.updatedAt = now
+
+	// A primeira ativação (inclusive o fim antecipado do trial) inicia o primeiro período de cobrança
+	if !s.HasStartedBilling() {
+		s.startPeriod(now)
+	}
 
 	event := SubscriptionActivatedEvent{
 		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
 			eventType:     "SubscriptionActivated",
 			aggregateID:   s.id.String(),
 			occurredAt:    time.Now(),
@@ -294,9 +441,12 @@ func (s *Subscription) Cancel(reason, correlationID string) error {
 
 	s.status = SubscriptionStatusCancelled
 	s.updatedAt = time.Now()
+	s.clearScheduledCancellation()
+	s.clearPendingPlanChange()
 
 	event := SubscriptionCancelledEvent{
 		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
 			eventType:     "SubscriptionCancelled",
 			aggregateID:   s.id.String(),
 			occurredAt:    time.Now(),
@@ -320,6 +470,7 @@ func (s *Subscription) Suspend(reason, correlationID string) error {
 
 	event := SubscriptionSuspendedEvent{
 		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
 			eventType:     "SubscriptionSuspended",
 			aggregateID:   s.id.String(),
 			occurredAt:    time.Now(),
@@ -332,6 +483,80 @@ func (s *Subscription) Suspend(reason, correlationID string) error {
 	return nil
 }
 
+// Renew avança a subscription para o próximo período quando o período corrente terminou
+func (s *Subscription) Renew(now time.Time, correlationID string) error {
+	// Com cancelamento agendado o fim do período encerra a subscription em vez de renová-la
+	if s.status != SubscriptionStatusActive || !s.HasStartedBilling() || s.cancelAtPeriodEnd {
+		return ErrInvalidStatusTransition
+	}
+
+	if now.Before(s.currentPeriodEnd) {
+		return ErrPeriodNotEnded
+	}
+
+	// Uma troca de plano agendada passa a valer no novo período
+	s.applyPendingPlanChange(now, correlationID)
+
+	s.startPeriod(s.currentPeriodEnd)
+	s.updatedAt = now
+
+	event := SubscriptionRenewedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionRenewed",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PlanID:      s.planID.String(),
+		CustomerID:  s.customerID.String(),
+		PeriodStart: s.currentPeriodStart,
+		PeriodEnd:   s.currentPeriodEnd,
+	}
+
+	s.addEvent(event)
+	return nil
+}
+
+// Expire encerra uma subscription suspensa cujo período terminou sem reativação
+func (s *Subscription) Expire(now time.Time, correlationID string) error {
+	if s.status != SubscriptionStatusSuspended {
+		return ErrInvalidStatusTransition
+	}
+
+	if !s.IsPeriodEnded(now) {
+		return ErrPeriodNotEnded
+	}
+
+	s.status = SubscriptionStatusInactive
+	s.updatedAt = now
+
+	event := SubscriptionExpiredEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionExpired",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PeriodEnd: s.currentPeriodEnd,
+	}
+
+	s.addEvent(event)
+	return nil
+}
+
+// IsPeriodEnded verifica se o período corrente já terminou no instante informado
+func (s *Subscription) IsPeriodEnded(now time.Time) bool {
+	return s.HasStartedBilling() && !now.Before(s.currentPeriodEnd)
+}
+
+// startPeriod inicia um novo período de cobrança a partir de start
+func (s *Subscription) startPeriod(start time.Time) {
+	s.currentPeriodStart = start
+	s.currentPeriodEnd = s.billingInterval.NextPeriodEnd(start)
+}
+
 // IsActive verifica se a subscription está ativa
 func (s *Subscription) IsActive() bool {
 	return s.status == SubscriptionStatusActive
@@ -342,6 +567,44 @@ func (s *Subscription) IsPending() bool {
 	return s.status == SubscriptionStatusPending
 }
 
+// IsSuspended verifica se a subscription está suspensa
+func (s *Subscription) IsSuspended() bool {
+	return s.status == SubscriptionStatusSuspended
+}
+
+// Resume reativa uma subscription suspensa
+func (s *Subscription) Resume(reason, correlationID string) error {
+	if s.status != SubscriptionStatusSuspended {
+		return ErrInvalidStatusTransition
+	}
+
+	now := time.Now()
+	s.status = SubscriptionStatusActive
+	s.updatedAt = now
+
+	event := SubscriptionResumedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionResumed",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PlanID:     s.planID.String(),
+		CustomerID: s.customerID.String(),
+		Reason:     reason,
+	}
+
+	s.addEvent(event)
+	return nil
+}
+
+// MarkPersisted registra a nova versão gravada e limpa os eventos já persistidos
+func (s *Subscription) MarkPersisted(version int) {
+	s.version = version
+	s.ClearEvents()
+}
+
 // ClearEvents limpa os eventos (usado após persistência)
 func (s *Subscription) ClearEvents() {
 	s.events = make([]DomainEvent, 0)
@@ -368,12 +631,28 @@ type SubscriptionRepository interface {
 	// GetByID busca uma subscription pelo ID
 	GetByID(ctx context.Context, id SubscriptionID) (*Subscription, error)
 
-	// GetByCustomerID busca subscriptions pelo customer ID
-	GetByCustomerID(ctx context.Context, customerID CustomerID) ([]*Subscription, error)
+	// GetByCustomerID busca uma página das subscriptions de um customer, aplicando filtros e ordenação
+	GetByCustomerID(ctx context.Context, customerID CustomerID, query ListSubscriptionsQuery) (*SubscriptionPage, error)
 
 	// Update atualiza uma subscription existente
 	Update(ctx context.Context, subscription *Subscription) error
 
-	// GetAll busca todas as subscriptions
-	GetAll(ctx context.Context) ([]*Subscription, error)
+	// List busca uma página de subscriptions aplicando filtros e ordenação
+	List(ctx context.Context, query ListSubscriptionsQuery) (*SubscriptionPage, error)
+
+	// ClaimDueSubscriptions reserva, via lease, subscriptions cujo período terminou
+	ClaimDueSubscriptions(ctx context.Context, query DueSubscriptionsQuery) ([]*Subscription, error)
+
+	// ReleaseLease libera o lease de uma subscription reservada pelo owner
+	ReleaseLease(ctx context.Context, id SubscriptionID, owner string) error
+}
+
+// DueSubscriptionsQuery define os parâmetros para reservar subscriptions vencidas
+type DueSubscriptionsQuery struct {
+	// Owner é o token da reserva; linhas que falharam ficam com o lease até expirar
+	Owner         string
+	Now           time.Time
+	LeaseDuration time.Duration
+	TrialNotice   time.Duration
+	Limit         int
 }
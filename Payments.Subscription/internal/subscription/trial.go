@@ -0,0 +1,212 @@
+package subscription
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidTrialDays indica uma duração de trial negativa
+	ErrInvalidTrialDays = errors.New("dias de trial inválidos")
+	// ErrInvalidPaymentMethodID indica um meio de pagamento vazio
+	ErrInvalidPaymentMethodID = errors.New("payment method ID é obrigatório")
+)
+
+// TrialOutcome representa o resultado do fim de um trial
+type TrialOutcome string
+
+const (
+	TrialOutcomeConverted TrialOutcome = "converted"
+	TrialOutcomeCancelled TrialOutcome = "cancelled"
+)
+
+// TrialCancellationReason é o motivo registrado quando o trial termina sem meio de pagamento
+const TrialCancellationReason = "trial ended without payment method"
+
+type SubscriptionTrialStartedEvent struct {
+	BaseEvent
+	PlanID      string    `json:"plan_id"`
+	CustomerID  string    `json:"customer_id"`
+	TrialEndsAt time.Time `json:"trial_ends_at"`
+}
+
+type SubscriptionTrialWillEndEvent struct {
+	BaseEvent
+	CustomerID       string    `json:"customer_id"`
+	TrialEndsAt      time.Time `json:"trial_ends_at"`
+	HasPaymentMethod bool      `json:"has_payment_method"`
+}
+
+type SubscriptionPaymentMethodAttachedEvent struct {
+	BaseEvent
+	CustomerID      string `json:"customer_id"`
+	PaymentMethodID string `json:"payment_method_id"`
+}
+
+type SubscriptionTrialEndedEvent struct {
+	BaseEvent
+	CustomerID string       `json:"customer_id"`
+	Outcome    TrialOutcome `json:"outcome"`
+}
+
+// TrialEndsAt retorna a data de fim do trial (zero se a subscription não teve trial)
+func (s *Subscription) TrialEndsAt() time.Time {
+	return s.trialEndsAt
+}
+
+// TrialEndNotified indica se o aviso de fim de trial já foi emitido
+func (s *Subscription) TrialEndNotified() bool {
+	return s.trialEndNotified
+}
+
+// PaymentMethodID retorna o meio de pagamento associado à subscription
+func (s *Subscription) PaymentMethodID() string {
+	return s.paymentMethodID
+}
+
+// HasPaymentMethod indica se há meio de pagamento para converter o trial
+func (s *Subscription) HasPaymentMethod() bool {
+	return s.paymentMethodID != ""
+}
+
+// IsTrialing verifica se a subscription está em trial
+func (s *Subscription) IsTrialing() bool {
+	return s.status == SubscriptionStatusTrialing
+}
+
+// AttachPaymentMethod associa um meio de pagamento à subscription
+func (s *Subscription) AttachPaymentMethod(paymentMethodID string) {
+	s.paymentMethodID = paymentMethodID
+	s.updatedAt = time.Now()
+}
+
+// ChangePaymentMethod associa ou troca o meio de pagamento de uma subscription ainda vigente,
+// permitindo que um trial iniciado sem meio de pagamento seja convertido ao terminar
+func (s *Subscription) ChangePaymentMethod(paymentMethodID, correlationID string) error {
+	if paymentMethodID == "" {
+		return ErrInvalidPaymentMethodID
+	}
+
+	if s.status == SubscriptionStatusCancelled || s.status == SubscriptionStatusInactive {
+		return ErrInvalidStatusTransition
+	}
+
+	s.AttachPaymentMethod(paymentMethodID)
+
+	event := SubscriptionPaymentMethodAttachedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionPaymentMethodAttached",
+			aggregateID:   s.id.String(),
+			occurredAt:    s.updatedAt,
+			correlationID: correlationID,
+		},
+		CustomerID:      s.customerID.String(),
+		PaymentMethodID: paymentMethodID,
+	}
+
+	s.addEvent(event)
+	return nil
+}
+
+// StartTrial coloca uma subscription pendente em trial pelo número de dias informado
+func (s *Subscription) StartTrial(days int, now time.Time, correlationID string) error {
+	if days <= 0 {
+		return ErrInvalidTrialDays
+	}
+
+	if s.status != SubscriptionStatusPending {
+		return ErrInvalidStatusTransition
+	}
+
+	s.status = SubscriptionStatusTrialing
+	s.trialEndsAt = now.AddDate(0, 0, days)
+	s.updatedAt = now
+
+	event := SubscriptionTrialStartedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionTrialStarted",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		PlanID:      s.planID.String(),
+		CustomerID:  s.customerID.String(),
+		TrialEndsAt: s.trialEndsAt,
+	}
+
+	s.addEvent(event)
+	return nil
+}
+
+// IsTrialEnded verifica se o trial terminou no instante informado
+func (s *Subscription) IsTrialEnded(now time.Time) bool {
+	return s.IsTrialing() && !now.Before(s.trialEndsAt)
+}
+
+// NotifyTrialWillEnd emite, uma única vez, o aviso de que o trial termina dentro da janela informada
+func (s *Subscription) NotifyTrialWillEnd(now time.Time, notice time.Duration, correlationID string) error {
+	if !s.IsTrialing() || s.trialEndNotified {
+		return ErrInvalidStatusTransition
+	}
+
+	if now.Before(s.trialEndsAt.Add(-notice)) {
+		return ErrPeriodNotEnded
+	}
+
+	s.trialEndNotified = true
+	s.updatedAt = now
+
+	event := SubscriptionTrialWillEndEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionTrialWillEnd",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		CustomerID:       s.customerID.String(),
+		TrialEndsAt:      s.trialEndsAt,
+		HasPaymentMethod: s.HasPaymentMethod(),
+	}
+
+	s.addEvent(event)
+	return nil
+}
+
+// EndTrial encerra o trial: converte em ativa quando há meio de pagamento, senão cancela
+func (s *Subscription) EndTrial(now time.Time, correlationID string) error {
+	if !s.IsTrialing() {
+		return ErrInvalidStatusTransition
+	}
+
+	if !s.IsTrialEnded(now) {
+		return ErrPeriodNotEnded
+	}
+
+	outcome := TrialOutcomeCancelled
+	if s.HasPaymentMethod() {
+		outcome = TrialOutcomeConverted
+	}
+
+	s.addEvent(SubscriptionTrialEndedEvent{
+		BaseEvent: BaseEvent{
+			eventID:       newEventID(),
+			eventType:     "SubscriptionTrialEnded",
+			aggregateID:   s.id.String(),
+			occurredAt:    now,
+			correlationID: correlationID,
+		},
+		CustomerID: s.customerID.String(),
+		Outcome:    outcome,
+	})
+
+	if outcome == TrialOutcomeCancelled {
+		return s.Cancel(TrialCancellationReason, correlationID)
+	}
+
+	// O primeiro período de cobrança começa exatamente no fim do trial
+	s.startPeriod(s.trialEndsAt)
+	return s.Activate(correlationID)
+}
@@ -0,0 +1,240 @@
+package subscription
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"payments-subscription/internal/common/problem"
+)
+
+// Limites aplicados aos dados do customer recebidos na criação da subscription
+const (
+	maxCustomerNameLength  = 100
+	maxCustomerEmailLength = 254
+)
+
+// maxReasonLength é o tamanho da coluna cancellation_reason
+const maxReasonLength = 255
+
+// Códigos dos campos inválidos devolvidos em problem.FieldError
+const (
+	FieldCodeRequired      = "required"
+	FieldCodeInvalidFormat = "invalid_format"
+	FieldCodeTooLong       = "too_long"
+	FieldCodeOutOfRange    = "out_of_range"
+	FieldCodeInvalidValue  = "invalid_value"
+)
+
+// ValidateCreateSubscriptionRequest valida a requisição antes de chamar o serviço,
+// retornando todos os campos inválidos de uma vez
+func ValidateCreateSubscriptionRequest(req CreateSubscriptionRequest) []problem.FieldError {
+	var errors []problem.FieldError
+
+	if strings.TrimSpace(req.PlanID) == "" {
+		errors = append(errors, problem.FieldError{Field: "plan_id", Code: FieldCodeRequired, Message: "plan_id is required"})
+	}
+
+	if req.TrialDays < 0 {
+		errors = append(errors, problem.FieldError{Field: "trial_days", Code: FieldCodeOutOfRange, Message: "trial_days must not be negative"})
+	}
+
+	// Com customer_id informado o customer já existe e nome e email passam a ser opcionais
+	customerRequired := strings.TrimSpace(req.Customer.CustomerID) == ""
+
+	name := strings.TrimSpace(req.Customer.Name)
+	switch {
+	case name == "" && customerRequired:
+		errors = append(errors, problem.FieldError{Field: "customer.name", Code: FieldCodeRequired, Message: "customer name is required"})
+	case utf8.RuneCountInString(name) > maxCustomerNameLength:
+		errors = append(errors, problem.FieldError{Field: "customer.name", Code: FieldCodeTooLong, Message: "customer name must have at most 100 characters"})
+	}
+
+	email := strings.TrimSpace(req.Customer.Email)
+	switch {
+	case email == "":
+		if customerRequired {
+			errors = append(errors, problem.FieldError{Field: "customer.email", Code: FieldCodeRequired, Message: "customer email is required"})
+		}
+	case len(email) > maxCustomerEmailLength:
+		errors = append(errors, problem.FieldError{Field: "customer.email", Code: FieldCodeTooLong, Message: "customer email must have at most 254 characters"})
+	case !isValidEmail(email):
+		errors = append(errors, problem.FieldError{Field: "customer.email", Code: FieldCodeInvalidFormat, Message: "customer email is not a valid address"})
+	}
+
+	return errors
+}
+
+// ValidateChangePlanRequest valida a requisição de troca de plano antes de chamar o serviço
+func ValidateChangePlanRequest(req ChangePlanRequest) []problem.FieldError {
+	var errors []problem.FieldError
+
+	if strings.TrimSpace(req.PlanID) == "" {
+		errors = append(errors, problem.FieldError{Field: "plan_id", Code: FieldCodeRequired, Message: "plan_id is required"})
+	}
+
+	switch PlanChangeTiming(req.Timing) {
+	case "", PlanChangeImmediate, PlanChangeEndOfPeriod:
+	default:
+		errors = append(errors, problem.FieldError{Field: "timing", Code: FieldCodeInvalidValue, Message: "timing must be immediate or end_of_period"})
+	}
+
+	return errors
+}
+
+// ValidateChangeStatusRequest valida o motivo das mudanças de status; reasonRequired indica se a
+// operação exige motivo
+func ValidateChangeStatusRequest(req ChangeStatusRequest, reasonRequired bool) []problem.FieldError {
+	var errors []problem.FieldError
+
+	switch {
+	case reasonRequired && req.Reason == "":
+		errors = append(errors, problem.FieldError{Field: "reason", Code: FieldCodeRequired, Message: "reason is required"})
+	case utf8.RuneCountInString(req.Reason) > maxReasonLength:
+		errors = append(errors, problem.FieldError{Field: "reason", Code: FieldCodeTooLong, Message: "reason must have at most 255 characters"})
+	}
+
+	return errors
+}
+
+// ValidateAttachPaymentMethodRequest valida a requisição de associação do meio de pagamento
+func ValidateAttachPaymentMethodRequest(req AttachPaymentMethodRequest) []problem.FieldError {
+	var errors []problem.FieldError
+
+	if strings.TrimSpace(req.PaymentMethodID) == "" {
+		errors = append(errors, problem.FieldError{Field: "payment_method_id", Code: FieldCodeRequired, Message: "payment_method_id is required"})
+	}
+
+	return errors
+}
+
+// ValidateInboundEventRequest valida um evento recebido de outro serviço antes de despachá-lo
+func ValidateInboundEventRequest(req InboundEventRequest) []problem.FieldError {
+	var errors []problem.FieldError
+
+	if strings.TrimSpace(req.Type) == "" {
+		errors = append(errors, problem.FieldError{Field: "type", Code: FieldCodeRequired, Message: "type is required"})
+	}
+
+	if strings.TrimSpace(req.SubscriptionID) == "" {
+		errors = append(errors, problem.FieldError{Field: "subscription_id", Code: FieldCodeRequired, Message: "subscription_id is required"})
+	}
+
+	if strings.TrimSpace(req.CustomerID) == "" {
+		errors = append(errors, problem.FieldError{Field: "customer_id", Code: FieldCodeRequired, Message: "customer_id is required"})
+	}
+
+	return errors
+}
+
+// ParseListSubscriptionsQuery converte os parâmetros de query string da listagem,
+// retornando todos os parâmetros inválidos de uma vez
+func ParseListSubscriptionsQuery(values url.Values) (ListSubscriptionsQuery, []problem.FieldError) {
+	var errors []problem.FieldError
+
+	query := ListSubscriptionsQuery{
+		Status:     SubscriptionStatus(values.Get("status")),
+		PlanID:     values.Get("plan_id"),
+		CustomerID: values.Get("customer_id"),
+	}
+
+	if query.Status != "" && !query.Status.IsValid() {
+		errors = append(errors, problem.FieldError{Field: "status", Code: FieldCodeInvalidValue, Message: "status is not a known subscription status"})
+	}
+
+	sort, err := ParseListSortOrder(values.Get("sort"))
+	if err != nil {
+		errors = append(errors, problem.FieldError{Field: "sort", Code: FieldCodeInvalidValue, Message: "sort must be created_at or -created_at"})
+	}
+	query.Sort = sort
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxPageSize {
+			errors = append(errors, problem.FieldError{Field: "limit", Code: FieldCodeOutOfRange, Message: fmt.Sprintf("limit must be between 1 and %d", MaxPageSize)})
+		}
+		query.Limit = limit
+	}
+
+	for _, field := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"created_from", &query.CreatedFrom},
+		{"created_to", &query.CreatedTo},
+	} {
+		raw := values.Get(field.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			errors = append(errors, problem.FieldError{Field: field.name, Code: FieldCodeInvalidFormat, Message: field.name + " must be an RFC 3339 timestamp"})
+			continue
+		}
+		*field.target = &parsed
+	}
+
+	if query.CreatedFrom != nil && query.CreatedTo != nil && !query.CreatedFrom.Before(*query.CreatedTo) {
+		errors = append(errors, problem.FieldError{Field: "created_to", Code: FieldCodeOutOfRange, Message: "created_to must be after created_from"})
+	}
+
+	if raw := values.Get("cursor"); raw != "" {
+		cursor, err := DecodeListCursor(raw)
+		switch {
+		case err != nil:
+			errors = append(errors, problem.FieldError{Field: "cursor", Code: FieldCodeInvalidFormat, Message: "cursor is not valid"})
+		case cursor.Sort != query.Sort:
+			errors = append(errors, problem.FieldError{Field: "cursor", Code: FieldCodeInvalidValue, Message: "cursor was issued for a different sort order"})
+		default:
+			query.After = cursor
+		}
+	}
+
+	return query, errors
+}
+
+// ParseDeadLetterFilter converte os parâmetros de query string da listagem da dead-letter queue,
+// retornando todos os parâmetros inválidos de uma vez
+func ParseDeadLetterFilter(values url.Values) (DeadLetterFilter, []problem.FieldError) {
+	var errors []problem.FieldError
+
+	filter := DeadLetterFilter{
+		EventType:     values.Get("event_type"),
+		AggregateID:   values.Get("aggregate_id"),
+		CorrelationID: values.Get("correlation_id"),
+		Source:        DeadLetterSource(values.Get("source")),
+		Status:        DeadLetterStatus(values.Get("status")),
+	}
+
+	if filter.Source != "" && !filter.Source.IsValid() {
+		errors = append(errors, problem.FieldError{Field: "source", Code: FieldCodeInvalidValue, Message: "source must be publish or handler"})
+	}
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		errors = append(errors, problem.FieldError{Field: "status", Code: FieldCodeInvalidValue, Message: "status must be pending, replaying, replayed or discarded"})
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxDeadLetterPageSize {
+			errors = append(errors, problem.FieldError{Field: "limit", Code: FieldCodeOutOfRange, Message: fmt.Sprintf("limit must be between 1 and %d", MaxDeadLetterPageSize)})
+		}
+		filter.Limit = limit
+	}
+
+	return filter, errors
+}
+
+// isValidEmail aceita apenas o endereço puro, sem nome de exibição
+func isValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return false
+	}
+	return address.Address == email
+}
@@ -0,0 +1,21 @@
+-- Criação da tabela outbox (eventos de domínio gravados na mesma transação do agregado)
+CREATE TABLE IF NOT EXISTS outbox (
+    id VARCHAR(36) PRIMARY KEY,
+    aggregate_id VARCHAR(36) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    correlation_id VARCHAR(100) NOT NULL DEFAULT '',
+    traceparent VARCHAR(55) NOT NULL DEFAULT '',
+    payload JSON NOT NULL,
+    occurred_at TIMESTAMP(6) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    locked_by VARCHAR(36) NULL,
+    locked_until TIMESTAMP(6) NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    published_at TIMESTAMP(6) NULL,
+
+    INDEX idx_outbox_pending (published_at, attempts, created_at),
+    INDEX idx_outbox_aggregate_id (aggregate_id),
+    INDEX idx_outbox_aggregate_pending (aggregate_id, published_at, created_at),
+    INDEX idx_outbox_locked_by (locked_by)
+);
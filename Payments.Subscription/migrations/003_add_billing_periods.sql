@@ -0,0 +1,7 @@
+-- Períodos de cobrança e data de renovação das subscriptions
+ALTER TABLE subscriptions
+    ADD COLUMN billing_interval ENUM('monthly', 'yearly', 'custom') NOT NULL DEFAULT 'monthly' AFTER status,
+    ADD COLUMN billing_interval_days INT NULL AFTER billing_interval,
+    ADD COLUMN current_period_start TIMESTAMP NULL AFTER billing_interval_days,
+    ADD COLUMN current_period_end TIMESTAMP NULL AFTER current_period_start,
+    ADD INDEX idx_subscriptions_status_period_end (status, current_period_end);
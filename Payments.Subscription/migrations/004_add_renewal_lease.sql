@@ -0,0 +1,5 @@
+-- Lease usado pelo worker de renovação para evitar processamento duplicado entre réplicas
+ALTER TABLE subscriptions
+    ADD COLUMN lease_owner VARCHAR(36) NULL,
+    ADD COLUMN lease_expires_at TIMESTAMP(6) NULL,
+    ADD INDEX idx_subscriptions_lease_owner (lease_owner);
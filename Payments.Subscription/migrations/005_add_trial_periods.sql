@@ -0,0 +1,7 @@
+-- Trial com conversão automática ou expiração
+ALTER TABLE subscriptions
+    MODIFY COLUMN status ENUM('pending', 'trialing', 'active', 'inactive', 'cancelled', 'suspended') NOT NULL DEFAULT 'pending',
+    ADD COLUMN trial_ends_at TIMESTAMP NULL AFTER current_period_end,
+    ADD COLUMN trial_end_notified BOOLEAN NOT NULL DEFAULT FALSE AFTER trial_ends_at,
+    ADD COLUMN payment_method_id VARCHAR(100) NULL AFTER trial_end_notified,
+    ADD INDEX idx_subscriptions_status_trial_ends_at (status, trial_ends_at);
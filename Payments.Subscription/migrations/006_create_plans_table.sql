@@ -0,0 +1,22 @@
+-- Criação da tabela plans (catálogo de planos)
+CREATE TABLE IF NOT EXISTS plans (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    description TEXT NOT NULL,
+    price_amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    billing_interval ENUM('monthly', 'yearly', 'custom') NOT NULL DEFAULT 'monthly',
+    billing_interval_days INT NULL,
+    trial_days INT NOT NULL DEFAULT 0,
+    features JSON NOT NULL,
+    status ENUM('active', 'archived') NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_plans_status (status),
+    INDEX idx_plans_created_at (created_at)
+);
+
+-- Plano usado pelo test-request.json da raiz do repositório
+INSERT IGNORE INTO plans (id, name, description, price_amount, currency, billing_interval, trial_days, features, status)
+VALUES ('premium-plan', 'Premium', 'Plano premium mensal', 4990, 'BRL', 'monthly', 0, JSON_ARRAY('premium-support'), 'active');
@@ -0,0 +1,5 @@
+-- Troca de plano agendada para o fim do período corrente
+ALTER TABLE subscriptions
+    ADD COLUMN pending_plan_id VARCHAR(36) NULL AFTER payment_method_id,
+    ADD COLUMN pending_billing_interval ENUM('monthly', 'yearly', 'custom') NULL AFTER pending_plan_id,
+    ADD COLUMN pending_billing_interval_days INT NULL AFTER pending_billing_interval;
@@ -0,0 +1,4 @@
+-- Cancelamento agendado para o fim do período corrente
+ALTER TABLE subscriptions
+    ADD COLUMN cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE AFTER pending_billing_interval_days,
+    ADD COLUMN cancellation_reason VARCHAR(255) NULL AFTER cancel_at_period_end;
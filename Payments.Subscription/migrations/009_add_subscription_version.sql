@@ -0,0 +1,3 @@
+-- Versão usada no controle de concorrência otimista das atualizações
+ALTER TABLE subscriptions
+    ADD COLUMN version INT NOT NULL DEFAULT 1 AFTER cancellation_reason;
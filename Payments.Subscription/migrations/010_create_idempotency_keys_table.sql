@@ -0,0 +1,14 @@
+-- Chaves de idempotência do POST /subscriptions com a resposta original para replay
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status ENUM('processing', 'completed') NOT NULL DEFAULT 'processing',
+    status_code INT NULL,
+    content_type VARCHAR(100) NULL,
+    response_body MEDIUMBLOB NULL,
+    locked_until TIMESTAMP(6) NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    expires_at TIMESTAMP(6) NOT NULL,
+
+    INDEX idx_idempotency_keys_expires_at (expires_at)
+);
@@ -0,0 +1,6 @@
+-- Índices para a listagem paginada por (created_at, id) com filtros
+ALTER TABLE subscriptions
+    ADD INDEX idx_subscriptions_created_at_id (created_at, id),
+    ADD INDEX idx_subscriptions_status_created_at_id (status, created_at, id),
+    ADD INDEX idx_subscriptions_plan_created_at_id (plan_id, created_at, id),
+    ADD INDEX idx_subscriptions_customer_created_at_id (customer_id, created_at, id);
@@ -0,0 +1,20 @@
+-- Estado das sagas de criação de subscription, usado para compensar ou concluir sagas interrompidas
+CREATE TABLE IF NOT EXISTS subscription_sagas (
+    id VARCHAR(36) PRIMARY KEY,
+    saga_type VARCHAR(50) NOT NULL,
+    status ENUM('running', 'completed', 'compensating', 'compensated') NOT NULL DEFAULT 'running',
+    subscription_id VARCHAR(36) NULL,
+    customer_id VARCHAR(36) NULL,
+    customer_created BOOLEAN NOT NULL DEFAULT FALSE,
+    steps JSON NOT NULL,
+    last_error TEXT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    correlation_id VARCHAR(100) NOT NULL DEFAULT '',
+    locked_by VARCHAR(36) NULL,
+    locked_until TIMESTAMP(6) NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+
+    INDEX idx_subscription_sagas_status_updated_at (status, updated_at),
+    INDEX idx_subscription_sagas_locked_by (locked_by)
+);
@@ -0,0 +1,4 @@
+-- Onboarding assíncrono: a subscription é criada antes de o customer existir
+ALTER TABLE subscriptions
+    MODIFY COLUMN customer_id VARCHAR(36) NULL,
+    ADD COLUMN requested_trial_days INT NOT NULL DEFAULT 0 AFTER payment_method_id;
@@ -0,0 +1,25 @@
+-- Dead-letter queue: eventos que esgotaram as tentativas de publicação (outbox) ou dos handlers (dispatcher)
+CREATE TABLE IF NOT EXISTS dead_letter_events (
+    id VARCHAR(36) PRIMARY KEY,
+    source ENUM('publish', 'handler') NOT NULL,
+    status ENUM('pending', 'replaying', 'replayed', 'discarded') NOT NULL DEFAULT 'pending',
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_id VARCHAR(36) NOT NULL,
+    correlation_id VARCHAR(100) NOT NULL DEFAULT '',
+    handler VARCHAR(100) NOT NULL DEFAULT '',
+    payload JSON NOT NULL,
+    headers JSON NOT NULL,
+    error TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    replay_attempts INT NOT NULL DEFAULT 0,
+    -- Início do reprocessamento em andamento; reservas antigas são de reprocessamentos interrompidos
+    replay_claimed_at TIMESTAMP(6) NULL,
+    occurred_at TIMESTAMP(6) NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    resolved_at TIMESTAMP(6) NULL,
+
+    INDEX idx_dead_letter_events_status_created_at (status, created_at),
+    INDEX idx_dead_letter_events_event_type (event_type),
+    INDEX idx_dead_letter_events_aggregate_id (aggregate_id),
+    INDEX idx_dead_letter_events_correlation_id (correlation_id)
+);
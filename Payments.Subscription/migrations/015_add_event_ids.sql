@@ -0,0 +1,6 @@
+-- ID do evento de domínio, publicado como id do envelope CloudEvents e preservado no reprocessamento
+ALTER TABLE outbox
+    ADD COLUMN event_id VARCHAR(36) NOT NULL DEFAULT '' AFTER id;
+
+ALTER TABLE dead_letter_events
+    ADD COLUMN event_id VARCHAR(36) NOT NULL DEFAULT '' AFTER id;